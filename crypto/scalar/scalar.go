@@ -0,0 +1,409 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package scalar implements the constant-time arithmetic of the integers modulo an odd modulus, like the order N of a
+// group for the secret scalars, or the prime P of the field of a curve.
+//
+// A Scalar has the fixed width of its modulus, whatever its value, and is kept in the Montgomery form. The arithmetic
+// runs in a time which depends on the modulus only, never on the values: there is no branch and no memory access on
+// secret data. Only the conversions from and to *big.Int, at the boundary of the APIs which still use them, are as
+// variable-time as math/big.
+package scalar
+
+import (
+	"errors"
+	"io"
+	"math/big"
+	"math/bits"
+)
+
+var ErrInvalidModulus = errors.New("scalar: the modulus must be odd and greater than 1")
+
+// Modulus is an odd modulus n > 1, with the constants of the Montgomery arithmetic modulo n.
+type Modulus struct {
+	n     []uint64 // little-endian limbs
+	nBig  *big.Int
+	size  int    // the length of an encoded scalar in bytes
+	n0inv uint64 // -n^-1 mod 2^64
+	one   []uint64
+	rr    []uint64 // R² mod n, R = 2^(64·len(n))
+}
+
+// NewModulus returns the modulus n, which must be odd and greater than 1.
+func NewModulus(n *big.Int) (*Modulus, error) {
+	if n.Sign() <= 0 || n.Bit(0) == 0 || n.BitLen() < 2 {
+		return nil, ErrInvalidModulus
+	}
+	limbs := (n.BitLen() + 63) / 64
+	m := &Modulus{
+		n:    toLimbs(n, limbs),
+		nBig: new(big.Int).Set(n),
+		size: (n.BitLen() + 7) / 8,
+	}
+	// Newton's iteration doubles the correct low bits of the inverse, an odd n0 is its own inverse modulo 8.
+	inv := m.n[0]
+	for i := 0; i < 5; i++ {
+		inv *= 2 - m.n[0]*inv
+	}
+	m.n0inv = -inv
+	r := new(big.Int).Lsh(big.NewInt(1), uint(64*limbs))
+	m.one = toLimbs(new(big.Int).Mod(r, n), limbs)
+	m.rr = toLimbs(r.Mul(r, r).Mod(r, n), limbs)
+	return m, nil
+}
+
+// Big returns the modulus n.
+func (m *Modulus) Big() *big.Int {
+	return new(big.Int).Set(m.nBig)
+}
+
+// Size returns the length of an encoded scalar in bytes, the length of n.
+func (m *Modulus) Size() int {
+	return m.size
+}
+
+// Scalar is an integer modulo n. The zero Scalar isn't usable, a Scalar is created by its Modulus, and all the operands
+// of an operation must have the same Modulus.
+type Scalar struct {
+	m     *Modulus
+	limbs []uint64 // x·R mod n
+}
+
+// NewScalar returns the scalar 0.
+func (m *Modulus) NewScalar() *Scalar {
+	return &Scalar{m: m, limbs: make([]uint64, len(m.n))}
+}
+
+// One returns the scalar 1.
+func (m *Modulus) One() *Scalar {
+	return &Scalar{m: m, limbs: append([]uint64{}, m.one...)}
+}
+
+// FromBig returns x mod n, for a non-negative x in constant time in the length of x, or a negative x in variable time.
+func (m *Modulus) FromBig(x *big.Int) *Scalar {
+	if x.Sign() < 0 {
+		x = new(big.Int).Mod(x, m.nBig)
+	}
+	return m.FromBytes(x.Bytes())
+}
+
+// FromBytes returns the big-endian number b mod n, in constant time in the length of b.
+func (m *Modulus) FromBytes(b []byte) *Scalar {
+	k := len(m.n)
+	// b is read in blocks of k limbs from the most significant one, s = s·R + block, where R = 2^(64·k),
+	// and a block less than R times R² mod n is the Montgomery form of the block mod n
+	padded := make([]byte, (len(b)+8*k-1)/(8*k)*8*k)
+	copy(padded[len(padded)-len(b):], b)
+	s := m.NewScalar()
+	block := make([]uint64, k)
+	tmp := make([]uint64, k)
+	for len(padded) > 0 {
+		for i := range block {
+			block[i] = 0
+			for j := 0; j < 8; j++ {
+				block[i] |= uint64(padded[8*k-1-8*i-j]) << uint(8*j)
+			}
+		}
+		s.limbs = m.montMul(s.limbs, s.limbs, m.rr)
+		tmp = m.montMul(tmp, block, m.rr)
+		s.Add(s, &Scalar{m: m, limbs: tmp})
+		padded = padded[8*k:]
+	}
+	for i := range block {
+		block[i] = 0
+	}
+	return s
+}
+
+// Random returns a uniformly random scalar in [0, n), from 16 bytes more than n of the random source, so that the bias
+// of the reduction is negligible.
+func (m *Modulus) Random(random io.Reader) (*Scalar, error) {
+	b := make([]byte, m.size+16)
+	if _, err := io.ReadFull(random, b); err != nil {
+		return nil, err
+	}
+	s := m.FromBytes(b)
+	for i := range b {
+		b[i] = 0
+	}
+	return s, nil
+}
+
+// RandomNonZero returns a uniformly random scalar in [1, n) like Random.
+func (m *Modulus) RandomNonZero(random io.Reader) (*Scalar, error) {
+	for {
+		s, err := m.Random(random)
+		if err != nil {
+			return nil, err
+		}
+		// only a zero, which is negligible, is discarded
+		if !s.IsZero() {
+			return s, nil
+		}
+	}
+}
+
+// Modulus returns the modulus of s.
+func (s *Scalar) Modulus() *Modulus {
+	return s.m
+}
+
+// Set sets s = x and returns s.
+func (s *Scalar) Set(x *Scalar) *Scalar {
+	s.m = x.m
+	s.limbs = append(s.limbs[:0], x.limbs...)
+	return s
+}
+
+// Add sets s = x + y mod n and returns s.
+func (s *Scalar) Add(x, y *Scalar) *Scalar {
+	m := s.check(x, y)
+	var buf [stackLimbs]uint64
+	z := limbsOf(buf[:], len(m.n))
+	var carry uint64
+	for i := range z {
+		z[i], carry = bits.Add64(x.limbs[i], y.limbs[i], carry)
+	}
+	s.limbs = m.reduceOnce(s.limbs, z, carry)
+	return s
+}
+
+// Sub sets s = x - y mod n and returns s.
+func (s *Scalar) Sub(x, y *Scalar) *Scalar {
+	m := s.check(x, y)
+	var buf [stackLimbs]uint64
+	z := limbsOf(buf[:], len(m.n))
+	var borrow, carry uint64
+	for i := range z {
+		z[i], borrow = bits.Sub64(x.limbs[i], y.limbs[i], borrow)
+	}
+	// adds n back if x < y
+	mask := -borrow
+	for i := range z {
+		z[i], carry = bits.Add64(z[i], m.n[i]&mask, carry)
+	}
+	s.limbs = append(s.limbs[:0], z...)
+	return s
+}
+
+// Neg sets s = -x mod n and returns s.
+func (s *Scalar) Neg(x *Scalar) *Scalar {
+	return s.Sub(x.m.NewScalar(), x)
+}
+
+// Mul sets s = x·y mod n and returns s.
+func (s *Scalar) Mul(x, y *Scalar) *Scalar {
+	m := s.check(x, y)
+	s.limbs = m.montMul(s.limbs, x.limbs, y.limbs)
+	return s
+}
+
+// Exp sets s = x^e mod n and returns s. The time depends on the length of e, but not on x, so e must be public.
+func (s *Scalar) Exp(x *Scalar, e []byte) *Scalar {
+	m := x.m
+	base := append([]uint64{}, x.limbs...)
+	acc := append([]uint64{}, m.one...)
+	prod := make([]uint64, len(m.n))
+	for _, c := range e {
+		for i := 7; i >= 0; i-- {
+			acc = m.montMul(acc, acc, acc)
+			prod = m.montMul(prod, acc, base)
+			ctSelect(acc, prod, acc, int(c>>uint(i)&1))
+		}
+	}
+	s.m, s.limbs = m, append(s.limbs[:0], acc...)
+	return s
+}
+
+// Inverse sets s = 1/x mod n by Fermat's little theorem, x^(n-2), and returns s. The modulus must be prime,
+// the inverse of 0 is 0.
+func (s *Scalar) Inverse(x *Scalar) *Scalar {
+	e := new(big.Int).Sub(x.m.nBig, big.NewInt(2))
+	return s.Exp(x, e.Bytes())
+}
+
+// Select sets s = x if cond is 1, or s = y if cond is 0, in constant time, and returns s.
+func (s *Scalar) Select(x, y *Scalar, cond int) *Scalar {
+	m := s.check(x, y)
+	var buf [stackLimbs]uint64
+	z := limbsOf(buf[:], len(m.n))
+	ctSelect(z, x.limbs, y.limbs, cond)
+	s.limbs = append(s.limbs[:0], z...)
+	return s
+}
+
+// Swap exchanges s and x if cond is 1, and leaves them if cond is 0, in constant time.
+func (s *Scalar) Swap(x *Scalar, cond int) {
+	s.check(x, x)
+	mask := -uint64(cond)
+	for i := range s.limbs {
+		t := (s.limbs[i] ^ x.limbs[i]) & mask
+		s.limbs[i] ^= t
+		x.limbs[i] ^= t
+	}
+}
+
+// Equal returns 1 if s == x, and 0 otherwise, in constant time.
+func (s *Scalar) Equal(x *Scalar) int {
+	s.check(x, x)
+	var diff uint64
+	for i := range s.limbs {
+		diff |= s.limbs[i] ^ x.limbs[i]
+	}
+	return int(1 ^ (diff|-diff)>>63)
+}
+
+// IsZero reports whether s == 0. Only the result leaks, not the value of s.
+func (s *Scalar) IsZero() bool {
+	return s.Equal(s.m.NewScalar()) == 1
+}
+
+// IsOdd returns 1 if s, as an integer in [0, n), is odd, and 0 otherwise, in constant time.
+func (s *Scalar) IsOdd() int {
+	return int(s.m.fromMont(s.limbs)[0] & 1)
+}
+
+// Bytes returns s as a big-endian number of Size bytes.
+func (s *Scalar) Bytes() []byte {
+	return s.FillBytes(make([]byte, s.m.size))
+}
+
+// FillBytes sets buf to s as a big-endian number, zero-extended, and returns buf. buf must have at least Size bytes.
+func (s *Scalar) FillBytes(buf []byte) []byte {
+	if len(buf) < s.m.size {
+		panic("scalar: buffer too small")
+	}
+	limbs := s.m.fromMont(s.limbs)
+	for i := range buf {
+		j := len(buf) - 1 - i
+		if i/8 < len(limbs) {
+			buf[j] = byte(limbs[i/8] >> uint(8*(i%8)))
+		} else {
+			buf[j] = 0
+		}
+	}
+	return buf
+}
+
+// Big returns s as a *big.Int in [0, n).
+func (s *Scalar) Big() *big.Int {
+	return new(big.Int).SetBytes(s.Bytes())
+}
+
+// Clear sets s = 0, to erase a secret scalar.
+func (s *Scalar) Clear() {
+	for i := range s.limbs {
+		s.limbs[i] = 0
+	}
+}
+
+// check panics if the operands don't have the modulus of s, which is set to the one of x if s is not initialized.
+func (s *Scalar) check(x, y *Scalar) *Modulus {
+	if s.m == nil {
+		s.m = x.m
+	}
+	if x.m != s.m || y.m != s.m {
+		if x.m.nBig.Cmp(s.m.nBig) != 0 || y.m.nBig.Cmp(s.m.nBig) != 0 {
+			panic("scalar: operands with different moduli")
+		}
+	}
+	return s.m
+}
+
+// montMul returns x·y·R^-1 mod n in z, by the coarsely integrated operand scanning method. x must be less than R
+// and y less than n.
+func (m *Modulus) montMul(z, x, y []uint64) []uint64 {
+	k := len(m.n)
+	var buf [stackLimbs + 2]uint64
+	t := limbsOf(buf[:], k+2)
+	for i := 0; i < k; i++ {
+		// t += x·y_i
+		var c uint64
+		for j := 0; j < k; j++ {
+			c, t[j] = mulAddAdd(x[j], y[i], t[j], c)
+		}
+		var cc uint64
+		t[k], cc = bits.Add64(t[k], c, 0)
+		t[k+1] = cc
+
+		// t = (t + u·n) / 2^64, where u makes the low limb zero
+		u := t[0] * m.n0inv
+		c, _ = mulAddAdd(u, m.n[0], t[0], 0)
+		for j := 1; j < k; j++ {
+			c, t[j-1] = mulAddAdd(u, m.n[j], t[j], c)
+		}
+		t[k-1], cc = bits.Add64(t[k], c, 0)
+		t[k] = t[k+1] + cc
+	}
+	// t < 2n
+	return m.reduceOnce(z, t[:k], t[k])
+}
+
+// reduceOnce returns carry·2^(64·k) + x - n in z if it's not negative, and x otherwise, for a value less than 2n.
+func (m *Modulus) reduceOnce(z, x []uint64, carry uint64) []uint64 {
+	var buf [stackLimbs]uint64
+	d := limbsOf(buf[:], len(m.n))
+	var borrow uint64
+	for i := range d {
+		d[i], borrow = bits.Sub64(x[i], m.n[i], borrow)
+	}
+	// x - n is the result unless it borrowed from a zero carry
+	keep := int(^carry & borrow & 1)
+	z = append(z[:0], x...)
+	ctSelect(z, x, d, keep)
+	return z
+}
+
+// fromMont returns the limbs x·R^-1 mod n of the integer.
+func (m *Modulus) fromMont(x []uint64) []uint64 {
+	one := make([]uint64, len(m.n))
+	one[0] = 1
+	return m.montMul(nil, x, one)
+}
+
+// stackLimbs is the number of limbs of the temporaries which are not allocated, enough for a 512-bit modulus.
+const stackLimbs = 8
+
+// limbsOf returns the first n limbs of the zeroed buf, or new limbs if buf is too short.
+func limbsOf(buf []uint64, n int) []uint64 {
+	if n <= len(buf) {
+		return buf[:n]
+	}
+	return make([]uint64, n)
+}
+
+// mulAddAdd returns the high and low limbs of x·y + a + b, which fits in two limbs.
+func mulAddAdd(x, y, a, b uint64) (hi, lo uint64) {
+	hi, lo = bits.Mul64(x, y)
+	var c uint64
+	lo, c = bits.Add64(lo, a, 0)
+	hi += c
+	lo, c = bits.Add64(lo, b, 0)
+	hi += c
+	return hi, lo
+}
+
+// ctSelect sets z = x if cond is 1, or z = y if cond is 0, in constant time. z may alias x or y.
+func ctSelect(z, x, y []uint64, cond int) {
+	mask := -uint64(cond)
+	for i := range z {
+		z[i] = y[i] ^ (x[i]^y[i])&mask
+	}
+}
+
+// toLimbs returns the non-negative x as little-endian 64-bit limbs.
+func toLimbs(x *big.Int, limbs int) []uint64 {
+	b := x.FillBytes(make([]byte, 8*limbs))
+	z := make([]uint64, limbs)
+	for i := range z {
+		for j := 0; j < 8; j++ {
+			z[i] |= uint64(b[len(b)-1-8*i-j]) << uint(8*j)
+		}
+	}
+	return z
+}
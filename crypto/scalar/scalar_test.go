@@ -0,0 +1,110 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package scalar
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+func testModuli() []*big.Int {
+	ristretto, _ := new(big.Int).SetString("7237005577332262213973186563042994240857116359379907606001950938285454250989", 10)
+	secp256k1P, _ := new(big.Int).SetString("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f", 16)
+	return []*big.Int{
+		big.NewInt(3),
+		big.NewInt(1000003),
+		new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 127), big.NewInt(1)),
+		ristretto,
+		secp256k1P,
+		elliptic.P256().Params().N,
+		elliptic.P384().Params().N,
+	}
+}
+
+func TestScalarArithmetic(t *testing.T) {
+	for _, n := range testModuli() {
+		m, err := NewModulus(n)
+		require.NoError(t, err)
+		for i := 0; i < 50; i++ {
+			a, _ := rand.Int(rand.Reader, n)
+			b, _ := rand.Int(rand.Reader, n)
+			if i == 0 {
+				a.Sub(n, big.NewInt(1))
+				b.SetInt64(0)
+			}
+			x, y := m.FromBig(a), m.FromBig(b)
+			requireBigEqual(t, a, x.Big(), "round trip mod %v", n)
+
+			sum := new(big.Int).Add(a, b)
+			requireBigEqual(t, sum.Mod(sum, n), m.NewScalar().Add(x, y).Big(), "add mod %v", n)
+			diff := new(big.Int).Sub(a, b)
+			requireBigEqual(t, diff.Mod(diff, n), m.NewScalar().Sub(x, y).Big(), "sub mod %v", n)
+			neg := new(big.Int).Neg(a)
+			requireBigEqual(t, neg.Mod(neg, n), m.NewScalar().Neg(x).Big(), "neg mod %v", n)
+			prod := new(big.Int).Mul(a, b)
+			requireBigEqual(t, prod.Mod(prod, n), m.NewScalar().Mul(x, y).Big(), "mul mod %v", n)
+			if n.ProbablyPrime(20) && a.Sign() != 0 {
+				requireBigEqual(t, new(big.Int).ModInverse(a, n), m.NewScalar().Inverse(x).Big(), "inverse mod %v", n)
+			}
+			require.Equal(t, 1, x.Equal(m.FromBig(a)))
+			require.Equal(t, a.Cmp(b) == 0, x.Equal(y) == 1)
+			require.Equal(t, int(a.Bit(0)), x.IsOdd())
+
+			// the reduction of a wide number
+			wide := make([]byte, 3*m.Size()+5)
+			_, _ = rand.Read(wide)
+			w := new(big.Int).SetBytes(wide)
+			requireBigEqual(t, w.Mod(w, n), m.FromBytes(wide).Big(), "reduction mod %v", n)
+		}
+	}
+}
+
+func requireBigEqual(t *testing.T, expected, actual *big.Int, msgAndArgs ...interface{}) {
+	require.Zero(t, expected.Cmp(actual), msgAndArgs...)
+}
+
+func TestScalarSelectAndSwap(t *testing.T) {
+	m, err := NewModulus(elliptic.P256().Params().N)
+	require.NoError(t, err)
+	x, y := m.FromBig(big.NewInt(5)), m.FromBig(big.NewInt(7))
+	require.Equal(t, int64(5), m.NewScalar().Select(x, y, 1).Big().Int64())
+	require.Equal(t, int64(7), m.NewScalar().Select(x, y, 0).Big().Int64())
+	x.Swap(y, 0)
+	require.Equal(t, int64(5), x.Big().Int64())
+	x.Swap(y, 1)
+	require.Equal(t, int64(7), x.Big().Int64())
+	require.Equal(t, int64(5), y.Big().Int64())
+	require.True(t, m.NewScalar().IsZero())
+	require.Equal(t, int64(1), m.One().Big().Int64())
+	require.Len(t, x.Bytes(), 32)
+}
+
+func TestScalarRandom(t *testing.T) {
+	m, err := NewModulus(big.NewInt(11))
+	require.NoError(t, err)
+	seen := make(map[int64]bool)
+	for i := 0; i < 500; i++ {
+		s, err := m.RandomNonZero(rand.Reader)
+		require.NoError(t, err)
+		v := s.Big().Int64()
+		require.True(t, v >= 1 && v < 11)
+		seen[v] = true
+	}
+	require.Len(t, seen, 10)
+}
+
+func TestNewModulusRejects(t *testing.T) {
+	for _, n := range []int64{-7, 0, 1, 2, 10} {
+		_, err := NewModulus(big.NewInt(n))
+		require.ErrorIs(t, err, ErrInvalidModulus, "modulus %d", n)
+	}
+}
@@ -16,5 +16,8 @@ package secp256k1
 import (
 	_ "github.com/stars-labs/go-pvss/crypto/secp256k1/libsecp256k1/include"
 	_ "github.com/stars-labs/go-pvss/crypto/secp256k1/libsecp256k1/src"
+	_ "github.com/stars-labs/go-pvss/crypto/secp256k1/libsecp256k1/src/modules/ecdh"
+	_ "github.com/stars-labs/go-pvss/crypto/secp256k1/libsecp256k1/src/modules/extrakeys"
 	_ "github.com/stars-labs/go-pvss/crypto/secp256k1/libsecp256k1/src/modules/recovery"
+	_ "github.com/stars-labs/go-pvss/crypto/secp256k1/libsecp256k1/src/modules/schnorrsig"
 )
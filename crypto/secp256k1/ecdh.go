@@ -0,0 +1,60 @@
+// Copyright 2021 Stars-labs. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found in
+// the LICENSE file.
+
+package secp256k1
+
+import (
+	"crypto/sha256"
+	"math/big"
+)
+
+// ECDHHashFunc derives the shared secret of ECDH from the 32-byte big-endian coordinates x, y of the shared point,
+// like the secp256k1_ecdh_hash_function of libsecp256k1.
+type ECDHHashFunc func(x, y []byte) []byte
+
+// ECDHHashSHA256 is the hash of ECDH, the SHA256 of the shared point in the 33-byte compressed format, as in the
+// ecdh module of libsecp256k1.
+func ECDHHashSHA256(x, y []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{0x02 | y[31]&1})
+	h.Write(x)
+	return h.Sum(nil)
+}
+
+// ECDHHashX returns a copy of the x of the shared point, for the protocols which derive their keys by their own KDF.
+func ECDHHashX(x, y []byte) []byte {
+	return append([]byte{}, x...)
+}
+
+// ecdhPoint is the pure Go shared point of ECDH, as two 32-byte big-endian coordinates.
+func ecdhPoint(priv, pubkey []byte) (x, y []byte, err error) {
+	if len(priv) != 32 {
+		return nil, nil, ErrInvalidKey
+	}
+	d := new(big.Int).SetBytes(priv)
+	if d.Sign() == 0 || d.Cmp(theCurve.N) >= 0 {
+		return nil, nil, ErrInvalidKey
+	}
+	px, py, ok := parsePubkey(pubkey)
+	if !ok {
+		return nil, nil, ErrInvalidPubkey
+	}
+	sx, sy := theCurve.ScalarMult(px, py, priv)
+	x, y = make([]byte, 32), make([]byte, 32)
+	readBits(sx, x)
+	readBits(sy, y)
+	return x, y, nil
+}
+
+// ecdhGo is the pure Go ECDHWithHash.
+func ecdhGo(priv, pubkey []byte, hash ECDHHashFunc) ([]byte, error) {
+	if hash == nil {
+		hash = ECDHHashSHA256
+	}
+	x, y, err := ecdhPoint(priv, pubkey)
+	if err != nil {
+		return nil, err
+	}
+	return hash(x, y), nil
+}
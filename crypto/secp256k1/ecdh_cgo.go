@@ -0,0 +1,76 @@
+// Copyright 2021 Stars-labs. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found in
+// the LICENSE file.
+
+// +build !gofuzz
+// +build cgo
+
+package secp256k1
+
+import (
+	"unsafe"
+)
+
+/*
+
+#include "libsecp256k1/include/secp256k1.h"
+
+extern int secp256k1_ext_ecdh(const secp256k1_context* ctx, unsigned char *result32, const unsigned char *pubkeydata, size_t pubkeylen, const unsigned char *scalar);
+extern int secp256k1_ext_ecdh_point(const secp256k1_context* ctx, unsigned char *point, const unsigned char *pubkeydata, size_t pubkeylen, const unsigned char *scalar);
+
+*/
+import "C"
+
+// ECDH computes the 32-byte shared secret of the private key and the public key of the peer, which is the SHA256 of
+// the shared point priv·pubkey in the compressed format (ECDHHashSHA256), as the ecdh module of libsecp256k1.
+// The public key is in the 33-byte compressed or the 65-byte uncompressed format.
+func ECDH(priv, pubkey []byte) ([]byte, error) {
+	if err := checkECDHKeys(priv, pubkey); err != nil {
+		return nil, err
+	}
+	var (
+		out      = make([]byte, 32)
+		outdata  = (*C.uchar)(unsafe.Pointer(&out[0]))
+		keydata  = (*C.uchar)(unsafe.Pointer(&pubkey[0]))
+		privdata = (*C.uchar)(unsafe.Pointer(&priv[0]))
+	)
+	if C.secp256k1_ext_ecdh(context, outdata, keydata, C.size_t(len(pubkey)), privdata) == 0 {
+		return nil, ErrInvalidPubkey
+	}
+	return out, nil
+}
+
+// ECDHWithHash computes the shared secret of the private key and the public key of the peer like ECDH, but derives it
+// from the shared point by the hash function, nil is ECDHHashSHA256.
+func ECDHWithHash(priv, pubkey []byte, hash ECDHHashFunc) ([]byte, error) {
+	if hash == nil {
+		return ECDH(priv, pubkey)
+	}
+	if err := checkECDHKeys(priv, pubkey); err != nil {
+		return nil, err
+	}
+	var (
+		point    = make([]byte, 64)
+		outdata  = (*C.uchar)(unsafe.Pointer(&point[0]))
+		keydata  = (*C.uchar)(unsafe.Pointer(&pubkey[0]))
+		privdata = (*C.uchar)(unsafe.Pointer(&priv[0]))
+	)
+	if C.secp256k1_ext_ecdh_point(context, outdata, keydata, C.size_t(len(pubkey)), privdata) == 0 {
+		return nil, ErrInvalidPubkey
+	}
+	secret := hash(point[:32], point[32:])
+	for i := range point {
+		point[i] = 0
+	}
+	return secret, nil
+}
+
+func checkECDHKeys(priv, pubkey []byte) error {
+	if len(priv) != 32 || C.secp256k1_ec_seckey_verify(context, (*C.uchar)(unsafe.Pointer(&priv[0]))) != 1 {
+		return ErrInvalidKey
+	}
+	if len(pubkey) == 0 {
+		return ErrInvalidPubkey
+	}
+	return nil
+}
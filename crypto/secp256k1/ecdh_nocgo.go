@@ -0,0 +1,20 @@
+// Copyright 2021 Stars-labs. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found in
+// the LICENSE file.
+
+// +build gofuzz !cgo
+
+package secp256k1
+
+// ECDH computes the 32-byte shared secret of the private key and the public key of the peer, which is the SHA256 of
+// the shared point priv·pubkey in the compressed format (ECDHHashSHA256), as the ecdh module of libsecp256k1.
+// The public key is in the 33-byte compressed or the 65-byte uncompressed format.
+func ECDH(priv, pubkey []byte) ([]byte, error) {
+	return ecdhGo(priv, pubkey, nil)
+}
+
+// ECDHWithHash computes the shared secret of the private key and the public key of the peer like ECDH, but derives it
+// from the shared point by the hash function, nil is ECDHHashSHA256.
+func ECDHWithHash(priv, pubkey []byte, hash ECDHHashFunc) ([]byte, error) {
+	return ecdhGo(priv, pubkey, hash)
+}
@@ -0,0 +1,92 @@
+// Copyright 2021 Stars-labs. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found in
+// the LICENSE file.
+
+package secp256k1
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+)
+
+// ecdhImpls are the ECDH implementations of the build, and the pure Go one, which is tested in every build.
+var ecdhImpls = []struct {
+	name string
+	ecdh func(priv, pubkey []byte, hash ECDHHashFunc) ([]byte, error)
+}{
+	{"default", ECDHWithHash},
+	{"go", ecdhGo},
+}
+
+func TestECDH(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		pub1, priv1 := generateKeyPair()
+		pub2, priv2 := generateKeyPair()
+		x2, y2 := S256().Unmarshal(pub2)
+		compressed2 := CompressPubkey(x2, y2)
+
+		secret, err := ECDH(priv1, pub2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// both parties derive the same secret, from the compressed or the uncompressed public key
+		if got, err := ECDH(priv2, pub1); err != nil || !bytes.Equal(got, secret) {
+			t.Fatalf("peer secret %x, %v, want %x", got, err, secret)
+		}
+		if got, err := ECDH(priv1, compressed2); err != nil || !bytes.Equal(got, secret) {
+			t.Fatalf("compressed secret %x, %v, want %x", got, err, secret)
+		}
+		// the secret is the SHA256 of the compressed shared point
+		sx, sy := S256().ScalarMult(x2, y2, priv1)
+		want := sha256.Sum256(CompressPubkey(sx, sy))
+		if !bytes.Equal(secret, want[:]) {
+			t.Fatalf("secret %x, want %x", secret, want)
+		}
+		for _, impl := range ecdhImpls {
+			for _, hash := range []ECDHHashFunc{nil, ECDHHashSHA256} {
+				if got, err := impl.ecdh(priv1, pub2, hash); err != nil || !bytes.Equal(got, secret) {
+					t.Fatalf("%s secret %x, %v, want %x", impl.name, got, err, secret)
+				}
+			}
+			if got, err := impl.ecdh(priv2, compressed2[:0:0], ECDHHashX); err != ErrInvalidPubkey {
+				t.Fatalf("%s secret %x, %v, want %v", impl.name, got, err, ErrInvalidPubkey)
+			}
+			got, err := impl.ecdh(priv2, compressed2, ECDHHashX)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// ECDHHashX is the x of the shared point, which is the x of the public key times the private key
+			if px, _ := S256().ScalarMult(x2, y2, priv2); !bytes.Equal(got, px.FillBytes(make([]byte, 32))) {
+				t.Fatalf("%s x %x, want %x", impl.name, got, px)
+			}
+		}
+	}
+}
+
+func TestECDHInvalidInputs(t *testing.T) {
+	pub, priv := generateKeyPair()
+	badPub := append([]byte{}, pub...)
+	badPub[64] ^= 1
+	hybrid := append([]byte{}, pub...)
+	hybrid[0] = 0x06 | pub[64]&1
+	zero := make([]byte, 32)
+	order := S256().N.FillBytes(make([]byte, 32))
+
+	for _, impl := range ecdhImpls {
+		for _, priv := range [][]byte{nil, priv[:31], zero, order} {
+			if _, err := impl.ecdh(priv, pub, nil); err != ErrInvalidKey {
+				t.Errorf("%s: private key %x: got %v, want %v", impl.name, priv, err, ErrInvalidKey)
+			}
+		}
+		for _, pub := range [][]byte{nil, pub[:33], pub[1:], badPub} {
+			if _, err := impl.ecdh(priv, pub, nil); err != ErrInvalidPubkey {
+				t.Errorf("%s: public key %x: got %v, want %v", impl.name, pub, err, ErrInvalidPubkey)
+			}
+		}
+		// the hybrid format is parsed as libsecp256k1 does
+		if _, err := impl.ecdh(priv, hybrid, nil); err != nil {
+			t.Errorf("%s: hybrid public key: %v", impl.name, err)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+// Copyright 2021 Stars-labs. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found in
+// the LICENSE file.
+
+package secp256k1
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"github.com/stars-labs/go-pvss/crypto/scalar"
+	"math/big"
+	"sync"
+)
+
+// The pure Go ECDSA of the package, which follows libsecp256k1 bit for bit: the nonces are the RFC 6979 HMAC-SHA256
+// nonces of libsecp256k1, the signatures have a low s, the verification rejects a high s, and the public keys are parsed
+// in the compressed, uncompressed and hybrid formats. Sign, RecoverPubkey, VerifySignature, DecompressPubkey and
+// CompressPubkey are implemented by libsecp256k1 with cgo, and by the functions below without cgo.
+// The secret keys and the nonces of the signatures are computed on the constant-time scalars modulo N.
+
+var (
+	orderOnce sync.Once
+	order     *scalar.Modulus // the order of the curve, mod N
+)
+
+// orderModulus returns the order N of the curve, the modulus of the secret keys and the nonces.
+func orderModulus() *scalar.Modulus {
+	orderOnce.Do(func() {
+		order, _ = scalar.NewModulus(theCurve.N)
+	})
+	return order
+}
+
+// secretScalar returns the 32-byte big-endian b as a scalar, ok is false if b is 0 or not below N. Only the result leaks.
+func secretScalar(b []byte) (k *scalar.Scalar, ok bool) {
+	k = orderModulus().FromBytes(b)
+	return k, !k.IsZero() && subtle.ConstantTimeCompare(k.Bytes(), b) == 1
+}
+
+func checkSignature(sig []byte) error {
+	if len(sig) != 65 {
+		return ErrInvalidSignatureLen
+	}
+	if sig[64] >= 4 {
+		return ErrInvalidRecoveryID
+	}
+	return nil
+}
+
+// signECDSA is the pure Go Sign.
+func signECDSA(msg []byte, seckey []byte) ([]byte, error) {
+	if len(msg) != 32 {
+		return nil, ErrInvalidMsgLen
+	}
+	if len(seckey) != 32 {
+		return nil, ErrInvalidKey
+	}
+	d, ok := secretScalar(seckey)
+	if !ok {
+		return nil, ErrInvalidKey
+	}
+	defer d.Clear()
+	n := orderModulus()
+	m := n.FromBytes(msg)
+
+	rng := newRFC6979(append(append([]byte{}, seckey...), msg...))
+	for {
+		nonce := rng.generate()
+		k, ok := secretScalar(nonce)
+		if !ok {
+			continue
+		}
+		rx, ry := theCurve.ScalarBaseMult(nonce)
+		// r = R.x mod N, the recovery id tells the parity of R.y and if R.x >= N
+		recid := byte(ry.Bit(0))
+		r := new(big.Int).Set(rx)
+		if r.Cmp(theCurve.N) >= 0 {
+			r.Sub(r, theCurve.N)
+			recid |= 2
+		}
+		// s = k^-1·(m + r·d)
+		ks := n.NewScalar().Mul(n.FromBig(r), d)
+		ks.Add(ks, m)
+		ks.Mul(ks, k.Inverse(k))
+		k.Clear()
+		if ks.IsZero() {
+			continue
+		}
+		s := ks.Big()
+		if isHigh(s) {
+			s.Sub(theCurve.N, s)
+			recid ^= 1
+		}
+		sig := make([]byte, 65)
+		readBits(r, sig[:32])
+		readBits(s, sig[32:64])
+		sig[64] = recid
+		return sig, nil
+	}
+}
+
+// recoverPubkey is the pure Go RecoverPubkey.
+func recoverPubkey(msg []byte, sig []byte) ([]byte, error) {
+	if len(msg) != 32 {
+		return nil, ErrInvalidMsgLen
+	}
+	if err := checkSignature(sig); err != nil {
+		return nil, err
+	}
+	r, s, ok := parseCompact(sig[:64])
+	if !ok || r.Sign() == 0 || s.Sign() == 0 {
+		return nil, ErrRecoverFailed
+	}
+	recid := sig[64]
+	x := new(big.Int).Set(r)
+	if recid&2 != 0 {
+		if x.Add(x, theCurve.N).Cmp(theCurve.P) >= 0 {
+			return nil, ErrRecoverFailed
+		}
+	}
+	rx, ry, ok := decompressPoint(x, recid&1 == 1)
+	if !ok {
+		return nil, ErrRecoverFailed
+	}
+	// Q = r^-1·(s·R - m·G)
+	rInv := new(big.Int).ModInverse(r, theCurve.N)
+	u1 := new(big.Int).SetBytes(msg)
+	u1.Mul(u1, rInv)
+	u1.Neg(u1)
+	u1.Mod(u1, theCurve.N)
+	u2 := new(big.Int).Mul(s, rInv)
+	u2.Mod(u2, theCurve.N)
+	qx, qy := theCurve.MultiScalarMult([]*big.Int{theCurve.Gx, rx}, []*big.Int{theCurve.Gy, ry}, [][]byte{u1.Bytes(), u2.Bytes()})
+	if qx == nil || (qx.Sign() == 0 && qy.Sign() == 0) {
+		return nil, ErrRecoverFailed
+	}
+	return theCurve.Marshal(qx, qy), nil
+}
+
+// verifySignature is the pure Go VerifySignature.
+func verifySignature(pubkey, msg, signature []byte) bool {
+	if len(msg) != 32 || len(signature) != 64 || len(pubkey) == 0 {
+		return false
+	}
+	r, s, ok := parseCompact(signature)
+	if !ok || isHigh(s) || r.Sign() == 0 || s.Sign() == 0 {
+		return false
+	}
+	qx, qy, ok := parsePubkey(pubkey)
+	if !ok {
+		return false
+	}
+	// R = s^-1·(m·G + r·Q), and R.x mod N == r
+	sInv := new(big.Int).ModInverse(s, theCurve.N)
+	u1 := new(big.Int).SetBytes(msg)
+	u1.Mul(u1, sInv)
+	u1.Mod(u1, theCurve.N)
+	u2 := new(big.Int).Mul(r, sInv)
+	u2.Mod(u2, theCurve.N)
+	rx, ry := theCurve.MultiScalarMult([]*big.Int{theCurve.Gx, qx}, []*big.Int{theCurve.Gy, qy}, [][]byte{u1.Bytes(), u2.Bytes()})
+	if rx == nil || (rx.Sign() == 0 && ry.Sign() == 0) {
+		return false
+	}
+	return rx.Mod(rx, theCurve.N).Cmp(r) == 0
+}
+
+// decompressPubkey is the pure Go DecompressPubkey.
+func decompressPubkey(pubkey []byte) (x, y *big.Int) {
+	if len(pubkey) != 33 {
+		return nil, nil
+	}
+	x, y, ok := parsePubkey(pubkey)
+	if !ok {
+		return nil, nil
+	}
+	return x, y
+}
+
+// compressPubkey is the pure Go CompressPubkey.
+func compressPubkey(x, y *big.Int) []byte {
+	x, y, ok := parsePubkey(theCurve.Marshal(x, y))
+	if !ok {
+		panic("invalid public key")
+	}
+	out := make([]byte, 33)
+	out[0] = 0x02 | byte(y.Bit(0))
+	readBits(x, out[1:])
+	return out
+}
+
+// isHigh tells if s > N/2, the signatures of libsecp256k1 have a low s.
+func isHigh(s *big.Int) bool {
+	return s.Cmp(new(big.Int).Rsh(theCurve.N, 1)) > 0
+}
+
+// parseCompact parses the 64-byte [R || S] signature, ok is false if r or s is not below N.
+func parseCompact(sig []byte) (r, s *big.Int, ok bool) {
+	r = new(big.Int).SetBytes(sig[:32])
+	s = new(big.Int).SetBytes(sig[32:64])
+	return r, s, r.Cmp(theCurve.N) < 0 && s.Cmp(theCurve.N) < 0
+}
+
+// parsePubkey parses a public key in the 33-byte compressed format, or in the 65-byte uncompressed or hybrid format.
+func parsePubkey(pub []byte) (x, y *big.Int, ok bool) {
+	switch {
+	case len(pub) == 33 && (pub[0] == 0x02 || pub[0] == 0x03):
+		return decompressPoint(new(big.Int).SetBytes(pub[1:]), pub[0] == 0x03)
+	case len(pub) == 65 && (pub[0] == 0x04 || pub[0] == 0x06 || pub[0] == 0x07):
+		x, y = new(big.Int).SetBytes(pub[1:33]), new(big.Int).SetBytes(pub[33:])
+		if x.Cmp(theCurve.P) >= 0 || y.Cmp(theCurve.P) >= 0 {
+			return nil, nil, false
+		}
+		if pub[0] != 0x04 && (y.Bit(0) == 1) != (pub[0] == 0x07) {
+			return nil, nil, false
+		}
+		if !theCurve.IsOnCurve(x, y) {
+			return nil, nil, false
+		}
+		return x, y, true
+	default:
+		return nil, nil, false
+	}
+}
+
+// decompressPoint returns the point of the x whose y has the parity, ok is false if there is none.
+func decompressPoint(x *big.Int, odd bool) (*big.Int, *big.Int, bool) {
+	xb := make([]byte, 32)
+	if x.Cmp(theCurve.P) >= 0 {
+		return nil, nil, false
+	}
+	readBits(x, xb)
+	x, y := liftX(xb)
+	if x == nil {
+		return nil, nil, false
+	}
+	if odd {
+		y.Sub(theCurve.P, y)
+	}
+	return x, y, true
+}
+
+// rfc6979 is the HMAC-SHA256 deterministic random generator of RFC 6979 section 3.2, as in libsecp256k1.
+type rfc6979 struct {
+	k, v  []byte
+	retry bool
+}
+
+func newRFC6979(key []byte) *rfc6979 {
+	rng := &rfc6979{k: make([]byte, 32), v: make([]byte, 32)}
+	for i := range rng.v {
+		rng.v[i] = 0x01
+	}
+	// K = HMAC_K(V || 0x00 || key), V = HMAC_K(V), K = HMAC_K(V || 0x01 || key), V = HMAC_K(V)
+	rng.k = rng.mac(rng.v, []byte{0x00}, key)
+	rng.v = rng.mac(rng.v)
+	rng.k = rng.mac(rng.v, []byte{0x01}, key)
+	rng.v = rng.mac(rng.v)
+	return rng
+}
+
+func (rng *rfc6979) mac(msgs ...[]byte) []byte {
+	h := hmac.New(sha256.New, rng.k)
+	for _, msg := range msgs {
+		h.Write(msg)
+	}
+	return h.Sum(nil)
+}
+
+// generate returns the next 32 bytes.
+func (rng *rfc6979) generate() []byte {
+	if rng.retry {
+		rng.k = rng.mac(rng.v, []byte{0x00})
+		rng.v = rng.mac(rng.v)
+	}
+	rng.v = rng.mac(rng.v)
+	rng.retry = true
+	return append([]byte{}, rng.v...)
+}
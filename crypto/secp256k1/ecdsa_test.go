@@ -0,0 +1,92 @@
+// Copyright 2021 Stars-labs. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found in
+// the LICENSE file.
+
+package secp256k1
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+)
+
+// TestECDSAGoMatchesDefault checks the pure Go ECDSA against the one of the build, which is libsecp256k1 with cgo.
+func TestECDSAGoMatchesDefault(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		pubkey, seckey := generateKeyPair()
+		msg := csprngEntropy(32)
+		sig, err := Sign(msg, seckey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		goSig, err := signECDSA(msg, seckey)
+		if err != nil || !bytes.Equal(sig, goSig) {
+			t.Fatalf("signature %x, %v, want %x", goSig, err, sig)
+		}
+		recovered, err := recoverPubkey(msg, sig)
+		if err != nil || !bytes.Equal(recovered, pubkey) {
+			t.Fatalf("recovered %x, %v, want %x", recovered, err, pubkey)
+		}
+
+		// a random signature recovers the same key or fails in both
+		random := randSig()
+		want, wantErr := RecoverPubkey(msg, random)
+		got, err := recoverPubkey(msg, random)
+		if err != wantErr || !bytes.Equal(got, want) {
+			t.Fatalf("recovered %x, %v, want %x, %v", got, err, want, wantErr)
+		}
+
+		x, y := S256().Unmarshal(pubkey)
+		compressed := CompressPubkey(x, y)
+		if !bytes.Equal(compressPubkey(x, y), compressed) {
+			t.Fatalf("compressed %x, want %x", compressPubkey(x, y), compressed)
+		}
+		dx, dy := decompressPubkey(compressed)
+		if dx == nil || dx.Cmp(x) != 0 || dy.Cmp(y) != 0 {
+			t.Fatalf("decompressed %x", compressed)
+		}
+		hybrid := append([]byte{0x06 | byte(y.Bit(0))}, pubkey[1:]...)
+		badHybrid := append([]byte{0x07 ^ byte(y.Bit(0))}, pubkey[1:]...)
+
+		// a high s is rejected
+		highS := append([]byte{}, sig[:64]...)
+		s := new(big.Int).SetBytes(highS[32:])
+		readBits(s.Sub(S256().N, s), highS[32:])
+		for _, key := range [][]byte{pubkey, compressed, hybrid, badHybrid, pubkey[:64], compressed[1:]} {
+			for _, signature := range [][]byte{sig[:64], highS} {
+				if verifySignature(key, msg, signature) != VerifySignature(key, msg, signature) {
+					t.Fatalf("verification of %x by %x is not %v", signature, key, VerifySignature(key, msg, signature))
+				}
+			}
+		}
+	}
+}
+
+func TestECDSAGoInvalidInputs(t *testing.T) {
+	_, seckey := generateKeyPair()
+	msg := csprngEntropy(32)
+	for _, key := range [][]byte{seckey[:31], make([]byte, 32), S256().N.Bytes()} {
+		if _, err := signECDSA(msg, key); err != ErrInvalidKey {
+			t.Errorf("got %v, want %v", err, ErrInvalidKey)
+		}
+	}
+	if _, err := signECDSA(msg[:31], seckey); err != ErrInvalidMsgLen {
+		t.Errorf("got %v, want %v", err, ErrInvalidMsgLen)
+	}
+	sig, _ := Sign(msg, seckey)
+	if _, err := recoverPubkey(msg, sig[:64]); err != ErrInvalidSignatureLen {
+		t.Errorf("got %v, want %v", err, ErrInvalidSignatureLen)
+	}
+	sig[64] = 4
+	if _, err := recoverPubkey(msg, sig); err != ErrInvalidRecoveryID {
+		t.Errorf("got %v, want %v", err, ErrInvalidRecoveryID)
+	}
+	copy(sig[32:64], S256().N.Bytes())
+	sig[64] = 0
+	if _, err := recoverPubkey(msg, sig); err != ErrRecoverFailed {
+		t.Errorf("got %v, want %v", err, ErrRecoverFailed)
+	}
+	if x, _ := decompressPubkey(append([]byte{0x02}, S256().P.Bytes()...)); x != nil {
+		t.Errorf("decompressed an x above the field size")
+	}
+}
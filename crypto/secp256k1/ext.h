@@ -128,3 +128,263 @@ int secp256k1_ext_scalar_mul(const secp256k1_context* ctx, unsigned char *point,
 	secp256k1_scalar_clear(&s);
 	return ret;
 }
+
+// secp256k1_ext_strauss_term is a term of secp256k1_ext_multi_scalar_mul: the odd multiples of its point in affine
+// coordinates, and the wNAF of its scalar.
+typedef struct {
+	secp256k1_ge pre[ECMULT_TABLE_SIZE(WINDOW_A)];
+	int wnaf[256];
+	int bits;
+} secp256k1_ext_strauss_term;
+
+// secp256k1_ext_multi_scalar_mul computes the sum of the points multiplied by the scalars in variable time,
+// so it must only be used on public values.
+//
+// It's the multiplication of Strauss, like secp256k1_ecmult for a single point: the wNAFs of all the scalars,
+// split by the endomorphism, share a single chain of about 130 doublings, rather than one chain per point.
+//
+// Returns: 1: multiplication was successful
+//          0: a point or a scalar was invalid (not on the curve or overflow)
+//          2: the sum is the point at infinity
+// Args:    ctx:      pointer to a context object built for verification (cannot be NULL)
+//  Out:    out:      the 64-byte sum, encoded as two 256bit big-endian numbers
+//  In:     points:   pointer to n 64-byte points, encoded as two 256bit big-endian numbers
+//          scalars:  pointer to n 32-byte big-endian scalars
+//          n:        the number of points and scalars
+int secp256k1_ext_multi_scalar_mul(
+	const secp256k1_context* ctx,
+	unsigned char *out,
+	const unsigned char *points,
+	const unsigned char *scalars,
+	size_t n
+) {
+	secp256k1_fe feX, feY;
+	secp256k1_fe zr[ECMULT_TABLE_SIZE(WINDOW_A)];
+	secp256k1_ge ge, tmp;
+	secp256k1_gej gej, sum;
+	secp256k1_gej prej[ECMULT_TABLE_SIZE(WINDOW_A)];
+	secp256k1_scalar s;
+#ifdef USE_ENDOMORPHISM
+	secp256k1_scalar s_1, s_lam;
+	const size_t per_point = 2;
+#else
+	const size_t per_point = 1;
+#endif
+	secp256k1_ext_strauss_term *terms;
+	int overflow = 0;
+	int bits = 0;
+	int i, w;
+	size_t j, k, m = 0;
+	ARG_CHECK(out != NULL);
+	ARG_CHECK(n == 0 || (points != NULL && scalars != NULL));
+	ARG_CHECK(secp256k1_ecmult_context_is_built(&ctx->ecmult_ctx));
+	if (n == 0) {
+		return 2;
+	}
+
+	terms = (secp256k1_ext_strauss_term*)checked_malloc(&ctx->error_callback, sizeof(secp256k1_ext_strauss_term) * per_point * n);
+	for (j = 0; j < n; j++) {
+		if (!secp256k1_fe_set_b32(&feX, points+64*j) || !secp256k1_fe_set_b32(&feY, points+64*j+32)) {
+			free(terms);
+			return 0;
+		}
+		secp256k1_ge_set_xy(&ge, &feX, &feY);
+		secp256k1_scalar_set_b32(&s, scalars+32*j, &overflow);
+		if (!secp256k1_ge_is_valid_var(&ge) || overflow) {
+			free(terms);
+			return 0;
+		}
+		if (secp256k1_scalar_is_zero(&s)) {
+			continue;
+		}
+		secp256k1_gej_set_ge(&gej, &ge);
+		secp256k1_ecmult_odd_multiples_table(ECMULT_TABLE_SIZE(WINDOW_A), prej, zr, &gej);
+		secp256k1_ge_set_table_gej_var(terms[m].pre, prej, zr, ECMULT_TABLE_SIZE(WINDOW_A));
+#ifdef USE_ENDOMORPHISM
+		/* s = s_1 + s_lam·lambda, where s_1 and s_lam are about 128 bits, and lambda·P = (beta·x, y) */
+		secp256k1_scalar_split_lambda(&s_1, &s_lam, &s);
+		terms[m].bits = secp256k1_ecmult_wnaf(terms[m].wnaf, 130, &s_1, WINDOW_A);
+		for (w = 0; w < ECMULT_TABLE_SIZE(WINDOW_A); w++) {
+			secp256k1_ge_mul_lambda(&terms[m+1].pre[w], &terms[m].pre[w]);
+		}
+		terms[m+1].bits = secp256k1_ecmult_wnaf(terms[m+1].wnaf, 130, &s_lam, WINDOW_A);
+#else
+		terms[m].bits = secp256k1_ecmult_wnaf(terms[m].wnaf, 256, &s, WINDOW_A);
+#endif
+		for (k = m; k < m+per_point; k++) {
+			if (terms[k].bits > bits) {
+				bits = terms[k].bits;
+			}
+		}
+		m += per_point;
+	}
+
+	secp256k1_gej_set_infinity(&sum);
+	for (i = bits - 1; i >= 0; i--) {
+		secp256k1_gej_double_var(&sum, &sum, NULL);
+		for (k = 0; k < m; k++) {
+			if (i < terms[k].bits && (w = terms[k].wnaf[i])) {
+				ECMULT_TABLE_GET_GE(&tmp, terms[k].pre, w, WINDOW_A);
+				secp256k1_gej_add_ge_var(&sum, &sum, &tmp, NULL);
+			}
+		}
+	}
+	free(terms);
+	if (secp256k1_gej_is_infinity(&sum)) {
+		return 2;
+	}
+	secp256k1_ge_set_gej(&ge, &sum);
+	secp256k1_fe_normalize(&ge.x);
+	secp256k1_fe_normalize(&ge.y);
+	secp256k1_fe_get_b32(out, &ge.x);
+	secp256k1_fe_get_b32(out+32, &ge.y);
+	return 1;
+}
+
+// secp256k1_ext_schnorrsig_sign creates a BIP-340 Schnorr signature and verifies it.
+//
+// Returns: 1: signing was successful
+//          0: secret key was invalid (zero or overflow), or signing failed
+// Args:    ctx:        pointer to a context object built for signing and verification (cannot be NULL)
+//  Out:    sig64:      the 64-byte signature (cannot be NULL)
+//  In:     msg:        pointer to the message, can be NULL if msglen is 0
+//          msglen:     length of the message
+//          seckey:     pointer to a 32-byte secret key (cannot be NULL)
+//          aux_rand32: pointer to 32 bytes of auxiliary randomness, NULL is the same as zeros
+int secp256k1_ext_schnorrsig_sign(
+	const secp256k1_context* ctx,
+	unsigned char *sig64,
+	const unsigned char *msg,
+	size_t msglen,
+	const unsigned char *seckey,
+	const unsigned char *aux_rand32
+) {
+	secp256k1_keypair keypair;
+	secp256k1_xonly_pubkey pubkey;
+	int ret;
+
+	if (!secp256k1_keypair_create(ctx, &keypair, seckey)) {
+		return 0;
+	}
+	ret = secp256k1_schnorrsig_sign(ctx, sig64, msg, msglen, &keypair, aux_rand32) &&
+		secp256k1_keypair_xonly_pub(ctx, &pubkey, NULL, &keypair) &&
+		secp256k1_schnorrsig_verify(ctx, sig64, msg, msglen, &pubkey);
+	memset(&keypair, 0, sizeof(keypair));
+	return ret;
+}
+
+// secp256k1_ext_schnorrsig_verify verifies a BIP-340 Schnorr signature.
+//
+// Returns: 1: signature is valid
+//          0: signature or public key is invalid
+// Args:    ctx:        pointer to a context object built for verification (cannot be NULL)
+//  In:     sig64:      pointer to a 64-byte signature (cannot be NULL)
+//          msg:        pointer to the message, can be NULL if msglen is 0
+//          msglen:     length of the message
+//          pubkey32:   pointer to a 32-byte x-only public key (cannot be NULL)
+int secp256k1_ext_schnorrsig_verify(
+	const secp256k1_context* ctx,
+	const unsigned char *sig64,
+	const unsigned char *msg,
+	size_t msglen,
+	const unsigned char *pubkey32
+) {
+	secp256k1_xonly_pubkey pubkey;
+
+	if (!secp256k1_xonly_pubkey_parse(ctx, &pubkey, pubkey32)) {
+		return 0;
+	}
+	return secp256k1_schnorrsig_verify(ctx, sig64, msg, msglen, &pubkey);
+}
+
+// secp256k1_ext_xonly_pubkey computes the BIP-340 x-only public key of a secret key.
+//
+// Returns: 1: the public key was computed
+//          0: secret key was invalid (zero or overflow)
+// Args:    ctx:        pointer to a context object built for signing (cannot be NULL)
+//  Out:    pubkey32:   the 32-byte x-only public key (cannot be NULL)
+//  In:     seckey:     pointer to a 32-byte secret key (cannot be NULL)
+int secp256k1_ext_xonly_pubkey(
+	const secp256k1_context* ctx,
+	unsigned char *pubkey32,
+	const unsigned char *seckey
+) {
+	secp256k1_keypair keypair;
+	secp256k1_xonly_pubkey pubkey;
+	int ret;
+
+	if (!secp256k1_keypair_create(ctx, &keypair, seckey)) {
+		return 0;
+	}
+	ret = secp256k1_keypair_xonly_pub(ctx, &pubkey, NULL, &keypair) &&
+		secp256k1_xonly_pubkey_serialize(ctx, pubkey32, &pubkey);
+	memset(&keypair, 0, sizeof(keypair));
+	return ret;
+}
+
+// secp256k1_ext_ecdh computes the hashed ECDH shared secret of the ecdh module: the SHA256 of the
+// compressed shared point.
+//
+// Returns: 1: the shared secret was computed
+//          0: the scalar was invalid (zero or overflow) or the public key could not be parsed
+// Args:    ctx:        pointer to a context object (cannot be NULL)
+//  Out:    result32:   the 32-byte shared secret (cannot be NULL)
+//  In:     pubkeydata: the public key of the peer (cannot be NULL)
+//          pubkeylen:  length of pubkeydata
+//          scalar:     a 32-byte secret key (cannot be NULL)
+int secp256k1_ext_ecdh(
+	const secp256k1_context* ctx,
+	unsigned char *result32,
+	const unsigned char *pubkeydata,
+	size_t pubkeylen,
+	const unsigned char *scalar
+) {
+	secp256k1_pubkey pubkey;
+
+	if (!secp256k1_ec_pubkey_parse(ctx, &pubkey, pubkeydata, pubkeylen)) {
+		return 0;
+	}
+	return secp256k1_ecdh(ctx, result32, &pubkey, scalar);
+}
+
+// secp256k1_ext_ecdh_point computes the shared point of ECDH in constant time, like the ecdh module
+// but without hashing it, so the caller derives the shared secret by its own hash function.
+//
+// Returns: 1: the shared point was computed
+//          0: the scalar was invalid (zero or overflow) or the public key could not be parsed
+// Args:    ctx:        pointer to a context object (cannot be NULL)
+//  Out:    point:      the 64-byte shared point, encoded as two 256bit big-endian numbers (cannot be NULL)
+//  In:     pubkeydata: the public key of the peer (cannot be NULL)
+//          pubkeylen:  length of pubkeydata
+//          scalar:     a 32-byte secret key (cannot be NULL)
+int secp256k1_ext_ecdh_point(
+	const secp256k1_context* ctx,
+	unsigned char *point,
+	const unsigned char *pubkeydata,
+	size_t pubkeylen,
+	const unsigned char *scalar
+) {
+	secp256k1_pubkey pubkey;
+	secp256k1_gej res;
+	secp256k1_ge pt;
+	secp256k1_scalar s;
+	int overflow = 0;
+	int ret = 0;
+
+	if (!secp256k1_ec_pubkey_parse(ctx, &pubkey, pubkeydata, pubkeylen)) {
+		return 0;
+	}
+	secp256k1_pubkey_load(ctx, &pt, &pubkey);
+	secp256k1_scalar_set_b32(&s, scalar, &overflow);
+	if (!overflow && !secp256k1_scalar_is_zero(&s)) {
+		secp256k1_ecmult_const(&res, &pt, &s);
+		secp256k1_ge_set_gej(&pt, &res);
+		secp256k1_fe_normalize(&pt.x);
+		secp256k1_fe_normalize(&pt.y);
+		secp256k1_fe_get_b32(point, &pt.x);
+		secp256k1_fe_get_b32(point+32, &pt.y);
+		ret = 1;
+	}
+	secp256k1_scalar_clear(&s);
+	return ret;
+}
@@ -0,0 +1,206 @@
+// Copyright 2021 Stars-labs. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found in
+// the LICENSE file.
+
+package secp256k1
+
+import (
+	"crypto/sha256"
+	"hash"
+	"math/big"
+)
+
+// The suite secp256k1_XMD:SHA-256_SSWU_RO_ of RFC 9380 (Hashing to Elliptic Curves).
+// secp256k1 has A = 0, so the simplified SWU map runs on the 3-isogenous curve
+// E': y² = x³ + A'·x + B', and the result is mapped back to secp256k1 by iso_map.
+var (
+	// E' and Z, see RFC 9380 section 8.7
+	isoA, _  = new(big.Int).SetString("3f8731abdd661adca08a5558f0f5d272e953d363cb6f0e5d405447c01a444533", 16)
+	isoB     = big.NewInt(1771)
+	sswuZ, _ = new(big.Int).SetString("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc24", 16) // -11
+
+	// constants of iso_map, see RFC 9380 appendix E.1
+	isoK = [4][]*big.Int{
+		// x_num
+		hexInts("8e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38daaaaa8c7",
+			"07d3d4c80bc321d5b9f315cea7fd44c5d595d2fc0bf63b92dfff1044f17c6581",
+			"534c328d23f234e6e2a413deca25caece4506144037c40314ecbd0b53d9dd262",
+			"8e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38daaaaa88c"),
+		// x_den, the leading coefficient is 1
+		hexInts("d35771193d94918a9ca34ccbb7b640dd86cd409542f8487d9fe6b745781eb49b",
+			"edadc6f64383dc1df7c4b2d51b54225406d36b641f5e41bbc52a56612a8c6d14",
+			"01"),
+		// y_num
+		hexInts("4bda12f684bda12f684bda12f684bda12f684bda12f684bda12f684b8e38e23c",
+			"c75e0c32d5cb7c0fa9d0a54b12a0a6d5647ab046d686da6fdffc90fc201d71a3",
+			"29a6194691f91a73715209ef6512e576722830a201be2018a765e85a9ecee931",
+			"2f684bda12f684bda12f684bda12f684bda12f684bda12f684bda12f38e38d84"),
+		// y_den, the leading coefficient is 1
+		hexInts("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffff93b",
+			"7a06534bb8bdb49fd5e9e6632722c2989467c1bfc8e8d978dfb425d2685c2573",
+			"6484aa716545ca2cf3a70c3fa8fe337e0a3d21162f0d6299a7bf8192bfd2a76f",
+			"01"),
+	}
+)
+
+// hashToFieldLen is L = ceil((ceil(log2(p)) + k) / 8) of the suite, where k = 128.
+const hashToFieldLen = 48
+
+// HashToCurve hashes msg to a point of secp256k1 with the domain separation tag dst,
+// as hash_to_curve of the suite secp256k1_XMD:SHA-256_SSWU_RO_ defined in RFC 9380.
+// No one knows the discrete logarithm of the result with respect to any other point.
+//
+// The point at infinity, which happens with negligible probability, is returned as (0, 0).
+func HashToCurve(msg, dst []byte) (x, y *big.Int) {
+	curve := S256()
+	uniform := ExpandMessageXMD(sha256.New, msg, dst, 2*hashToFieldLen)
+	u0 := new(big.Int).SetBytes(uniform[:hashToFieldLen])
+	u1 := new(big.Int).SetBytes(uniform[hashToFieldLen:])
+	x0, y0 := mapToCurve(u0.Mod(u0, curve.P))
+	x1, y1 := mapToCurve(u1.Mod(u1, curve.P))
+	if x0.Sign() == 0 && y0.Sign() == 0 {
+		return x1, y1
+	}
+	if x1.Sign() == 0 && y1.Sign() == 0 {
+		return x0, y0
+	}
+	// Q0 == -Q1
+	if x0.Cmp(x1) == 0 && y0.Cmp(y1) != 0 {
+		return new(big.Int), new(big.Int)
+	}
+	// the cofactor of secp256k1 is 1, so clear_cofactor is a no-op
+	return curve.Add(x0, y0, x1, y1)
+}
+
+// mapToCurve maps the field element u to secp256k1, by the simplified SWU map to E' followed by iso_map.
+func mapToCurve(u *big.Int) (x, y *big.Int) {
+	p := S256().P
+
+	// map_to_curve_simple_swu, RFC 9380 section 6.6.2
+	// tv1 = inv0(Z²·u⁴ + Z·u²)
+	zu2 := new(big.Int).Mul(u, u)
+	zu2.Mul(zu2, sswuZ).Mod(zu2, p)
+	tv1 := new(big.Int).Mul(zu2, zu2)
+	tv1.Add(tv1, zu2).Mod(tv1, p)
+	var x1 *big.Int
+	if tv1.Sign() == 0 {
+		// x1 = B / (Z·A)
+		x1 = new(big.Int).Mul(sswuZ, isoA)
+		x1.ModInverse(x1, p)
+		x1.Mul(x1, isoB)
+	} else {
+		// x1 = (-B / A)·(1 + tv1)
+		tv1.ModInverse(tv1, p)
+		x1 = new(big.Int).ModInverse(isoA, p)
+		x1.Mul(x1, new(big.Int).Sub(p, isoB))
+		x1.Mul(x1, tv1.Add(tv1, big.NewInt(1)))
+	}
+	x1.Mod(x1, p)
+
+	xp, yp := x1, new(big.Int).ModSqrt(isoCurve(x1), p)
+	if yp == nil {
+		// x2 = Z·u²·x1
+		xp = new(big.Int).Mul(zu2, x1)
+		xp.Mod(xp, p)
+		yp = new(big.Int).ModSqrt(isoCurve(xp), p)
+	}
+	if u.Bit(0) != yp.Bit(0) {
+		yp.Sub(p, yp)
+	}
+	return isoMap(xp, yp)
+}
+
+// isoCurve returns x³ + A'·x + B' of E'.
+func isoCurve(x *big.Int) *big.Int {
+	p := S256().P
+	gx := new(big.Int).Mul(x, x)
+	gx.Add(gx, isoA)
+	gx.Mul(gx, x)
+	gx.Add(gx, isoB)
+	return gx.Mod(gx, p)
+}
+
+// isoMap maps the point (x', y') of E' to secp256k1 by the 3-isogeny, RFC 9380 appendix E.1:
+// x = x_num / x_den, y = y' · y_num / y_den.
+func isoMap(xp, yp *big.Int) (x, y *big.Int) {
+	p := S256().P
+	var evals [4]*big.Int
+	for i, k := range isoK {
+		// Horner's method, k[i] is the coefficient of x'^i
+		v := new(big.Int)
+		for j := len(k) - 1; j >= 0; j-- {
+			v.Mul(v, xp)
+			v.Add(v, k[j])
+			v.Mod(v, p)
+		}
+		evals[i] = v
+	}
+	if evals[1].Sign() == 0 || evals[3].Sign() == 0 {
+		// the exceptional cases of iso_map are mapped to the point at infinity
+		return new(big.Int), new(big.Int)
+	}
+	x = new(big.Int).ModInverse(evals[1], p)
+	x.Mul(x, evals[0]).Mod(x, p)
+	y = new(big.Int).ModInverse(evals[3], p)
+	y.Mul(y, evals[2]).Mul(y, yp).Mod(y, p)
+	return x, y
+}
+
+// ExpandMessageXMD implements expand_message_xmd of RFC 9380 section 5.3.1,
+// it expands msg to lenInBytes uniformly random bytes with the hash function h and the domain separation tag dst.
+// It is shared by the hash_to_curve suites of the package and of the pvss groups.
+func ExpandMessageXMD(h func() hash.Hash, msg, dst []byte, lenInBytes int) []byte {
+	hasher := h()
+	bInBytes, sInBytes := hasher.Size(), hasher.BlockSize()
+	if len(dst) > 255 {
+		// oversized DST, see RFC 9380 section 5.3.3
+		hasher.Write([]byte("H2C-OVERSIZE-DST-"))
+		hasher.Write(dst)
+		dst = hasher.Sum(nil)
+		hasher.Reset()
+	}
+	ell := (lenInBytes + bInBytes - 1) / bInBytes
+	if ell > 255 || lenInBytes > 65535 {
+		panic("secp256k1: expand_message_xmd output too long")
+	}
+	dstPrime := append(append([]byte{}, dst...), byte(len(dst)))
+
+	// b_0 = H(Z_pad || msg || l_i_b_str || I2OSP(0, 1) || DST_prime)
+	hasher.Write(make([]byte, sInBytes))
+	hasher.Write(msg)
+	hasher.Write([]byte{byte(lenInBytes >> 8), byte(lenInBytes), 0})
+	hasher.Write(dstPrime)
+	b0 := hasher.Sum(nil)
+
+	// b_1 = H(b_0 || I2OSP(1, 1) || DST_prime)
+	hasher.Reset()
+	hasher.Write(b0)
+	hasher.Write([]byte{1})
+	hasher.Write(dstPrime)
+	bi := hasher.Sum(nil)
+
+	uniform := make([]byte, 0, ell*bInBytes)
+	uniform = append(uniform, bi...)
+	for i := 2; i <= ell; i++ {
+		// b_i = H(strxor(b_0, b_(i - 1)) || I2OSP(i, 1) || DST_prime)
+		xored := make([]byte, bInBytes)
+		for j := range xored {
+			xored[j] = b0[j] ^ bi[j]
+		}
+		hasher.Reset()
+		hasher.Write(xored)
+		hasher.Write([]byte{byte(i)})
+		hasher.Write(dstPrime)
+		bi = hasher.Sum(nil)
+		uniform = append(uniform, bi...)
+	}
+	return uniform[:lenInBytes]
+}
+
+func hexInts(hexes ...string) []*big.Int {
+	ints := make([]*big.Int, len(hexes))
+	for i, h := range hexes {
+		ints[i], _ = new(big.Int).SetString(h, 16)
+	}
+	return ints
+}
@@ -0,0 +1,72 @@
+// Copyright 2021 Stars-labs. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found in
+// the LICENSE file.
+
+package secp256k1
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"hash"
+	"strings"
+	"testing"
+)
+
+// Test vectors of secp256k1_XMD:SHA-256_SSWU_RO_, RFC 9380 appendix J.8.1.
+func TestHashToCurve(t *testing.T) {
+	dst := []byte("QUUX-V01-CS02-with-secp256k1_XMD:SHA-256_SSWU_RO_")
+	vectors := []struct {
+		msg  string
+		x, y string
+	}{
+		{"",
+			"c1cae290e291aee617ebaef1be6d73861479c48b841eaba9b7b5852ddfeb1346",
+			"64fa678e07ae116126f08b022a94af6de15985c996c3a91b64c406a960e51067"},
+		{"abc",
+			"3377e01eab42db296b512293120c6cee72b6ecf9f9205760bd9ff11fb3cb2c4b",
+			"7f95890f33efebd1044d382a01b1bee0900fb6116f94688d487c6c7b9c8371f6"},
+		{"abcdef0123456789",
+			"bac54083f293f1fe08e4a70137260aa90783a5cb84d3f35848b324d0674b0e3a",
+			"4436476085d4c3c4508b60fcf4389c40176adce756b398bdee27bca19758d828"},
+		{"q128_" + strings.Repeat("q", 128),
+			"e2167bc785333a37aa562f021f1e881defb853839babf52a7f72b102e41890e9",
+			"f2401dd95cc35867ffed4f367cd564763719fbc6a53e969fb8496a1e6685d873"},
+		{"a512_" + strings.Repeat("a", 512),
+			"e3c8d35aaaf0b9b647e88a0a0a7ee5d5bed5ad38238152e4e6fd8c1f8cb7c998",
+			"8446eeb6181bf12f56a9d24e262221cc2f0c4725c7e3803024b5888ee5823aa6"},
+	}
+	for _, v := range vectors {
+		x, y := HashToCurve([]byte(v.msg), dst)
+		expected := hexInts(v.x, v.y)
+		if x.Cmp(expected[0]) != 0 || y.Cmp(expected[1]) != 0 {
+			t.Errorf("msg %q: got (%x, %x), want (%s, %s)", v.msg, x, y, v.x, v.y)
+		}
+		if !S256().IsOnCurve(x, y) {
+			t.Errorf("msg %q: point is not on the curve", v.msg)
+		}
+	}
+}
+
+func TestExpandMessageXMD(t *testing.T) {
+	// test vectors from RFC 9380 appendix K.1 and K.3
+	vectors := []struct {
+		h        func() hash.Hash
+		dst, msg string
+		len      int
+		uniform  string
+	}{
+		{sha256.New, "QUUX-V01-CS02-with-expander-SHA256-128", "", 0x20,
+			"68a985b87eb6b46952128911f2a4412bbc302a9d759667f87f7a21d803f07235"},
+		{sha256.New, "QUUX-V01-CS02-with-expander-SHA256-128", "abc", 0x20,
+			"d8ccab23b5985ccea865c6c97b6e5b8350e794e603b4b97902f53a8a0d605615"},
+		{sha512.New, "QUUX-V01-CS02-with-expander-SHA512-256", "", 0x20,
+			"6b9a7312411d92f921c6f68ca0b6380730a1a4d982c507211a90964c394179ba"},
+	}
+	for _, v := range vectors {
+		uniform := ExpandMessageXMD(v.h, []byte(v.msg), []byte(v.dst), v.len)
+		if got := hex.EncodeToString(uniform); got != v.uniform {
+			t.Errorf("expand_message_xmd(%q, %q) = %s, want %s", v.msg, v.dst, got, v.uniform)
+		}
+	}
+}
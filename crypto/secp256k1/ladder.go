@@ -0,0 +1,134 @@
+// Copyright 2021 Stars-labs. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found in
+// the LICENSE file.
+
+package secp256k1
+
+import (
+	"github.com/stars-labs/go-pvss/crypto/scalar"
+	"math/big"
+	"sync"
+)
+
+// The constant-time scalar multiplication of the pure Go build: a Montgomery ladder over the 256 bits of the scalar,
+// with the complete addition formulas of Renes, Costello and Batina for a = 0 ("Complete addition formulas for prime
+// order elliptic curves", algorithm 7), in projective coordinates on the fixed-width field elements of the scalar
+// package. The formulas have no exceptional case, not even the point at infinity or a doubling, so the ladder runs the
+// same operations for every scalar, and its two points are swapped in constant time.
+
+var (
+	fieldOnce sync.Once
+	field     *scalar.Modulus // the field of the curve, mod P
+	fieldB3   *scalar.Scalar  // 3·b = 21
+)
+
+func initField() {
+	fieldOnce.Do(func() {
+		field, _ = scalar.NewModulus(theCurve.P)
+		fieldB3 = field.FromBig(new(big.Int).Mul(big.NewInt(3), theCurve.B))
+	})
+}
+
+// projectivePoint is the point (X/Z, Y/Z), or the point at infinity (0:1:0).
+type projectivePoint struct {
+	x, y, z *scalar.Scalar
+}
+
+// add returns p + q, for any points of the curve.
+func (p *projectivePoint) add(q *projectivePoint) *projectivePoint {
+	f := field
+	t0 := f.NewScalar().Mul(p.x, q.x)
+	t1 := f.NewScalar().Mul(p.y, q.y)
+	t2 := f.NewScalar().Mul(p.z, q.z)
+	t3 := f.NewScalar().Add(p.x, p.y)
+	t4 := f.NewScalar().Add(q.x, q.y)
+	t3.Mul(t3, t4)
+	t4.Add(t0, t1)
+	t3.Sub(t3, t4) // X1·Y2 + X2·Y1
+	t4.Add(p.y, p.z)
+	x3 := f.NewScalar().Add(q.y, q.z)
+	t4.Mul(t4, x3)
+	x3.Add(t1, t2)
+	t4.Sub(t4, x3) // Y1·Z2 + Y2·Z1
+	x3.Add(p.x, p.z)
+	y3 := f.NewScalar().Add(q.x, q.z)
+	x3.Mul(x3, y3)
+	y3.Add(t0, t2)
+	y3.Sub(x3, y3) // X1·Z2 + X2·Z1
+	x3.Add(t0, t0)
+	t0.Add(x3, t0) // 3·X1·X2
+	t2.Mul(fieldB3, t2)
+	z3 := f.NewScalar().Add(t1, t2)
+	t1.Sub(t1, t2)
+	y3.Mul(fieldB3, y3)
+	x3.Mul(t4, y3)
+	t2.Mul(t3, t1)
+	x3.Sub(t2, x3)
+	y3.Mul(y3, t0)
+	t1.Mul(t1, z3)
+	y3.Add(t1, y3)
+	t0.Mul(t0, t3)
+	z3.Mul(z3, t4)
+	z3.Add(z3, t0)
+	return &projectivePoint{x3, y3, z3}
+}
+
+// swap exchanges p and q if cond is 1, in constant time.
+func (p *projectivePoint) swap(q *projectivePoint, cond int) {
+	p.x.Swap(q.x, cond)
+	p.y.Swap(q.y, cond)
+	p.z.Swap(q.z, cond)
+}
+
+// scalarMultLadder returns k·(Bx, By), or nil, nil if the scalar is zero or not below N, in a time which doesn't
+// depend on the scalar. The point must be on the curve.
+func scalarMultLadder(Bx, By *big.Int, kb []byte) (*big.Int, *big.Int) {
+	if len(kb) > 32 {
+		panic("can't handle scalars > 256 bits")
+	}
+	k := make([]byte, 32)
+	copy(k[32-len(kb):], kb)
+	defer func() {
+		for i := range k {
+			k[i] = 0
+		}
+	}()
+	if !validScalar(k) {
+		return nil, nil
+	}
+	initField()
+
+	r0 := &projectivePoint{field.NewScalar(), field.One(), field.NewScalar()}
+	r1 := &projectivePoint{field.FromBig(Bx), field.FromBig(By), field.One()}
+	// r1 - r0 = B, from the most significant bit: a bit of 0 sets r0 = 2·r0, r1 = r0 + r1,
+	// and a bit of 1 sets r0 = r0 + r1, r1 = 2·r1
+	for _, c := range k {
+		for i := 7; i >= 0; i-- {
+			bit := int(c >> uint(i) & 1)
+			r0.swap(r1, bit)
+			r1 = r0.add(r1)
+			r0 = r0.add(r0)
+			r0.swap(r1, bit)
+		}
+	}
+
+	// B has the prime order N and 0 < k < N, so r0 is not the point at infinity
+	zInv := field.NewScalar().Inverse(r0.z)
+	x := field.NewScalar().Mul(r0.x, zInv)
+	y := field.NewScalar().Mul(r0.y, zInv)
+	return x.Big(), y.Big()
+}
+
+// validScalar reports whether the 32-byte k is in [1, N), in constant time.
+func validScalar(k []byte) bool {
+	n := make([]byte, 32)
+	readBits(theCurve.N, n)
+	// k - N borrows if k < N
+	var borrow, nonZero int
+	for i := 31; i >= 0; i-- {
+		d := int(k[i]) - int(n[i]) - borrow
+		borrow = (d >> 8) & 1
+		nonZero |= int(k[i])
+	}
+	return borrow&^((nonZero-1)>>8&1) == 1
+}
@@ -0,0 +1,66 @@
+// Copyright 2021 Stars-labs. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found in
+// the LICENSE file.
+
+package secp256k1
+
+import (
+	"math/big"
+	"testing"
+)
+
+// TestScalarMultLadder checks the constant-time ladder against the ScalarMult of the build and the double-and-add
+// of the Jacobian formulas.
+func TestScalarMultLadder(t *testing.T) {
+	curve := S256()
+	nMinus1 := new(big.Int).Sub(curve.N, big.NewInt(1))
+	scalars := [][]byte{{1}, {2}, {3}, nMinus1.Bytes(), new(big.Int).Rsh(curve.N, 1).Bytes()}
+	for i := 0; i < 20; i++ {
+		scalars = append(scalars, csprngEntropy(32))
+	}
+	px, py := curve.ScalarBaseMult(csprngEntropy(32))
+	for _, k := range scalars {
+		for _, p := range [][2]*big.Int{{curve.Gx, curve.Gy}, {px, py}} {
+			x, y := scalarMultLadder(p[0], p[1], k)
+			wantX, wantY := curve.ScalarMult(p[0], p[1], k)
+			if x == nil || x.Cmp(wantX) != 0 || y.Cmp(wantY) != 0 {
+				t.Fatalf("%x·(%x, %x) = (%x, %x), want (%x, %x)", k, p[0], p[1], x, y, wantX, wantY)
+			}
+			sum := jacobianPoint{new(big.Int), new(big.Int), new(big.Int)}
+			for _, bit := range new(big.Int).SetBytes(k).Text(2) {
+				sum = curve.addJacobianVar(sum, sum)
+				if bit == '1' {
+					sum = curve.addJacobianVar(sum, jacobianPoint{p[0], p[1], big.NewInt(1)})
+				}
+			}
+			if wantX, wantY = curve.affineFromJacobian(sum.x, sum.y, sum.z); x.Cmp(wantX) != 0 || y.Cmp(wantY) != 0 {
+				t.Fatalf("%x·(%x, %x) = (%x, %x), want (%x, %x)", k, p[0], p[1], x, y, wantX, wantY)
+			}
+		}
+	}
+	// (N-1)·G = -G
+	if x, y := scalarMultLadder(curve.Gx, curve.Gy, nMinus1.Bytes()); x.Cmp(curve.Gx) != 0 || y.Cmp(new(big.Int).Sub(curve.P, curve.Gy)) != 0 {
+		t.Fatalf("(N-1)·G = (%x, %x)", x, y)
+	}
+	nPlus1 := new(big.Int).Add(curve.N, big.NewInt(1))
+	for _, k := range [][]byte{nil, make([]byte, 32), curve.N.Bytes(), nPlus1.Bytes(), bytesOf(0xff, 32)} {
+		if x, y := scalarMultLadder(curve.Gx, curve.Gy, k); x != nil || y != nil {
+			t.Errorf("%x·G = (%x, %x), want nil", k, x, y)
+		}
+	}
+}
+
+func bytesOf(b byte, n int) []byte {
+	s := make([]byte, n)
+	for i := range s {
+		s[i] = b
+	}
+	return s
+}
+
+func BenchmarkScalarMultLadder(b *testing.B) {
+	k := csprngEntropy(32)
+	for i := 0; i < b.N; i++ {
+		scalarMultLadder(theCurve.Gx, theCurve.Gy, k)
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2021 Stars-labs. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found in
+// the LICENSE file.
+
+package secp256k1
+
+import (
+	"math/big"
+)
+
+// msmTerms checks the inputs of MultiScalarMult, reduces the scalars modulo N, and drops the terms which add nothing:
+// the points at infinity (0, 0) and the zero scalars. ok is false if a point is not on the curve.
+func (BitCurve *BitCurve) msmTerms(xs, ys []*big.Int, scalars [][]byte) (txs, tys, ks []*big.Int, ok bool) {
+	if len(xs) != len(ys) || len(xs) != len(scalars) {
+		panic("MultiScalarMult with different numbers of points and scalars")
+	}
+	for i, scalar := range scalars {
+		if len(scalar) > 32 {
+			panic("can't handle scalars > 256 bits")
+		}
+		if xs[i].Sign() == 0 && ys[i].Sign() == 0 {
+			continue
+		}
+		if xs[i].Cmp(BitCurve.P) >= 0 || ys[i].Cmp(BitCurve.P) >= 0 || !BitCurve.IsOnCurve(xs[i], ys[i]) {
+			return nil, nil, nil, false
+		}
+		k := new(big.Int).SetBytes(scalar)
+		if k.Mod(k, BitCurve.N).Sign() == 0 {
+			continue
+		}
+		txs, tys, ks = append(txs, xs[i]), append(tys, ys[i]), append(ks, k)
+	}
+	return txs, tys, ks, true
+}
+
+// jacobianPoint is a point in Jacobian coordinates, z = 0 is the point at infinity.
+type jacobianPoint struct {
+	x, y, z *big.Int
+}
+
+// addJacobianVar adds two points in Jacobian coordinates like addJacobian, but also handles
+// the point at infinity, the doubling and the sum of opposite points.
+func (BitCurve *BitCurve) addJacobianVar(p, q jacobianPoint) jacobianPoint {
+	if p.z.Sign() == 0 {
+		return q
+	}
+	if q.z.Sign() == 0 {
+		return p
+	}
+	// p == ±q iff x1·z2² == x2·z1²
+	z1z1 := new(big.Int).Mul(p.z, p.z)
+	z2z2 := new(big.Int).Mul(q.z, q.z)
+	u1 := new(big.Int).Mul(p.x, z2z2)
+	u2 := new(big.Int).Mul(q.x, z1z1)
+	if u1.Mod(u1, BitCurve.P).Cmp(u2.Mod(u2, BitCurve.P)) == 0 {
+		s1 := new(big.Int).Mul(p.y, z2z2.Mul(z2z2, q.z))
+		s2 := new(big.Int).Mul(q.y, z1z1.Mul(z1z1, p.z))
+		if s1.Mod(s1, BitCurve.P).Cmp(s2.Mod(s2, BitCurve.P)) != 0 {
+			return jacobianPoint{new(big.Int), new(big.Int), new(big.Int)}
+		}
+		x, y, z := BitCurve.doubleJacobian(p.x, p.y, p.z)
+		return jacobianPoint{x, y, z}
+	}
+	x, y, z := BitCurve.addJacobian(p.x, p.y, p.z, q.x, q.y, q.z)
+	return jacobianPoint{x, y, z}
+}
+
+// pippenger computes ∑ ks[i]·(xs[i], ys[i]) by the bucket method of Pippenger, in variable time.
+// The scalars are in [1, N) and the points are on the curve, as returned by msmTerms.
+//
+// The scalars are cut into windows of c bits. For every window, from the most significant one, the points
+// are put into the bucket of their digit, and the window sum ∑ j·B_j is computed with 2·2^c additions
+// by the running sums B_(2^c-1), B_(2^c-1)+B_(2^c-2), ... .
+func (BitCurve *BitCurve) pippenger(xs, ys, ks []*big.Int) (*big.Int, *big.Int) {
+	infinity := func() jacobianPoint { return jacobianPoint{new(big.Int), new(big.Int), new(big.Int)} }
+	one := big.NewInt(1)
+	c := pippengerWindow(len(ks))
+	buckets := make([]jacobianPoint, 1<<c)
+	sum := infinity()
+	for w := (BitCurve.BitSize+c-1)/c - 1; w >= 0; w-- {
+		for i := 0; i < c && sum.z.Sign() != 0; i++ {
+			sum.x, sum.y, sum.z = BitCurve.doubleJacobian(sum.x, sum.y, sum.z)
+		}
+		for j := range buckets {
+			buckets[j] = infinity()
+		}
+		for i, k := range ks {
+			digit := 0
+			for b := c - 1; b >= 0; b-- {
+				digit = digit<<1 | int(k.Bit(w*c+b))
+			}
+			if digit != 0 {
+				buckets[digit] = BitCurve.addJacobianVar(buckets[digit], jacobianPoint{xs[i], ys[i], one})
+			}
+		}
+		running, window := infinity(), infinity()
+		for j := len(buckets) - 1; j > 0; j-- {
+			running = BitCurve.addJacobianVar(running, buckets[j])
+			window = BitCurve.addJacobianVar(window, running)
+		}
+		sum = BitCurve.addJacobianVar(sum, window)
+	}
+	return BitCurve.affineFromJacobian(sum.x, sum.y, sum.z)
+}
+
+// pippengerWindow returns the window size in bits for n terms, which minimizes the number of additions:
+// for every window, n additions into the buckets and 2·2^c additions of the window sum.
+func pippengerWindow(n int) int {
+	best, bestCost := 1, -1
+	for c := 1; c <= 16; c++ {
+		cost := (256 + c - 1) / c * (n + 2<<c)
+		if bestCost < 0 || cost < bestCost {
+			best, bestCost = c, cost
+		}
+	}
+	return best
+}
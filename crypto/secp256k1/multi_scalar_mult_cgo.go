@@ -0,0 +1,56 @@
+// Copyright 2021 Stars-labs. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found in
+// the LICENSE file.
+
+// +build !gofuzz
+// +build cgo
+
+package secp256k1
+
+import (
+	"math/big"
+	"unsafe"
+)
+
+/*
+
+#include "libsecp256k1/include/secp256k1.h"
+
+extern int secp256k1_ext_multi_scalar_mul(const secp256k1_context* ctx, unsigned char *out, const unsigned char *points, const unsigned char *scalars, size_t n);
+
+*/
+import "C"
+
+// MultiScalarMult returns ∑ scalars[i]·(xs[i], ys[i]), where the scalars are big-endian and at most 256 bits.
+// The points at infinity are (0, 0), and so is the sum at infinity. It returns nil, nil if a point is not on the curve.
+//
+// The sum is computed in variable time by the multiplication of Strauss on the wNAFs of libsecp256k1, whose terms share
+// a single chain of doublings, so it must only be used on public values.
+func (BitCurve *BitCurve) MultiScalarMult(xs, ys []*big.Int, scalars [][]byte) (*big.Int, *big.Int) {
+	xs, ys, ks, ok := BitCurve.msmTerms(xs, ys, scalars)
+	if !ok {
+		return nil, nil
+	}
+	if len(ks) == 0 {
+		return new(big.Int), new(big.Int)
+	}
+	points := make([]byte, 64*len(ks))
+	packed := make([]byte, 32*len(ks))
+	for i, k := range ks {
+		readBits(xs[i], points[64*i:64*i+32])
+		readBits(ys[i], points[64*i+32:64*i+64])
+		readBits(k, packed[32*i:32*i+32])
+	}
+	out := make([]byte, 64)
+	outPtr := (*C.uchar)(unsafe.Pointer(&out[0]))
+	pointsPtr := (*C.uchar)(unsafe.Pointer(&points[0]))
+	scalarsPtr := (*C.uchar)(unsafe.Pointer(&packed[0]))
+	switch C.secp256k1_ext_multi_scalar_mul(context, outPtr, pointsPtr, scalarsPtr, C.size_t(len(ks))) {
+	case 1:
+		return new(big.Int).SetBytes(out[:32]), new(big.Int).SetBytes(out[32:])
+	case 2:
+		return new(big.Int), new(big.Int)
+	default:
+		return nil, nil
+	}
+}
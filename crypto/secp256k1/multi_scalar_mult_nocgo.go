@@ -0,0 +1,24 @@
+// Copyright 2021 Stars-labs. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found in
+// the LICENSE file.
+
+// +build gofuzz !cgo
+
+package secp256k1
+
+import "math/big"
+
+// MultiScalarMult returns ∑ scalars[i]·(xs[i], ys[i]), where the scalars are big-endian and at most 256 bits.
+// The points at infinity are (0, 0), and so is the sum at infinity. It returns nil, nil if a point is not on the curve.
+//
+// The sum is computed in variable time by the Pippenger bucket method, so it must only be used on public values.
+func (BitCurve *BitCurve) MultiScalarMult(xs, ys []*big.Int, scalars [][]byte) (*big.Int, *big.Int) {
+	xs, ys, ks, ok := BitCurve.msmTerms(xs, ys, scalars)
+	if !ok {
+		return nil, nil
+	}
+	if len(ks) == 0 {
+		return new(big.Int), new(big.Int)
+	}
+	return BitCurve.pippenger(xs, ys, ks)
+}
@@ -0,0 +1,128 @@
+// Copyright 2021 Stars-labs. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found in
+// the LICENSE file.
+
+package secp256k1
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+// naiveScalarMult computes k·(x, y) by double-and-add on the affine Add and Double, as an independent reference.
+func naiveScalarMult(x, y *big.Int, k *big.Int) (*big.Int, *big.Int) {
+	curve := S256()
+	rx, ry := new(big.Int), new(big.Int)
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		if rx.Sign() != 0 || ry.Sign() != 0 {
+			rx, ry = curve.Double(rx, ry)
+		}
+		if k.Bit(i) == 1 {
+			rx, ry = curve.Add(rx, ry, x, y)
+		}
+	}
+	return rx, ry
+}
+
+func randomPoint(t *testing.T) (*big.Int, *big.Int) {
+	k, err := rand.Int(rand.Reader, S256().N)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return naiveScalarMult(S256().Gx, S256().Gy, k)
+}
+
+func TestMultiScalarMult(t *testing.T) {
+	curve := S256()
+	for _, n := range []int{1, 2, 5, 33} {
+		xs, ys, scalars := make([]*big.Int, n), make([]*big.Int, n), make([][]byte, n)
+		wantX, wantY := new(big.Int), new(big.Int)
+		for i := range xs {
+			xs[i], ys[i] = randomPoint(t)
+			k, _ := rand.Int(rand.Reader, curve.N)
+			switch i % 5 {
+			case 1:
+				// a point at infinity
+				xs[i], ys[i] = new(big.Int), new(big.Int)
+			case 2:
+				// a zero scalar
+				k.SetInt64(0)
+			case 3:
+				// the same point twice
+				xs[i], ys[i] = xs[i-3], ys[i-3]
+			}
+			scalars[i] = k.Bytes()
+			px, py := naiveScalarMult(xs[i], ys[i], k)
+			wantX, wantY = curve.Add(wantX, wantY, px, py)
+		}
+		x, y := curve.MultiScalarMult(xs, ys, scalars)
+		if x == nil || x.Cmp(wantX) != 0 || y.Cmp(wantY) != 0 {
+			t.Errorf("MultiScalarMult of %d terms: got (%x, %x), want (%x, %x)", n, x, y, wantX, wantY)
+		}
+		ts, tys, ks, ok := curve.msmTerms(xs, ys, scalars)
+		if !ok {
+			t.Fatal("msmTerms rejected the points")
+		}
+		if x, y := curve.pippenger(ts, tys, ks); x.Cmp(wantX) != 0 || y.Cmp(wantY) != 0 {
+			t.Errorf("pippenger of %d terms: got (%x, %x), want (%x, %x)", n, x, y, wantX, wantY)
+		}
+	}
+}
+
+func TestMultiScalarMultEdgeCases(t *testing.T) {
+	curve := S256()
+	x, y := randomPoint(t)
+	negY := new(big.Int).Sub(curve.P, y)
+	two := []byte{2}
+
+	// no terms, and P + (-P) are at infinity
+	if x, y := curve.MultiScalarMult(nil, nil, nil); x.Sign() != 0 || y.Sign() != 0 {
+		t.Errorf("empty sum is not at infinity")
+	}
+	if x, y := curve.MultiScalarMult([]*big.Int{x, x}, []*big.Int{y, negY}, [][]byte{two, two}); x.Sign() != 0 || y.Sign() != 0 {
+		t.Errorf("2·P + 2·(-P) is not at infinity")
+	}
+	// the scalars are reduced modulo N
+	nPlus2 := new(big.Int).Add(curve.N, big.NewInt(2)).Bytes()
+	wantX, wantY := curve.Double(x, y)
+	if gotX, gotY := curve.MultiScalarMult([]*big.Int{x}, []*big.Int{y}, [][]byte{nPlus2}); gotX.Cmp(wantX) != 0 || gotY.Cmp(wantY) != 0 {
+		t.Errorf("(N+2)·P != 2·P")
+	}
+	// a point not on the curve
+	if gotX, gotY := curve.MultiScalarMult([]*big.Int{x}, []*big.Int{new(big.Int).Add(y, big.NewInt(1))}, [][]byte{two}); gotX != nil || gotY != nil {
+		t.Errorf("a point not on the curve is accepted")
+	}
+}
+
+func BenchmarkMultiScalarMult(b *testing.B) {
+	curve := S256()
+	n := 20
+	xs, ys, scalars := make([]*big.Int, n), make([]*big.Int, n), make([][]byte, n)
+	for i := range xs {
+		k, _ := rand.Int(rand.Reader, curve.N)
+		xs[i], ys[i] = naiveScalarMult(curve.Gx, curve.Gy, k)
+		k, _ = rand.Int(rand.Reader, curve.N)
+		scalars[i] = k.Bytes()
+	}
+	b.Run("MultiScalarMult", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			curve.MultiScalarMult(xs, ys, scalars)
+		}
+	})
+	b.Run("ScalarMult", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			sumX, sumY := new(big.Int), new(big.Int)
+			for j := range xs {
+				x, y := curve.ScalarMult(xs[j], ys[j], scalars[j])
+				sumX, sumY = curve.Add(sumX, sumY, x, y)
+			}
+		}
+	})
+	b.Run("pippenger", func(b *testing.B) {
+		ts, tys, ks, _ := curve.msmTerms(xs, ys, scalars)
+		for i := 0; i < b.N; i++ {
+			curve.pippenger(ts, tys, ks)
+		}
+	})
+}
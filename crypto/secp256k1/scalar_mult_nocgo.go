@@ -8,6 +8,9 @@ package secp256k1
 
 import "math/big"
 
+// ScalarMult returns scalar·(Bx, By), or nil, nil if the scalar is zero or not below N, like the cgo version.
+//
+// The product is computed by a Montgomery ladder in constant time, see scalarMultLadder.
 func (BitCurve *BitCurve) ScalarMult(Bx, By *big.Int, scalar []byte) (*big.Int, *big.Int) {
-	panic("ScalarMult is not available when secp256k1 is built without cgo")
+	return scalarMultLadder(Bx, By, scalar)
 }
@@ -0,0 +1,151 @@
+// Copyright 2021 Stars-labs. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found in
+// the LICENSE file.
+
+package secp256k1
+
+import (
+	"crypto/sha256"
+	"github.com/stars-labs/go-pvss/crypto/scalar"
+	"math/big"
+)
+
+// The BIP-340 Schnorr signatures: the public key is the 32 bytes x of the point P = d·G with an even y,
+// and the signature of the message m is the 64 bytes R.x || s, where R = k·G has an even y,
+// s = k + e·d mod N and e = tagged_hash("BIP0340/challenge", R.x || P.x || m) mod N.
+// The messages have any length.
+//
+// SignSchnorr, VerifySchnorr and XOnlyPubkey are implemented by the schnorrsig module of libsecp256k1 with cgo,
+// and by signSchnorr, verifySchnorr and xOnlyPubkey below without cgo.
+
+// taggedHash returns the BIP-340 tagged hash SHA256(SHA256(tag) || SHA256(tag) || msgs...).
+func taggedHash(tag string, msgs ...[]byte) []byte {
+	t := sha256.Sum256([]byte(tag))
+	h := sha256.New()
+	h.Write(t[:])
+	h.Write(t[:])
+	for _, msg := range msgs {
+		h.Write(msg)
+	}
+	return h.Sum(nil)
+}
+
+// schnorrChallenge returns e = tagged_hash("BIP0340/challenge", r || P || m) mod N.
+func schnorrChallenge(r, pubkey, msg []byte) *big.Int {
+	e := new(big.Int).SetBytes(taggedHash("BIP0340/challenge", r, pubkey, msg))
+	return e.Mod(e, theCurve.N)
+}
+
+// schnorrSecret returns the secret key d in [1, N) and its x-only public key, where d is negated if d·G has an odd y.
+func schnorrSecret(seckey []byte) (*scalar.Scalar, []byte, error) {
+	if len(seckey) != 32 {
+		return nil, nil, ErrInvalidKey
+	}
+	d, ok := secretScalar(seckey)
+	if !ok {
+		return nil, nil, ErrInvalidKey
+	}
+	x, y := theCurve.ScalarBaseMult(seckey)
+	if y.Bit(0) == 1 {
+		d.Neg(d)
+	}
+	pubkey := make([]byte, 32)
+	readBits(x, pubkey)
+	return d, pubkey, nil
+}
+
+// xOnlyPubkey is the pure Go XOnlyPubkey.
+func xOnlyPubkey(seckey []byte) ([]byte, error) {
+	_, pubkey, err := schnorrSecret(seckey)
+	return pubkey, err
+}
+
+// signSchnorr is the pure Go SignSchnorr, as the default signing of BIP-340.
+func signSchnorr(msg, seckey, auxRand []byte) ([]byte, error) {
+	if auxRand == nil {
+		auxRand = make([]byte, 32)
+	}
+	if len(auxRand) != 32 {
+		return nil, ErrInvalidAuxRandLen
+	}
+	d, pubkey, err := schnorrSecret(seckey)
+	if err != nil {
+		return nil, err
+	}
+	defer d.Clear()
+
+	// t = bytes(d) xor tagged_hash("BIP0340/aux", a), k = tagged_hash("BIP0340/nonce", t || P.x || m) mod N
+	n := orderModulus()
+	t := d.Bytes()
+	for i, b := range taggedHash("BIP0340/aux", auxRand) {
+		t[i] ^= b
+	}
+	k := n.FromBytes(taggedHash("BIP0340/nonce", t, pubkey, msg))
+	defer k.Clear()
+	if k.IsZero() {
+		return nil, ErrSignFailed
+	}
+	rx, ry := theCurve.ScalarBaseMult(k.Bytes())
+	if ry.Bit(0) == 1 {
+		k.Neg(k)
+	}
+
+	sig := make([]byte, 64)
+	readBits(rx, sig[:32])
+	// s = k + e·d mod N
+	s := n.FromBig(schnorrChallenge(sig[:32], pubkey, msg))
+	s.Mul(s, d)
+	s.Add(s, k)
+	s.FillBytes(sig[32:])
+	if !verifySchnorr(pubkey, msg, sig) {
+		return nil, ErrSignFailed
+	}
+	return sig, nil
+}
+
+// verifySchnorr is the pure Go VerifySchnorr.
+func verifySchnorr(pubkey, msg, signature []byte) bool {
+	if len(pubkey) != 32 || len(signature) != 64 {
+		return false
+	}
+	px, py := liftX(pubkey)
+	if px == nil {
+		return false
+	}
+	r := new(big.Int).SetBytes(signature[:32])
+	s := new(big.Int).SetBytes(signature[32:])
+	if r.Cmp(theCurve.P) >= 0 || s.Cmp(theCurve.N) >= 0 {
+		return false
+	}
+	// R = s·G - e·P
+	e := schnorrChallenge(signature[:32], pubkey, msg)
+	e.Sub(theCurve.N, e)
+	rx, ry := theCurve.MultiScalarMult([]*big.Int{theCurve.Gx, px}, []*big.Int{theCurve.Gy, py}, [][]byte{signature[32:], e.Bytes()})
+	if rx == nil || (rx.Sign() == 0 && ry.Sign() == 0) {
+		return false
+	}
+	return ry.Bit(0) == 0 && rx.Cmp(r) == 0
+}
+
+// liftX returns the point of the x with an even y, or nil, nil if there is none.
+func liftX(xb []byte) (*big.Int, *big.Int) {
+	x := new(big.Int).SetBytes(xb)
+	if x.Cmp(theCurve.P) >= 0 {
+		return nil, nil
+	}
+	// y = (x³ + 7)^((P+1)/4), as P = 3 mod 4
+	c := new(big.Int).Mul(x, x)
+	c.Mul(c, x)
+	c.Add(c, theCurve.B)
+	c.Mod(c, theCurve.P)
+	exp := new(big.Int).Add(theCurve.P, big.NewInt(1))
+	y := new(big.Int).Exp(c, exp.Rsh(exp, 2), theCurve.P)
+	y2 := new(big.Int).Mul(y, y)
+	if y2.Mod(y2, theCurve.P).Cmp(c) != 0 {
+		return nil, nil
+	}
+	if y.Bit(0) == 1 {
+		y.Sub(theCurve.P, y)
+	}
+	return x, y
+}
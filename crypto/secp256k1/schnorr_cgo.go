@@ -0,0 +1,79 @@
+// Copyright 2021 Stars-labs. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found in
+// the LICENSE file.
+
+// +build !gofuzz
+// +build cgo
+
+package secp256k1
+
+import (
+	"unsafe"
+)
+
+/*
+
+#include "libsecp256k1/include/secp256k1.h"
+
+extern int secp256k1_ext_schnorrsig_sign(const secp256k1_context* ctx, unsigned char *sig64, const unsigned char *msg, size_t msglen, const unsigned char *seckey, const unsigned char *aux_rand32);
+extern int secp256k1_ext_schnorrsig_verify(const secp256k1_context* ctx, const unsigned char *sig64, const unsigned char *msg, size_t msglen, const unsigned char *pubkey32);
+extern int secp256k1_ext_xonly_pubkey(const secp256k1_context* ctx, unsigned char *pubkey32, const unsigned char *seckey);
+
+*/
+import "C"
+
+// SignSchnorr creates a BIP-340 Schnorr signature of the message, which has any length, in the 64-byte [R.x || s] format.
+// auxRand is 32 bytes of fresh randomness mixed into the nonce, as recommended by BIP-340, or nil which is the same as zeros.
+// The signature is verified before it's returned.
+func SignSchnorr(msg, seckey, auxRand []byte) ([]byte, error) {
+	if len(auxRand) != 32 && auxRand != nil {
+		return nil, ErrInvalidAuxRandLen
+	}
+	if len(seckey) != 32 {
+		return nil, ErrInvalidKey
+	}
+	seckeydata := (*C.uchar)(unsafe.Pointer(&seckey[0]))
+	if C.secp256k1_ec_seckey_verify(context, seckeydata) != 1 {
+		return nil, ErrInvalidKey
+	}
+	var auxdata *C.uchar
+	if auxRand != nil {
+		auxdata = (*C.uchar)(unsafe.Pointer(&auxRand[0]))
+	}
+	sig := make([]byte, 64)
+	sigdata := (*C.uchar)(unsafe.Pointer(&sig[0]))
+	if C.secp256k1_ext_schnorrsig_sign(context, sigdata, bytesPtr(msg), C.size_t(len(msg)), seckeydata, auxdata) == 0 {
+		return nil, ErrSignFailed
+	}
+	return sig, nil
+}
+
+// VerifySchnorr checks the BIP-340 Schnorr signature of the message by the 32-byte x-only public key.
+func VerifySchnorr(pubkey, msg, signature []byte) bool {
+	if len(pubkey) != 32 || len(signature) != 64 {
+		return false
+	}
+	sigdata := (*C.uchar)(unsafe.Pointer(&signature[0]))
+	keydata := (*C.uchar)(unsafe.Pointer(&pubkey[0]))
+	return C.secp256k1_ext_schnorrsig_verify(context, sigdata, bytesPtr(msg), C.size_t(len(msg)), keydata) != 0
+}
+
+// XOnlyPubkey returns the 32-byte BIP-340 x-only public key of the secret key.
+func XOnlyPubkey(seckey []byte) ([]byte, error) {
+	if len(seckey) != 32 {
+		return nil, ErrInvalidKey
+	}
+	pubkey := make([]byte, 32)
+	if C.secp256k1_ext_xonly_pubkey(context, (*C.uchar)(unsafe.Pointer(&pubkey[0])), (*C.uchar)(unsafe.Pointer(&seckey[0]))) == 0 {
+		return nil, ErrInvalidKey
+	}
+	return pubkey, nil
+}
+
+// bytesPtr returns the pointer to the first byte of b, or nil if b is empty.
+func bytesPtr(b []byte) *C.uchar {
+	if len(b) == 0 {
+		return nil
+	}
+	return (*C.uchar)(unsafe.Pointer(&b[0]))
+}
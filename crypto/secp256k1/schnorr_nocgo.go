@@ -0,0 +1,24 @@
+// Copyright 2021 Stars-labs. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found in
+// the LICENSE file.
+
+// +build gofuzz !cgo
+
+package secp256k1
+
+// SignSchnorr creates a BIP-340 Schnorr signature of the message, which has any length, in the 64-byte [R.x || s] format.
+// auxRand is 32 bytes of fresh randomness mixed into the nonce, as recommended by BIP-340, or nil which is the same as zeros.
+// The signature is verified before it's returned.
+func SignSchnorr(msg, seckey, auxRand []byte) ([]byte, error) {
+	return signSchnorr(msg, seckey, auxRand)
+}
+
+// VerifySchnorr checks the BIP-340 Schnorr signature of the message by the 32-byte x-only public key.
+func VerifySchnorr(pubkey, msg, signature []byte) bool {
+	return verifySchnorr(pubkey, msg, signature)
+}
+
+// XOnlyPubkey returns the 32-byte BIP-340 x-only public key of the secret key.
+func XOnlyPubkey(seckey []byte) ([]byte, error) {
+	return xOnlyPubkey(seckey)
+}
@@ -0,0 +1,121 @@
+// Copyright 2021 Stars-labs. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found in
+// the LICENSE file.
+
+package secp256k1
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/hex"
+	"os"
+	"testing"
+)
+
+// schnorrImpls are the BIP-340 implementations of the build, and the pure Go ones, which are tested in every build.
+var schnorrImpls = []struct {
+	name   string
+	sign   func(msg, seckey, auxRand []byte) ([]byte, error)
+	verify func(pubkey, msg, signature []byte) bool
+	pubkey func(seckey []byte) ([]byte, error)
+}{
+	{"default", SignSchnorr, VerifySchnorr, XOnlyPubkey},
+	{"go", signSchnorr, verifySchnorr, xOnlyPubkey},
+}
+
+// TestSchnorrVectors checks the official test vectors of BIP-340, from
+// https://github.com/bitcoin/bips/blob/master/bip-0340/test-vectors.csv
+func TestSchnorrVectors(t *testing.T) {
+	f, err := os.Open("testdata/bip-0340-test-vectors.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decode := func(s string) []byte {
+		b, err := hex.DecodeString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	for _, impl := range schnorrImpls {
+		for _, record := range records[1:] {
+			index, seckey, pubkey, auxRand, msg, sig := record[0], decode(record[1]), decode(record[2]), decode(record[3]), decode(record[4]), decode(record[5])
+			valid := record[6] == "TRUE"
+			if len(seckey) != 0 {
+				pub, err := impl.pubkey(seckey)
+				if err != nil || !bytes.Equal(pub, pubkey) {
+					t.Errorf("%s vector %s: public key %x, %v, want %x", impl.name, index, pub, err, pubkey)
+				}
+				got, err := impl.sign(msg, seckey, auxRand)
+				if err != nil || !bytes.Equal(got, sig) {
+					t.Errorf("%s vector %s: signature %x, %v, want %x", impl.name, index, got, err, sig)
+				}
+			}
+			if impl.verify(pubkey, msg, sig) != valid {
+				t.Errorf("%s vector %s: verification is not %v (%s)", impl.name, index, valid, record[7])
+			}
+		}
+	}
+}
+
+func TestSchnorrSignAndVerify(t *testing.T) {
+	for _, impl := range schnorrImpls {
+		for i := 0; i < 20; i++ {
+			_, seckey := generateKeyPair()
+			pubkey, err := impl.pubkey(seckey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			msg := csprngEntropy(i)
+			sig, err := impl.sign(msg, seckey, csprngEntropy(32))
+			if err != nil {
+				t.Fatalf("%s: signature error: %s", impl.name, err)
+			}
+			for _, verify := range []func(pubkey, msg, signature []byte) bool{VerifySchnorr, verifySchnorr} {
+				if !verify(pubkey, msg, sig) {
+					t.Fatalf("%s: signature of %x is not valid", impl.name, msg)
+				}
+				if verify(pubkey, append(msg, 0), sig) {
+					t.Fatalf("%s: signature of another message is valid", impl.name)
+				}
+			}
+			// the default auxiliary randomness is zeros
+			sig1, err := impl.sign(msg, seckey, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sig2, err := impl.sign(msg, seckey, make([]byte, 32))
+			if err != nil || !bytes.Equal(sig1, sig2) {
+				t.Fatalf("%s: signature with nil auxiliary randomness %x, want %x", impl.name, sig1, sig2)
+			}
+		}
+	}
+}
+
+func TestSchnorrInvalidInputs(t *testing.T) {
+	_, seckey := generateKeyPair()
+	zero, order := make([]byte, 32), S256().N.Bytes()
+	for _, impl := range schnorrImpls {
+		for _, key := range [][]byte{nil, seckey[:31], zero, order} {
+			if _, err := impl.sign([]byte("msg"), key, nil); err != ErrInvalidKey {
+				t.Errorf("%s: got %v, want %v", impl.name, err, ErrInvalidKey)
+			}
+			if _, err := impl.pubkey(key); err != ErrInvalidKey {
+				t.Errorf("%s: got %v, want %v", impl.name, err, ErrInvalidKey)
+			}
+		}
+		if _, err := impl.sign([]byte("msg"), seckey, make([]byte, 31)); err != ErrInvalidAuxRandLen {
+			t.Errorf("%s: got %v, want %v", impl.name, err, ErrInvalidAuxRandLen)
+		}
+		pubkey, _ := impl.pubkey(seckey)
+		sig, _ := impl.sign(nil, seckey, nil)
+		if impl.verify(pubkey[:31], nil, sig) || impl.verify(pubkey, nil, sig[:63]) {
+			t.Errorf("%s: a truncated public key or signature is valid", impl.name)
+		}
+	}
+}
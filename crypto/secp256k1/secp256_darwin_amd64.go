@@ -2,7 +2,7 @@
 // Use of this source code is governed by a BSD-style license that can be found in
 // the LICENSE file.
 
-// +build darwin,amd64,!ios
+// +build !gofuzz,darwin,amd64,!ios
 
 // Package secp256k1 wraps the bitcoin secp256k1 C library.
 package secp256k1
@@ -24,7 +24,10 @@ package secp256k1
 #define USE_ECMULT_STATIC_PRECOMPUTATION
 #define NDEBUG
 #include "./libsecp256k1/src/secp256k1.c"
+#include "./libsecp256k1/src/modules/ecdh/main_impl.h"
 #include "./libsecp256k1/src/modules/recovery/main_impl.h"
+#include "./libsecp256k1/src/modules/extrakeys/main_impl.h"
+#include "./libsecp256k1/src/modules/schnorrsig/main_impl.h"
 #include "ext.h"
 
 typedef void (*callbackFunc) (const char* msg, void* data);
@@ -34,7 +37,6 @@ extern void secp256k1GoPanicError(const char* msg, void* data);
 import "C"
 
 import (
-	"errors"
 	"math/big"
 	"unsafe"
 )
@@ -48,16 +50,6 @@ func init() {
 	C.secp256k1_context_set_error_callback(context, C.callbackFunc(C.secp256k1GoPanicError), nil)
 }
 
-var (
-	ErrInvalidMsgLen       = errors.New("invalid message length, need 32 bytes")
-	ErrInvalidSignatureLen = errors.New("invalid signature length")
-	ErrInvalidRecoveryID   = errors.New("invalid signature recovery id")
-	ErrInvalidKey          = errors.New("invalid private key")
-	ErrInvalidPubkey       = errors.New("invalid public key")
-	ErrSignFailed          = errors.New("signing failed")
-	ErrRecoverFailed       = errors.New("recovery failed")
-)
-
 // Sign creates a recoverable ECDSA signature.
 // The produced signature is in the 65-byte [R || S || V] format where V is 0 or 1.
 //
@@ -164,13 +156,3 @@ func CompressPubkey(x, y *big.Int) []byte {
 	}
 	return out
 }
-
-func checkSignature(sig []byte) error {
-	if len(sig) != 65 {
-		return ErrInvalidSignatureLen
-	}
-	if sig[64] >= 4 {
-		return ErrInvalidRecoveryID
-	}
-	return nil
-}
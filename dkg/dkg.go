@@ -0,0 +1,528 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package dkg implements a Pedersen-style distributed key generation on top of the PVSS dealer.
+//
+// Each of the n parties deals a random polynomial p_d of degree t-1 in a PVSS distribution shares box,
+// so the box is publicly verifiable by everyone with pvss.VerifyDistributionShares. Besides the box,
+// the dealer publishes
+//
+//   - its public key contribution p_d(0)·G, with a DLEQ proof that it has the same discrete logarithm
+//     as the commitment C_0 = p_d(0)·H,
+//   - the scalar share p_d(i) of every party i, masked by a key derived from the Diffie-Hellman key
+//     of the dealer and the party. The party checks p_d(i)·H == X_i, the commitment of its share.
+//
+// A party whose share does not match files a complaint revealing the Diffie-Hellman key with a DLEQ proof,
+// so everyone can check the accusation. The qualified dealers are those whose deal is valid and
+// against whom no complaint is justified, the secret share of party i is x_i = ∑ p_d(i) and the
+// joint public key is x·G = ∑ p_d(0)·G over the qualified dealers d.
+//
+// The secret scalars, the polynomial values, the shares and the nonces of the proofs, are computed on the constant-time
+// scalars of the crypto/scalar package, math/big only carries the private keys, the masked shares and Result.SecretShare
+// at the API.
+package dkg
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/stars-labs/go-pvss/crypto/scalar"
+	"github.com/stars-labs/go-pvss/pvss"
+	"math/big"
+	"sort"
+)
+
+var (
+	ErrInvalidConfig    = errors.New("invalid dkg config")
+	ErrInvalidDeal      = errors.New("invalid deal")
+	ErrInvalidComplaint = errors.New("invalid complaint")
+	ErrNotEnoughDealers = errors.New("not enough qualified dealers")
+)
+
+const (
+	publicKeyProtocol = "go-pvss/dkg/public-key"
+	shareKeyProtocol  = "go-pvss/dkg/share-key"
+	complaintProtocol = "go-pvss/dkg/complaint"
+)
+
+// Config is the public setting of a DKG ceremony, which all the parties agree on.
+// The party at index i (1 <= i <= n) owns PublicKeys[i-1].
+//
+// In a resharing, the dealers are the holders of an old sharing rather than the parties, see Resharer: the dealer at index i
+// owns Dealers[i-1], and Commitments are the commitments C_j of the old sharing, whose threshold is len(Commitments).
+type Config struct {
+	Group       pvss.Group
+	ID          []byte // identifies the ceremony
+	Epoch       uint64
+	PublicKeys  []pvss.Element
+	Threshold   int
+	Dealers     []pvss.Element // only in a resharing
+	Commitments []pvss.Element // only in a resharing
+}
+
+func (c *Config) validate() error {
+	if c == nil || c.Group == nil {
+		return fmt.Errorf("%w: missing group", ErrInvalidConfig)
+	}
+	if c.Threshold < 1 || c.Threshold > len(c.PublicKeys) {
+		return fmt.Errorf("%w: threshold %d of %d parties", ErrInvalidConfig, c.Threshold, len(c.PublicKeys))
+	}
+	if c.Dealers == nil && c.Commitments == nil {
+		return nil
+	}
+	if len(c.Commitments) < 1 || len(c.Commitments) > len(c.Dealers) {
+		return fmt.Errorf("%w: old threshold %d of %d dealers", ErrInvalidConfig, len(c.Commitments), len(c.Dealers))
+	}
+	for _, commitment := range c.Commitments {
+		if commitment == nil {
+			return fmt.Errorf("%w: missing commitment", ErrInvalidConfig)
+		}
+	}
+	return nil
+}
+
+// resharing reports whether the ceremony reshares an old sharing.
+func (c *Config) resharing() bool {
+	return c.Commitments != nil
+}
+
+// dealerThreshold returns the number of the qualified dealers needed to complete the ceremony.
+func (c *Config) dealerThreshold() int {
+	if c.resharing() {
+		return len(c.Commitments)
+	}
+	return c.Threshold
+}
+
+// dealerKey returns the public key of the dealer at index, or nil.
+func (c *Config) dealerKey(index int) pvss.Element {
+	if !c.resharing() {
+		return c.publicKey(index)
+	}
+	if index < 1 || index > len(c.Dealers) {
+		return nil
+	}
+	return c.Dealers[index-1]
+}
+
+// publicKey returns the public key of the party at index, or nil.
+func (c *Config) publicKey(index int) pvss.Element {
+	if index < 1 || index > len(c.PublicKeys) {
+		return nil
+	}
+	return c.PublicKeys[index-1]
+}
+
+// Session returns the PVSS session of the box dealt by the dealer at index.
+// The index of the dealer is bound into the session ID, so a box can not be replayed by another dealer.
+func (c *Config) Session(dealer int) *pvss.Session {
+	id := make([]byte, 4, 4+len(c.ID)+4)
+	binary.BigEndian.PutUint32(id, uint32(len(c.ID)))
+	id = append(id, c.ID...)
+	id = append(id, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(id[len(id)-4:], uint32(dealer))
+	return pvss.NewSession(c.Group, id, c.Epoch, c.PublicKeys, c.Threshold)
+}
+
+// JointSession returns the session of the joint sharing of the ceremony, in which its key is used, like by the threshold
+// ElGamal decryption of pvss. It's the session of the dealer index 0, which is no dealer.
+func (c *Config) JointSession() *pvss.Session {
+	return c.Session(0)
+}
+
+// transcript creates the transcript of the protocol between the dealer and the party at index.
+func (c *Config) transcript(protocol string, dealer, party int) *pvss.Transcript {
+	t := pvss.NewTranscript(protocol, c.Session(dealer).ID)
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], c.Epoch)
+	t.AppendMessage("epoch", b[:])
+	binary.BigEndian.PutUint64(b[:], uint64(party))
+	t.AppendMessage("party", b[:])
+	return t
+}
+
+// Deal is what a dealer publishes in the ceremony.
+type Deal struct {
+	Dealer    int // index of the dealer
+	Box       *pvss.DistributionSharesBox
+	PublicKey pvss.Element // p(0)·G
+	Challenge *big.Int     // DLEQ(G, p(0)·G, H, C_0)
+	Response  *big.Int
+	// EncryptedShares[i-1] is p(i) masked for the party at index i.
+	EncryptedShares []*big.Int
+}
+
+// Complaint accuses the dealer of a deal, revealing the Diffie-Hellman key of the dealer and the accuser
+// with a DLEQ(G, PK_accuser, PK_dealer, Key) proof, so everyone can unmask the accuser's share.
+// An invalid box needs no complaint, as everyone can verify it.
+type Complaint struct {
+	Accuser   int
+	Dealer    int
+	Key       pvss.Element
+	Challenge *big.Int
+	Response  *big.Int
+}
+
+// Result is the outcome of the ceremony for a party.
+type Result struct {
+	Index       int
+	SecretShare *big.Int       // x_i
+	PublicKey   pvss.Element   // joint public key x·G
+	Commitments []pvss.Element // joint commitments C_j of the coefficients, so X_i = ∑ C_j·i^j = x_i·H
+	Qualified   []int          // indexes of the qualified dealers
+}
+
+// Party is a participant of the ceremony, it's both a dealer and a receiver.
+type Party struct {
+	config     *Config
+	index      int
+	privateKey *big.Int
+	dealer     *pvss.Dealer
+	shares     map[int]*scalar.Scalar // dealer -> p_d(index)
+}
+
+// NewParty creates the party at index of the ceremony, privateKey is the private key of PublicKeys[index-1].
+func NewParty(config *Config, index int, privateKey *big.Int) (*Party, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	pk := config.publicKey(index)
+	if pk == nil {
+		return nil, fmt.Errorf("%w: no party at index %d", ErrInvalidConfig, index)
+	}
+	dealer := pvss.NewDealer(config.Group, privateKey)
+	if !config.Group.Equal(dealer.PK, pk) {
+		return nil, fmt.Errorf("%w: private key does not match the public key of party %d", ErrInvalidConfig, index)
+	}
+	return &Party{
+		config:     config,
+		index:      index,
+		privateKey: privateKey,
+		dealer:     dealer,
+		shares:     make(map[int]*scalar.Scalar),
+	}, nil
+}
+
+// Index returns the index of the party.
+func (p *Party) Index() int {
+	return p.index
+}
+
+// Deal deals a random polynomial to all the parties.
+func (p *Party) Deal() (*Deal, error) {
+	if p.config.resharing() {
+		return nil, fmt.Errorf("%w: the dealers of a resharing are the old holders", ErrInvalidConfig)
+	}
+	poly, err := pvss.InitPolynomial(p.config.Threshold-1, p.config.Group.Order())
+	if err != nil {
+		return nil, err
+	}
+	return dealPolynomial(p.config, p.index, p.privateKey, p.dealer, poly)
+}
+
+// dealPolynomial deals the polynomial of the dealer at index to all the parties.
+func dealPolynomial(config *Config, index int, privateKey *big.Int, dealer *pvss.Dealer, poly *pvss.Polynomial) (*Deal, error) {
+	g, m := config.Group, pvss.OrderModulus(config.Group)
+	// the box hides nothing but p(0)·G, which is the public key contribution anyway
+	box, err := dealer.DistributePolynomial(new(big.Int), poly, config.PublicKeys, config.Session(index))
+	if err != nil {
+		return nil, err
+	}
+
+	s := poly.Evaluate(m.NewScalar())
+	w, err := m.Random(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	dleq := pvss.NewScalarDLEQ(g, g.Generator(), nil, g.SecondGenerator(), box.Commitments[0], w, s)
+	c, r := dleq.ChallengeAndResponse(config.transcript(publicKeyProtocol, index, 0))
+
+	deal := &Deal{
+		Dealer:          index,
+		Box:             box,
+		PublicKey:       dleq.H1,
+		Challenge:       c,
+		Response:        r,
+		EncryptedShares: make([]*big.Int, len(config.PublicKeys)),
+	}
+	for i, pk := range config.PublicKeys {
+		key := g.ScalarMult(pk, privateKey)
+		mask := m.FromBig(config.shareMask(key, index, i+1))
+		share := poly.Evaluate(m.FromBig(big.NewInt(int64(i + 1))))
+		deal.EncryptedShares[i] = share.Add(share, mask).Big()
+	}
+	return deal, nil
+}
+
+// shareMask derives the mask of the share of the party from the Diffie-Hellman key of the dealer and the party.
+func (c *Config) shareMask(key pvss.Element, dealer, party int) *big.Int {
+	t := c.transcript(shareKeyProtocol, dealer, party)
+	t.AppendElement(c.Group, "key", key)
+	return t.ChallengeScalar(c.Group, "mask")
+}
+
+// VerifyDeal verifies a deal publicly: the box, the public key contribution and the shape of the deal.
+// In a resharing, the constant term of the dealt polynomial must be the share of the dealer: C_0 == X_i of the old commitments.
+func VerifyDeal(config *Config, deal *Deal) error {
+	if err := config.validate(); err != nil {
+		return err
+	}
+	if deal == nil || config.dealerKey(deal.Dealer) == nil {
+		return fmt.Errorf("%w: unknown dealer", ErrInvalidDeal)
+	}
+	// the shares are checked against the commitments of the coefficients, which the SCRAPE mode doesn't carry
+	if deal.Box == nil || deal.PublicKey == nil || deal.Challenge == nil || deal.Response == nil ||
+		len(deal.Box.ShareCommitments) != 0 || len(deal.EncryptedShares) != len(config.PublicKeys) {
+		return fmt.Errorf("%w: malformed deal of dealer %d", ErrInvalidDeal, deal.Dealer)
+	}
+	for _, e := range deal.EncryptedShares {
+		if e == nil || e.Sign() < 0 || e.Cmp(config.Group.Order()) >= 0 {
+			return fmt.Errorf("%w: malformed share of dealer %d", ErrInvalidDeal, deal.Dealer)
+		}
+	}
+	g := config.Group
+	if err := pvss.CheckDistributionShares(g, config.Session(deal.Dealer), deal.Box); err != nil {
+		return fmt.Errorf("%w: box of dealer %d: %v", ErrInvalidDeal, deal.Dealer, err)
+	}
+	ok := pvss.DLEQVerify(g, config.transcript(publicKeyProtocol, deal.Dealer, 0),
+		g.Generator(), deal.PublicKey, g.SecondGenerator(), deal.Box.Commitments[0], deal.Challenge, deal.Response)
+	if !ok {
+		return fmt.Errorf("%w: public key proof of dealer %d", ErrInvalidDeal, deal.Dealer)
+	}
+	if config.resharing() && !g.Equal(deal.Box.Commitments[0], pvss.CommitmentAt(g, config.Commitments, deal.Dealer)) {
+		return fmt.Errorf("%w: dealer %d does not deal its share", ErrInvalidDeal, deal.Dealer)
+	}
+	return nil
+}
+
+// ProcessDeal verifies a deal and unmasks the share of the party.
+// If the deal is invalid, the error wraps ErrInvalidDeal, and everyone else can see it as well.
+// If the share does not match the commitments, a complaint against the dealer is returned, which should be published.
+func (p *Party) ProcessDeal(deal *Deal) (*Complaint, error) {
+	if err := VerifyDeal(p.config, deal); err != nil {
+		return nil, err
+	}
+	g := p.config.Group
+	dealerPK := p.config.dealerKey(deal.Dealer)
+	key := g.ScalarMult(dealerPK, p.privateKey)
+	share := unmask(p.config, deal, p.index, key)
+	if share != nil {
+		p.shares[deal.Dealer] = share
+		return nil, nil
+	}
+
+	m := pvss.OrderModulus(p.config.Group)
+	w, err := m.Random(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	dleq := pvss.NewScalarDLEQ(g, g.Generator(), p.dealer.PK, dealerPK, key, w, m.FromBig(p.privateKey))
+	c, r := dleq.ChallengeAndResponse(p.config.transcript(complaintProtocol, deal.Dealer, p.index))
+	return &Complaint{
+		Accuser:   p.index,
+		Dealer:    deal.Dealer,
+		Key:       key,
+		Challenge: c,
+		Response:  r,
+	}, nil
+}
+
+// unmask returns the share of the party in the deal unmasked by the key, or nil if it does not match the commitments.
+func unmask(config *Config, deal *Deal, party int, key pvss.Element) *scalar.Scalar {
+	g, m := config.Group, pvss.OrderModulus(config.Group)
+	share := m.NewScalar().Sub(m.FromBig(deal.EncryptedShares[party-1]), m.FromBig(config.shareMask(key, deal.Dealer, party)))
+	if !g.Equal(g.ScalarMult(g.SecondGenerator(), share.Big()), pvss.CommitmentAt(g, deal.Box.Commitments, party)) {
+		return nil
+	}
+	return share
+}
+
+// VerifyComplaint checks a complaint against a publicly valid deal, it returns nil if the complaint is justified,
+// so the dealer must be disqualified.
+func VerifyComplaint(config *Config, deal *Deal, complaint *Complaint) error {
+	if complaint == nil || deal == nil || complaint.Dealer != deal.Dealer {
+		return fmt.Errorf("%w: not against the deal", ErrInvalidComplaint)
+	}
+	g := config.Group
+	accuserPK, dealerPK := config.publicKey(complaint.Accuser), config.dealerKey(complaint.Dealer)
+	if accuserPK == nil || dealerPK == nil || complaint.Key == nil || complaint.Challenge == nil || complaint.Response == nil {
+		return fmt.Errorf("%w: malformed complaint", ErrInvalidComplaint)
+	}
+	if err := VerifyDeal(config, deal); err != nil {
+		return err
+	}
+	// DLEQ(G, PK_accuser, PK_dealer, Key)
+	ok := pvss.DLEQVerify(g, config.transcript(complaintProtocol, complaint.Dealer, complaint.Accuser),
+		g.Generator(), accuserPK, dealerPK, complaint.Key, complaint.Challenge, complaint.Response)
+	if !ok {
+		return fmt.Errorf("%w: invalid key proof of party %d", ErrInvalidComplaint, complaint.Accuser)
+	}
+	if unmask(config, deal, complaint.Accuser, complaint.Key) != nil {
+		return fmt.Errorf("%w: share of party %d is valid", ErrInvalidComplaint, complaint.Accuser)
+	}
+	return nil
+}
+
+// QualifiedDealers returns the indexes of the dealers, in increasing order, whose deal is publicly valid and against
+// whom no complaint is justified. A dealer with more than one deal is disqualified.
+func QualifiedDealers(config *Config, deals []*Deal, complaints []*Complaint) []int {
+	valid := make(map[int]*Deal)
+	disqualified := make(map[int]bool)
+	for _, deal := range deals {
+		if deal == nil {
+			continue
+		}
+		if _, ok := valid[deal.Dealer]; ok || VerifyDeal(config, deal) != nil {
+			disqualified[deal.Dealer] = true
+			continue
+		}
+		valid[deal.Dealer] = deal
+	}
+	for _, complaint := range complaints {
+		if complaint == nil {
+			continue
+		}
+		if deal, ok := valid[complaint.Dealer]; ok && VerifyComplaint(config, deal, complaint) == nil {
+			disqualified[complaint.Dealer] = true
+		}
+	}
+	qualified := make([]int, 0, len(valid))
+	for dealer := range valid {
+		if !disqualified[dealer] {
+			qualified = append(qualified, dealer)
+		}
+	}
+	sort.Ints(qualified)
+	return qualified
+}
+
+// Finalize selects the qualified dealers, and derives the secret share of the party and the joint public key.
+// All the deals must have been processed by ProcessDeal. At least threshold dealers must be qualified, in a resharing
+// at least the old threshold.
+func (p *Party) Finalize(deals []*Deal, complaints []*Complaint) (*Result, error) {
+	qualified := QualifiedDealers(p.config, deals, complaints)
+	if t := p.config.dealerThreshold(); len(qualified) < t {
+		return nil, fmt.Errorf("%w: %d of threshold %d", ErrNotEnoughDealers, len(qualified), t)
+	}
+	publicKey, err := JointPublicKey(p.config, deals, qualified)
+	if err != nil {
+		return nil, err
+	}
+	commitments, err := JointCommitments(p.config, deals, qualified)
+	if err != nil {
+		return nil, err
+	}
+	dealers, weights := p.config.weights(qualified)
+	m := pvss.OrderModulus(p.config.Group)
+	secretShare, term := m.NewScalar(), m.NewScalar()
+	for i, dealer := range dealers {
+		share, ok := p.shares[dealer]
+		if !ok {
+			return nil, fmt.Errorf("%w: deal of dealer %d is not processed", ErrInvalidDeal, dealer)
+		}
+		secretShare.Add(secretShare, term.Mul(m.FromBig(weights[i]), share))
+	}
+	return &Result{
+		Index:       p.index,
+		SecretShare: secretShare.Big(),
+		PublicKey:   publicKey,
+		Commitments: commitments,
+		Qualified:   qualified,
+	}, nil
+}
+
+// weights returns the dealers whose polynomials make up the joint polynomial, and their weights: all the qualified dealers
+// with the weight 1 in a DKG, the first old threshold of them with their Lagrange coefficients in a resharing, so the
+// joint polynomial interpolates the old secret ∑ λ_i·x_i.
+func (c *Config) weights(qualified []int) ([]int, []*big.Int) {
+	if !c.resharing() {
+		weights := make([]*big.Int, len(qualified))
+		for i := range weights {
+			weights[i] = big.NewInt(1)
+		}
+		return qualified, weights
+	}
+	dealers := qualified
+	if t := c.dealerThreshold(); len(dealers) > t {
+		dealers = dealers[:t]
+	}
+	weights := make([]*big.Int, len(dealers))
+	for i, dealer := range dealers {
+		weights[i] = pvss.LagrangeCoefficient(dealer, dealers, c.Group.Order())
+	}
+	return dealers, weights
+}
+
+// dealsOf returns the deals of the dealers in order.
+func dealsOf(deals []*Deal, dealers []int) ([]*Deal, error) {
+	byDealer := make(map[int]*Deal, len(deals))
+	for _, deal := range deals {
+		if deal != nil {
+			byDealer[deal.Dealer] = deal
+		}
+	}
+	selected := make([]*Deal, len(dealers))
+	for i, dealer := range dealers {
+		deal, ok := byDealer[dealer]
+		if !ok {
+			return nil, fmt.Errorf("%w: no deal of dealer %d", ErrInvalidDeal, dealer)
+		}
+		selected[i] = deal
+	}
+	return selected, nil
+}
+
+// JointPublicKey returns the joint public key ∑ p_d(0)·G of the qualified dealers, in a resharing ∑ λ_i·x_i·G
+// of the first old threshold of them.
+func JointPublicKey(config *Config, deals []*Deal, qualified []int) (pvss.Element, error) {
+	if t := config.dealerThreshold(); len(qualified) < t {
+		return nil, fmt.Errorf("%w: %d of threshold %d", ErrNotEnoughDealers, len(qualified), t)
+	}
+	dealers, weights := config.weights(qualified)
+	selected, err := dealsOf(deals, dealers)
+	if err != nil {
+		return nil, err
+	}
+	g := config.Group
+	publicKey := g.Identity()
+	for i, deal := range selected {
+		publicKey = g.Add(publicKey, g.ScalarMult(deal.PublicKey, weights[i]))
+	}
+	return publicKey, nil
+}
+
+// JointCommitments returns the commitments C_j of the joint polynomial, like JointPublicKey. In a resharing, it checks publicly
+// that the joint polynomial interpolates the old secret: its C_0 must be the C_0 of the old commitments.
+func JointCommitments(config *Config, deals []*Deal, qualified []int) ([]pvss.Element, error) {
+	if t := config.dealerThreshold(); len(qualified) < t {
+		return nil, fmt.Errorf("%w: %d of threshold %d", ErrNotEnoughDealers, len(qualified), t)
+	}
+	dealers, weights := config.weights(qualified)
+	selected, err := dealsOf(deals, dealers)
+	if err != nil {
+		return nil, err
+	}
+	g := config.Group
+	commitments := make([]pvss.Element, config.Threshold)
+	for j := range commitments {
+		commitments[j] = g.Identity()
+	}
+	for i, deal := range selected {
+		if len(deal.Box.Commitments) != len(commitments) {
+			return nil, fmt.Errorf("%w: %d commitments of dealer %d", ErrInvalidDeal, len(deal.Box.Commitments), deal.Dealer)
+		}
+		for j, c := range deal.Box.Commitments {
+			commitments[j] = g.Add(commitments[j], g.ScalarMult(c, weights[i]))
+		}
+	}
+	if config.resharing() && !g.Equal(commitments[0], config.Commitments[0]) {
+		return nil, fmt.Errorf("%w: the resharing does not preserve the secret", ErrInvalidDeal)
+	}
+	return commitments, nil
+}
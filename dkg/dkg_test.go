@@ -0,0 +1,186 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dkg
+
+import (
+	"crypto/rand"
+	"github.com/stars-labs/go-pvss/pvss"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+func newCeremony(t *testing.T, g pvss.Group, n, threshold int) (*Config, []*Party) {
+	config := &Config{Group: g, ID: []byte("dkg test"), Epoch: 1, Threshold: threshold}
+	privates := make([]*big.Int, n)
+	for i := range privates {
+		private, public, err := pvss.GenerateKey(g, rand.Reader)
+		require.NoError(t, err)
+		privates[i] = private
+		config.PublicKeys = append(config.PublicKeys, public)
+	}
+	parties := make([]*Party, n)
+	for i, private := range privates {
+		party, err := NewParty(config, i+1, private)
+		require.NoError(t, err)
+		parties[i] = party
+	}
+	return config, parties
+}
+
+func deal(t *testing.T, parties []*Party) []*Deal {
+	deals := make([]*Deal, len(parties))
+	for i, party := range parties {
+		d, err := party.Deal()
+		require.NoError(t, err)
+		deals[i] = d
+	}
+	return deals
+}
+
+// checkResults checks that all the parties agree on the joint public key, and any threshold of the shares interpolates its secret.
+func checkResults(t *testing.T, g pvss.Group, threshold int, results []*Result) {
+	for _, result := range results {
+		require.True(t, g.Equal(results[0].PublicKey, result.PublicKey))
+		require.Equal(t, results[0].Qualified, result.Qualified)
+	}
+	for start := 0; start+threshold <= len(results); start++ {
+		subset := results[start : start+threshold]
+		secret := new(big.Int)
+		for _, ri := range subset {
+			// λ_i = ∏(j≠i) j/(j−i)
+			num, den := big.NewInt(1), big.NewInt(1)
+			for _, rj := range subset {
+				if rj.Index != ri.Index {
+					num.Mul(num, big.NewInt(int64(rj.Index)))
+					den.Mul(den, big.NewInt(int64(rj.Index-ri.Index)))
+				}
+			}
+			lambda := num.Mul(num, den.ModInverse(den.Mod(den, g.Order()), g.Order()))
+			secret.Add(secret, lambda.Mul(lambda, ri.SecretShare))
+		}
+		require.True(t, g.Equal(g.ScalarBaseMult(secret), results[0].PublicKey))
+	}
+}
+
+func TestDKG(t *testing.T) {
+	for _, g := range []pvss.Group{pvss.Secp256k1(), pvss.Ristretto255()} {
+		t.Run(g.Name(), func(t *testing.T) {
+			config, parties := newCeremony(t, g, 5, 3)
+			deals := deal(t, parties)
+			for _, party := range parties {
+				for _, d := range deals {
+					complaint, err := party.ProcessDeal(d)
+					require.NoError(t, err)
+					require.Nil(t, complaint)
+				}
+			}
+			require.Equal(t, []int{1, 2, 3, 4, 5}, QualifiedDealers(config, deals, nil))
+
+			results := make([]*Result, len(parties))
+			for i, party := range parties {
+				result, err := party.Finalize(deals, nil)
+				require.NoError(t, err)
+				results[i] = result
+			}
+			checkResults(t, g, 3, results)
+		})
+	}
+}
+
+func TestDKGComplaints(t *testing.T) {
+	g := pvss.Secp256k1()
+	config, parties := newCeremony(t, g, 5, 3)
+	deals := deal(t, parties)
+
+	// dealer 1 sends a wrong share to party 2, the deal is still publicly valid
+	deals[0].EncryptedShares[1].Add(deals[0].EncryptedShares[1], big.NewInt(1))
+	require.NoError(t, VerifyDeal(config, deals[0]))
+	// dealer 3 deals an invalid box
+	deals[2].Box.Shares[0].S = g.Add(deals[2].Box.Shares[0].S, g.Generator())
+	// dealer 4 replays the deal of dealer 5
+	replayed := *deals[4]
+	replayed.Dealer = 4
+	deals[3] = &replayed
+
+	var complaints []*Complaint
+	for _, party := range parties {
+		for i, d := range deals {
+			complaint, err := party.ProcessDeal(d)
+			if i == 2 || i == 3 {
+				require.ErrorIs(t, err, ErrInvalidDeal)
+				continue
+			}
+			require.NoError(t, err)
+			if party.Index() == 2 && d.Dealer == 1 {
+				require.NotNil(t, complaint)
+				require.NoError(t, VerifyComplaint(config, d, complaint))
+				complaints = append(complaints, complaint)
+			} else {
+				require.Nil(t, complaint)
+			}
+		}
+	}
+
+	// a complaint against an honest dealer is rejected
+	forged := *complaints[0]
+	forged.Dealer = 2
+	require.ErrorIs(t, VerifyComplaint(config, deals[1], &forged), ErrInvalidComplaint)
+	honestKey := g.ScalarMult(config.PublicKeys[1], big.NewInt(1))
+	forged.Key = honestKey
+	require.ErrorIs(t, VerifyComplaint(config, deals[1], &forged), ErrInvalidComplaint)
+
+	require.Equal(t, []int{2, 5}, QualifiedDealers(config, deals, append(complaints, &forged)))
+	_, err := parties[0].Finalize(deals, complaints)
+	require.ErrorIs(t, err, ErrNotEnoughDealers)
+
+	// with a lower threshold the ceremony completes without the disqualified dealer
+	config2, parties2 := newCeremony(t, g, 5, 2)
+	deals2 := deal(t, parties2)
+	deals2[0].EncryptedShares[1].Add(deals2[0].EncryptedShares[1], big.NewInt(1))
+	complaints = nil
+	for _, party := range parties2 {
+		for _, d := range deals2 {
+			complaint, err := party.ProcessDeal(d)
+			require.NoError(t, err)
+			if complaint != nil {
+				complaints = append(complaints, complaint)
+			}
+		}
+	}
+	require.Len(t, complaints, 1)
+	results := make([]*Result, len(parties2))
+	for i, party := range parties2 {
+		result, err := party.Finalize(deals2, complaints)
+		require.NoError(t, err)
+		results[i] = result
+	}
+	require.Equal(t, []int{2, 3, 4, 5}, results[0].Qualified)
+	checkResults(t, g, config2.Threshold, results)
+}
+
+func TestDKGElGamal(t *testing.T) {
+	g := pvss.Secp256k1()
+	config, parties := newCeremony(t, g, 5, 3)
+	results := runDKG(t, parties, deal(t, parties))
+	commitments, session := results[0].Commitments, config.JointSession()
+
+	// the committee decrypts a message to the joint key with any threshold of the secret shares
+	ciphertext, err := pvss.EncryptElGamal(g, session, commitments[0], []byte("to the committee"), nil, pvss.ChaCha20Poly1305)
+	require.NoError(t, err)
+	decShares := make([]*pvss.DecryptionShare, 0, 3)
+	for _, result := range results[2:] {
+		decShare, err := pvss.PartialDecrypt(g, session, ciphertext, result.Index, result.SecretShare)
+		require.NoError(t, err)
+		decShares = append(decShares, decShare)
+	}
+	plaintext, err := pvss.DecryptElGamal(g, session, commitments, ciphertext, decShares, nil)
+	require.NoError(t, err)
+	require.Equal(t, []byte("to the committee"), plaintext)
+}
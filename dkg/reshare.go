@@ -0,0 +1,74 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dkg
+
+import (
+	"fmt"
+	"github.com/stars-labs/go-pvss/pvss"
+	"math/big"
+)
+
+// The resharing of a secret to a new committee and threshold, without reconstructing it, see:
+// Yvo Desmedt and Sushil Jajodia. Redistributing Secret Shares to New Access Structures and Its Applications. 1997.
+//
+// The old holder i deals a random polynomial q_i of the new degree t'-1 with q_i(0) = x_i, its share of the old sharing,
+// in a deal like the DKG one, so its commitment C'_0 = x_i·H must be the commitment X_i = ∑ C_j·i^j of the old sharing.
+// The new party k gets x'_k = ∑ λ_i·q_i(k) from the first t qualified old holders, so the new joint polynomial
+// ∑ λ_i·q_i interpolates ∑ λ_i·x_i = x, which everyone checks by ∑ λ_i·C'_0,i == C_0.
+//
+// The new parties run NewParty, ProcessDeal and Finalize on the Config of the resharing, which sets Dealers and Commitments.
+
+// Resharer is an old holder which deals its share to the new committee of a resharing.
+type Resharer struct {
+	config     *Config
+	index      int
+	privateKey *big.Int
+	share      *big.Int
+	dealer     *pvss.Dealer
+}
+
+// NewResharer creates the old holder at index of the resharing, privateKey is the private key of Dealers[index-1],
+// and share is its share x_i of the old sharing, see Result.SecretShare.
+func NewResharer(config *Config, index int, privateKey, share *big.Int) (*Resharer, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	if !config.resharing() {
+		return nil, fmt.Errorf("%w: not a resharing", ErrInvalidConfig)
+	}
+	pk := config.dealerKey(index)
+	if pk == nil {
+		return nil, fmt.Errorf("%w: no dealer at index %d", ErrInvalidConfig, index)
+	}
+	g := config.Group
+	dealer := pvss.NewDealer(g, privateKey)
+	if !g.Equal(dealer.PK, pk) {
+		return nil, fmt.Errorf("%w: private key does not match the public key of dealer %d", ErrInvalidConfig, index)
+	}
+	if share == nil || share.Sign() < 0 || share.Cmp(g.Order()) >= 0 ||
+		!g.Equal(g.ScalarMult(g.SecondGenerator(), share), pvss.CommitmentAt(g, config.Commitments, index)) {
+		return nil, fmt.Errorf("%w: share does not match the commitments of dealer %d", ErrInvalidConfig, index)
+	}
+	return &Resharer{
+		config:     config,
+		index:      index,
+		privateKey: privateKey,
+		share:      share,
+		dealer:     dealer,
+	}, nil
+}
+
+// Deal deals the share of the old holder on a random polynomial of the new threshold to the new parties.
+func (r *Resharer) Deal() (*Deal, error) {
+	poly, err := pvss.InitSecretPolynomial(r.config.Threshold-1, r.share, r.config.Group.Order())
+	if err != nil {
+		return nil, err
+	}
+	return dealPolynomial(r.config, r.index, r.privateKey, r.dealer, poly)
+}
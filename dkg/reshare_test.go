@@ -0,0 +1,132 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dkg
+
+import (
+	"github.com/stars-labs/go-pvss/pvss"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+// runDKG runs an honest ceremony, and returns the results of all the parties.
+func runDKG(t *testing.T, parties []*Party, deals []*Deal) []*Result {
+	for _, party := range parties {
+		for _, d := range deals {
+			complaint, err := party.ProcessDeal(d)
+			require.NoError(t, err)
+			require.Nil(t, complaint)
+		}
+	}
+	results := make([]*Result, len(parties))
+	for i, party := range parties {
+		result, err := party.Finalize(deals, nil)
+		require.NoError(t, err)
+		results[i] = result
+	}
+	return results
+}
+
+// newResharing creates the config of resharing the results of the old ceremony to a new committee.
+func newResharing(t *testing.T, old *Config, results []*Result, n, threshold int) (*Config, []*Party) {
+	config, parties := newCeremony(t, old.Group, n, threshold)
+	config.ID, config.Epoch = old.ID, old.Epoch+1
+	config.Dealers, config.Commitments = old.PublicKeys, results[0].Commitments
+	return config, parties
+}
+
+func TestReshare(t *testing.T) {
+	for _, g := range []pvss.Group{pvss.Secp256k1(), pvss.Ristretto255()} {
+		t.Run(g.Name(), func(t *testing.T) {
+			oldConfig, oldParties := newCeremony(t, g, 5, 3)
+			oldResults := runDKG(t, oldParties, deal(t, oldParties))
+			for _, result := range oldResults {
+				require.True(t, g.Equal(g.ScalarMult(g.SecondGenerator(), result.SecretShare), pvss.CommitmentAt(g, result.Commitments, result.Index)))
+			}
+
+			// the old holders 2, 4 and 5 reshare to 7 new parties with the threshold 4
+			config, parties := newResharing(t, oldConfig, oldResults, 7, 4)
+			var deals []*Deal
+			for _, i := range []int{2, 4, 5} {
+				resharer, err := NewResharer(config, i, oldParties[i-1].privateKey, oldResults[i-1].SecretShare)
+				require.NoError(t, err)
+				d, err := resharer.Deal()
+				require.NoError(t, err)
+				require.NoError(t, VerifyDeal(config, d))
+				deals = append(deals, d)
+			}
+			_, err := parties[0].Deal()
+			require.ErrorIs(t, err, ErrInvalidConfig)
+
+			results := runDKG(t, parties, deals)
+			require.Equal(t, []int{2, 4, 5}, results[0].Qualified)
+			checkResults(t, g, 4, results)
+			require.True(t, g.Equal(oldResults[0].PublicKey, results[0].PublicKey))
+			require.True(t, g.Equal(oldResults[0].Commitments[0], results[0].Commitments[0]))
+			require.Len(t, results[0].Commitments, 4)
+		})
+	}
+}
+
+func TestReshareRejects(t *testing.T) {
+	g := pvss.Secp256k1()
+	oldConfig, oldParties := newCeremony(t, g, 5, 3)
+	oldResults := runDKG(t, oldParties, deal(t, oldParties))
+	config, parties := newResharing(t, oldConfig, oldResults, 4, 2)
+
+	// a wrong share or key of the old holder
+	wrong := new(big.Int).Add(oldResults[0].SecretShare, big.NewInt(1))
+	_, err := NewResharer(config, 1, oldParties[0].privateKey, wrong)
+	require.ErrorIs(t, err, ErrInvalidConfig)
+	_, err = NewResharer(config, 2, oldParties[0].privateKey, oldResults[0].SecretShare)
+	require.ErrorIs(t, err, ErrInvalidConfig)
+
+	deals := make([]*Deal, 0, 5)
+	for i := 1; i <= 5; i++ {
+		resharer, err := NewResharer(config, i, oldParties[i-1].privateKey, oldResults[i-1].SecretShare)
+		require.NoError(t, err)
+		d, err := resharer.Deal()
+		require.NoError(t, err)
+		deals = append(deals, d)
+	}
+
+	// the old holder 1 deals another value than its share, with a valid box
+	cheater := &Resharer{config: config, index: 1, privateKey: oldParties[0].privateKey, share: wrong, dealer: pvss.NewDealer(g, oldParties[0].privateKey)}
+	cheat, err := cheater.Deal()
+	require.NoError(t, err)
+	require.NoError(t, pvss.CheckDistributionShares(g, config.Session(1), cheat.Box))
+	require.ErrorIs(t, VerifyDeal(config, cheat), ErrInvalidDeal)
+	deals[0] = cheat
+
+	for _, party := range parties {
+		for i, d := range deals {
+			complaint, err := party.ProcessDeal(d)
+			if i == 0 {
+				require.ErrorIs(t, err, ErrInvalidDeal)
+				continue
+			}
+			require.NoError(t, err)
+			require.Nil(t, complaint)
+		}
+	}
+	require.Equal(t, []int{2, 3, 4, 5}, QualifiedDealers(config, deals, nil))
+	results := make([]*Result, len(parties))
+	for i, party := range parties {
+		result, err := party.Finalize(deals, nil)
+		require.NoError(t, err)
+		require.Equal(t, []int{2, 3, 4, 5}, result.Qualified)
+		results[i] = result
+	}
+	checkResults(t, g, 2, results)
+	require.True(t, g.Equal(oldResults[0].PublicKey, results[0].PublicKey))
+
+	// fewer than the old threshold of honest old holders
+	_, err = parties[0].Finalize(deals[:3], nil)
+	require.ErrorIs(t, err, ErrNotEnoughDealers)
+}
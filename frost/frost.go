@@ -0,0 +1,472 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package frost implements the two-round FROST threshold Schnorr signing over secp256k1, in the style of RFC 9591,
+// whose signatures are BIP-340 signatures of the x-only group public key.
+//
+// The signers hold the scalar shares x_i of a key x, like the secret shares of a dkg ceremony, see NewKeyShare, or the
+// shares of a key dealt by a PVSS dealer, see NewKeyShareFromBox. The commitments C_j = a_j·H of the sharing bind the
+// shares, and the verification shares of the signers, which are checked against them.
+//
+// The shares decrypted from a plain PVSS box, by Dealer.ExtractSecretShare, are the points p(i)·G, not the scalars p(i),
+// so a dealer deals a signing key with Dealer.DistributeKey, whose pvss.KeyBox delivers the scalars p(i) as well, and
+// the signers extract them with Dealer.ExtractKeyShare.
+//
+//   - Round one: every signer picks the hiding and binding nonces d_i, e_i, and publishes the commitments D_i = d_i·G, E_i = e_i·G.
+//   - Round two: with the commitments of all the signers, every signer derives the binding factors ρ_i, the group commitment
+//     R = ∑ D_i + ρ_i·E_i and the BIP-340 challenge c = H(R.x || P.x || m), and publishes z_i = d_i + ρ_i·e_i + λ_i·x_i·c.
+//   - The aggregator sums z = ∑ z_i, and the signature is (R.x, z).
+//
+// BIP-340 keys and nonces have an even y: if the group public key P = x·G has an odd y, the signers use -x_i, and if
+// R has an odd y, they use -d_i, -e_i.
+//
+// A signer must never use the nonces of round one for two signatures, SigningNonces are erased by Sign.
+//
+// The nonces and the secret share are computed on the constant-time scalars of the crypto/scalar package, math/big only
+// carries KeyShare.SecretShare and the public values, like the signature shares, at the API.
+package frost
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/stars-labs/go-pvss/crypto/scalar"
+	"github.com/stars-labs/go-pvss/crypto/secp256k1"
+	"github.com/stars-labs/go-pvss/dkg"
+	"github.com/stars-labs/go-pvss/pvss"
+	"math/big"
+	"sort"
+)
+
+var (
+	ErrInvalidKeyShare          = errors.New("invalid key share")
+	ErrInvalidCommitments       = errors.New("invalid signing commitments")
+	ErrNonceReused              = errors.New("signing nonces already used")
+	ErrInvalidSignatureShare    = errors.New("invalid signature share")
+	ErrInvalidVerificationShare = errors.New("invalid verification share")
+	ErrInvalidSignature         = errors.New("invalid signature")
+)
+
+// contextString prefixes all the hashes of the ciphersuite, in the way of RFC 9591.
+const contextString = "FROST-secp256k1-BIP340-GO-PVSS-v1"
+
+const verificationShareProtocol = "go-pvss/frost/verification-share"
+
+// KeyShare is the share of a signer of a threshold key on secp256k1.
+type KeyShare struct {
+	Group       pvss.Group     // a secp256k1 group, whose second generator H is the one of the commitments
+	Index       int            // i
+	SecretShare *big.Int       // x_i
+	PublicKey   pvss.Element   // the group public key P = x·G
+	Commitments []pvss.Element // the commitments C_j of the sharing, X_i = ∑ C_j·i^j = x_i·H, the threshold is len(Commitments)
+}
+
+// NewKeyShare returns the key share of a dkg result on the secp256k1 group g, after checking the share against the commitments.
+func NewKeyShare(g pvss.Group, result *dkg.Result) (*KeyShare, error) {
+	if !isSecp256k1(g) {
+		return nil, fmt.Errorf("%w: group %s is not secp256k1", ErrInvalidKeyShare, g.Name())
+	}
+	if result == nil || result.Index < 1 || result.SecretShare == nil || result.PublicKey == nil || len(result.Commitments) == 0 {
+		return nil, fmt.Errorf("%w: malformed result", ErrInvalidKeyShare)
+	}
+	return newKeyShare(g, result.Index, result.SecretShare, result.PublicKey, result.Commitments)
+}
+
+// NewKeyShareFromBox returns the key share of the signer from a key box dealt on the secp256k1 group g in the expected
+// session, see pvss.Dealer.DistributeKey. The box is verified by pvss.CheckKeyBox, and the share is extracted by the signer
+// with its PVSS key, its index is its position in the box.
+func NewKeyShareFromBox(g pvss.Group, expected *pvss.Session, kb *pvss.KeyBox, signer *pvss.Dealer) (*KeyShare, error) {
+	if !isSecp256k1(g) {
+		return nil, fmt.Errorf("%w: group %s is not secp256k1", ErrInvalidKeyShare, g.Name())
+	}
+	if err := pvss.CheckKeyBox(g, expected, kb); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKeyShare, err)
+	}
+	index, share, err := signer.ExtractKeyShare(kb)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKeyShare, err)
+	}
+	return newKeyShare(g, index, share, kb.PublicKey, kb.Box.Commitments)
+}
+
+// newKeyShare returns the key share after checking the share against the commitments.
+func newKeyShare(g pvss.Group, index int, share *big.Int, publicKey pvss.Element, commitments []pvss.Element) (*KeyShare, error) {
+	if !g.Equal(g.ScalarMult(g.SecondGenerator(), share), pvss.CommitmentAt(g, commitments, index)) {
+		return nil, fmt.Errorf("%w: share of signer %d does not match the commitments", ErrInvalidKeyShare, index)
+	}
+	return &KeyShare{
+		Group:       g,
+		Index:       index,
+		SecretShare: share,
+		PublicKey:   publicKey,
+		Commitments: commitments,
+	}, nil
+}
+
+// Threshold returns the number of the signers needed to sign.
+func (k *KeyShare) Threshold() int {
+	return len(k.Commitments)
+}
+
+// VerificationShare is the public share Y_i = x_i·G of a signer, by which its signature shares are verified,
+// with the DLEQ(G,Y_i,H,X_i) proof against the commitment X_i of its share.
+type VerificationShare struct {
+	Index     int
+	Y         pvss.Element
+	Challenge *big.Int
+	Response  *big.Int
+}
+
+// VerificationShare returns the verification share of the signer, which it publishes once.
+func (k *KeyShare) VerificationShare() (*VerificationShare, error) {
+	g, m := k.Group, pvss.OrderModulus(k.Group)
+	w, err := m.Random(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	dleq := pvss.NewScalarDLEQ(g, g.Generator(), nil, g.SecondGenerator(), nil, w, m.FromBig(k.SecretShare))
+	c, r := dleq.ChallengeAndResponse(verificationShareTranscript(k.Index))
+	return &VerificationShare{Index: k.Index, Y: dleq.H1, Challenge: c, Response: r}, nil
+}
+
+// Verify verifies the verification share against the commitments of the sharing on the group g.
+func (v *VerificationShare) Verify(g pvss.Group, commitments []pvss.Element) error {
+	if v == nil || v.Index < 1 || v.Y == nil || v.Challenge == nil || v.Response == nil || len(commitments) == 0 {
+		return fmt.Errorf("%w: malformed share", ErrInvalidVerificationShare)
+	}
+	Xi := pvss.CommitmentAt(g, commitments, v.Index)
+	if !pvss.DLEQVerify(g, verificationShareTranscript(v.Index), g.Generator(), v.Y, g.SecondGenerator(), Xi, v.Challenge, v.Response) {
+		return fmt.Errorf("%w: proof of signer %d", ErrInvalidVerificationShare, v.Index)
+	}
+	return nil
+}
+
+func verificationShareTranscript(index int) *pvss.Transcript {
+	t := pvss.NewTranscript(verificationShareProtocol, nil)
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(index))
+	t.AppendMessage("index", b[:])
+	return t
+}
+
+// SigningCommitment is what a signer publishes in round one.
+type SigningCommitment struct {
+	Index   int
+	Hiding  pvss.Element // D_i = d_i·G
+	Binding pvss.Element // E_i = e_i·G
+}
+
+// SigningNonces are the secret nonces of a signer for a single signature.
+type SigningNonces struct {
+	index      int
+	hiding     *scalar.Scalar // d_i
+	binding    *scalar.Scalar // e_i
+	commitment *SigningCommitment
+}
+
+// Commit runs round one: it generates the nonces of the signer, and returns them with their commitment, which is published.
+func (k *KeyShare) Commit() (*SigningNonces, *SigningCommitment, error) {
+	g, m := k.Group, pvss.OrderModulus(k.Group)
+	secret := m.FromBig(k.SecretShare)
+	defer secret.Clear()
+	hiding, err := generateNonce(g, secret)
+	if err != nil {
+		return nil, nil, err
+	}
+	binding, err := generateNonce(g, secret)
+	if err != nil {
+		return nil, nil, err
+	}
+	commitment := &SigningCommitment{
+		Index:   k.Index,
+		Hiding:  g.ScalarBaseMult(hiding.Big()),
+		Binding: g.ScalarBaseMult(binding.Big()),
+	}
+	return &SigningNonces{index: k.Index, hiding: hiding, binding: binding, commitment: commitment}, commitment, nil
+}
+
+// generateNonce returns H3(random_bytes(32) || x_i), so a weak random source alone doesn't reveal the nonce.
+func generateNonce(g pvss.Group, secret *scalar.Scalar) (*scalar.Scalar, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return nil, err
+	}
+	return pvss.OrderModulus(g).FromBytes(wideHash("nonce", random, secret.Bytes())), nil
+}
+
+// SignatureShare is what a signer publishes in round two.
+type SignatureShare struct {
+	Index int
+	Z     *big.Int // z_i
+}
+
+// Sign runs round two: it signs the message with the nonces of round one, given the commitments of all the signers,
+// at least threshold of them including the signer. The nonces are erased, they can't sign again.
+func (k *KeyShare) Sign(nonces *SigningNonces, message []byte, commitments []*SigningCommitment) (*SignatureShare, error) {
+	if nonces == nil || nonces.hiding == nil {
+		return nil, ErrNonceReused
+	}
+	if nonces.index != k.Index {
+		return nil, fmt.Errorf("%w: nonces of signer %d", ErrInvalidKeyShare, nonces.index)
+	}
+	pkg, err := newSigningPackage(k.Group, k.PublicKey, k.Threshold(), message, commitments)
+	if err != nil {
+		return nil, err
+	}
+	own, ok := pkg.commitments[k.Index]
+	if !ok || !k.Group.Equal(own.Hiding, nonces.commitment.Hiding) || !k.Group.Equal(own.Binding, nonces.commitment.Binding) {
+		return nil, fmt.Errorf("%w: missing the commitment of signer %d", ErrInvalidCommitments, k.Index)
+	}
+
+	// z_i = ±(d_i + ρ_i·e_i) + λ_i·(±x_i)·c, the signs depend on the public R and P only
+	m := pvss.OrderModulus(k.Group)
+	z := m.NewScalar().Mul(m.FromBig(pkg.rhos[k.Index]), nonces.binding)
+	z.Add(z, nonces.hiding)
+	if pkg.negateNonces {
+		z.Neg(z)
+	}
+	secret := m.FromBig(k.SecretShare)
+	secret.Mul(secret, m.FromBig(pkg.lambda(k.Index)))
+	if pkg.negateKey {
+		secret.Neg(secret)
+	}
+	z.Add(z, secret.Mul(secret, m.FromBig(pkg.challenge)))
+	secret.Clear()
+
+	nonces.hiding.Clear()
+	nonces.binding.Clear()
+	nonces.hiding, nonces.binding = nil, nil
+	return &SignatureShare{Index: k.Index, Z: z.Big()}, nil
+}
+
+// VerifySignatureShare verifies a signature share of the message against the verification share of its signer, which is
+// verified against the commitments C_j of the sharing first, the threshold is len(sharing).
+func VerifySignatureShare(g pvss.Group, publicKey pvss.Element, sharing []pvss.Element, message []byte, commitments []*SigningCommitment,
+	verificationShare *VerificationShare, share *SignatureShare) error {
+	pkg, err := newSigningPackage(g, publicKey, len(sharing), message, commitments)
+	if err != nil {
+		return err
+	}
+	return pkg.verifyShare(sharing, verificationShare, share)
+}
+
+// Aggregate sums the signature shares of all the signers of the commitments into the BIP-340 signature of the message,
+// 64 bytes. sharing are the commitments C_j of the sharing of the key, the threshold is len(sharing). If verificationShares
+// are given, they are verified against sharing, then every share is verified, and a faulty one is reported by its signer.
+func Aggregate(g pvss.Group, publicKey pvss.Element, sharing []pvss.Element, message []byte, commitments []*SigningCommitment,
+	shares []*SignatureShare, verificationShares []*VerificationShare) ([]byte, error) {
+	pkg, err := newSigningPackage(g, publicKey, len(sharing), message, commitments)
+	if err != nil {
+		return nil, err
+	}
+	byIndex := make(map[int]*SignatureShare, len(shares))
+	for _, share := range shares {
+		if share == nil || share.Z == nil || pkg.commitments[share.Index] == nil || byIndex[share.Index] != nil {
+			return nil, fmt.Errorf("%w: malformed or unexpected share", ErrInvalidSignatureShare)
+		}
+		byIndex[share.Index] = share
+	}
+	if len(byIndex) != len(pkg.indexes) {
+		return nil, fmt.Errorf("%w: %d shares of %d signers", ErrInvalidSignatureShare, len(byIndex), len(pkg.indexes))
+	}
+	if verificationShares != nil {
+		byIndex := make(map[int]*VerificationShare, len(verificationShares))
+		for _, vs := range verificationShares {
+			if vs != nil {
+				byIndex[vs.Index] = vs
+			}
+		}
+		for _, share := range shares {
+			if err := pkg.verifyShare(sharing, byIndex[share.Index], share); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	z := new(big.Int)
+	for _, share := range shares {
+		z.Add(z, share.Z)
+	}
+	z.Mod(z, g.Order())
+	signature := append(xOnly(g, pkg.R), serializeScalar(z)...)
+	if !Verify(XOnlyPublicKey(g, publicKey), message, signature) {
+		return nil, ErrInvalidSignature
+	}
+	return signature, nil
+}
+
+// signingPackage is what every signer and the aggregator derive from the commitments of round one.
+type signingPackage struct {
+	group        pvss.Group
+	commitments  map[int]*SigningCommitment
+	indexes      []int
+	rhos         map[int]*big.Int // binding factors ρ_i
+	R            pvss.Element     // group commitment, with an even y
+	negateNonces bool
+	negateKey    bool
+	challenge    *big.Int
+}
+
+func newSigningPackage(g pvss.Group, publicKey pvss.Element, threshold int, message []byte, commitments []*SigningCommitment) (*signingPackage, error) {
+	if publicKey == nil || g.Equal(publicKey, g.Identity()) {
+		return nil, fmt.Errorf("%w: invalid public key", ErrInvalidKeyShare)
+	}
+	if len(commitments) < threshold || threshold < 1 {
+		return nil, fmt.Errorf("%w: %d signers of threshold %d", ErrInvalidCommitments, len(commitments), threshold)
+	}
+	pkg := &signingPackage{
+		group:       g,
+		commitments: make(map[int]*SigningCommitment, len(commitments)),
+		rhos:        make(map[int]*big.Int, len(commitments)),
+	}
+	for _, c := range commitments {
+		if c == nil || c.Index < 1 || c.Hiding == nil || c.Binding == nil ||
+			g.Equal(c.Hiding, g.Identity()) || g.Equal(c.Binding, g.Identity()) {
+			return nil, fmt.Errorf("%w: malformed commitment", ErrInvalidCommitments)
+		}
+		if pkg.commitments[c.Index] != nil {
+			return nil, fmt.Errorf("%w: duplicate signer %d", ErrInvalidCommitments, c.Index)
+		}
+		pkg.commitments[c.Index] = c
+		pkg.indexes = append(pkg.indexes, c.Index)
+	}
+	sort.Ints(pkg.indexes)
+
+	// ρ_i = H1(P || H4(m) || H5(commitment list) || i), the commitment list is sorted by the index
+	var list bytes.Buffer
+	for _, i := range pkg.indexes {
+		list.Write(serializeScalar(big.NewInt(int64(i))))
+		list.Write(g.Encode(pkg.commitments[i].Hiding))
+		list.Write(g.Encode(pkg.commitments[i].Binding))
+	}
+	prefix := append(g.Encode(publicKey), hash("msg", message)...)
+	prefix = append(prefix, hash("com", list.Bytes())...)
+	R := g.Identity()
+	for _, i := range pkg.indexes {
+		rho := hashToScalar(g, "rho", prefix, serializeScalar(big.NewInt(int64(i))))
+		pkg.rhos[i] = rho
+		R = g.Add(R, g.Add(pkg.commitments[i].Hiding, g.ScalarMult(pkg.commitments[i].Binding, rho)))
+	}
+	if g.Equal(R, g.Identity()) {
+		return nil, fmt.Errorf("%w: the group commitment is the identity", ErrInvalidCommitments)
+	}
+	if hasOddY(g, R) {
+		pkg.negateNonces = true
+		R = g.Neg(R)
+	}
+	pkg.R = R
+	pkg.negateKey = hasOddY(g, publicKey)
+	pkg.challenge = challenge(g, xOnly(g, R), XOnlyPublicKey(g, publicKey), message)
+	return pkg, nil
+}
+
+// lambda returns the Lagrange coefficient of the signer among the signers.
+func (pkg *signingPackage) lambda(index int) *big.Int {
+	return pvss.LagrangeCoefficient(index, pkg.indexes, pkg.group.Order())
+}
+
+// verifyShare checks the verification share against the commitments of the sharing, then z_i·G == ±(D_i + ρ_i·E_i) + λ_i·c·(±Y_i).
+func (pkg *signingPackage) verifyShare(sharing []pvss.Element, vs *VerificationShare, share *SignatureShare) error {
+	g := pkg.group
+	if share == nil || share.Z == nil || pkg.commitments[share.Index] == nil {
+		return fmt.Errorf("%w: malformed or unexpected share", ErrInvalidSignatureShare)
+	}
+	if vs == nil || vs.Index != share.Index || vs.Y == nil {
+		return fmt.Errorf("%w: no verification share of signer %d", ErrInvalidSignatureShare, share.Index)
+	}
+	if err := vs.Verify(g, sharing); err != nil {
+		return err
+	}
+	c := pkg.commitments[share.Index]
+	nonce := g.Add(c.Hiding, g.ScalarMult(c.Binding, pkg.rhos[share.Index]))
+	if pkg.negateNonces {
+		nonce = g.Neg(nonce)
+	}
+	Y := vs.Y
+	if pkg.negateKey {
+		Y = g.Neg(Y)
+	}
+	expected := g.Add(nonce, g.ScalarMult(Y, new(big.Int).Mul(pkg.lambda(share.Index), pkg.challenge)))
+	if !g.Equal(g.ScalarBaseMult(share.Z), expected) {
+		return fmt.Errorf("%w: share of signer %d", ErrInvalidSignatureShare, share.Index)
+	}
+	return nil
+}
+
+// XOnlyPublicKey returns the 32 bytes x-only BIP-340 public key of the point.
+func XOnlyPublicKey(g pvss.Group, publicKey pvss.Element) []byte {
+	return xOnly(g, publicKey)
+}
+
+// Verify verifies the BIP-340 signature of the message by the x-only public key.
+func Verify(publicKey, message, signature []byte) bool {
+	return secp256k1.VerifySchnorr(publicKey, message, signature)
+}
+
+// challenge returns the BIP-340 challenge int(tagged_hash("BIP0340/challenge", r || P || m)) mod n.
+func challenge(g pvss.Group, r, publicKey, message []byte) *big.Int {
+	tag := sha256.Sum256([]byte("BIP0340/challenge"))
+	h := sha256.New()
+	h.Write(tag[:])
+	h.Write(tag[:])
+	h.Write(r)
+	h.Write(publicKey)
+	h.Write(message)
+	e := new(big.Int).SetBytes(h.Sum(nil))
+	return e.Mod(e, g.Order())
+}
+
+// hashToScalar hashes the context string, the tag and the messages into a scalar, by SHA-512 reduced modulo the order.
+func hashToScalar(g pvss.Group, tag string, msgs ...[]byte) *big.Int {
+	k := new(big.Int).SetBytes(wideHash(tag, msgs...))
+	return k.Mod(k, g.Order())
+}
+
+// wideHash is the SHA-512 hash of the context string, the tag and the messages.
+func wideHash(tag string, msgs ...[]byte) []byte {
+	h := sha512.New()
+	h.Write([]byte(contextString + tag))
+	for _, msg := range msgs {
+		h.Write(msg)
+	}
+	return h.Sum(nil)
+}
+
+// hash is the SHA-256 hash of the context string, the tag and the message.
+func hash(tag string, msg []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte(contextString + tag))
+	h.Write(msg)
+	return h.Sum(nil)
+}
+
+func serializeScalar(k *big.Int) []byte {
+	b := make([]byte, 32)
+	return k.FillBytes(b)
+}
+
+// isSecp256k1 reports whether g is the group of secp256k1, by its order and its generator in the compressed encoding,
+// which xOnly and hasOddY rely on.
+func isSecp256k1(g pvss.Group) bool {
+	curve := secp256k1.S256()
+	return g != nil && g.Order().Cmp(curve.N) == 0 &&
+		bytes.Equal(g.Encode(g.Generator()), secp256k1.CompressPubkey(curve.Gx, curve.Gy))
+}
+
+// xOnly returns the x of the point in 32 bytes, from its compressed encoding.
+func xOnly(g pvss.Group, a pvss.Element) []byte {
+	return g.Encode(a)[1:]
+}
+
+func hasOddY(g pvss.Group, a pvss.Element) bool {
+	return g.Encode(a)[0] == 0x03
+}
@@ -0,0 +1,305 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package frost
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/stars-labs/go-pvss/dkg"
+	"github.com/stars-labs/go-pvss/pvss"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+// renamedGroup is a group which claims the name of secp256k1.
+type renamedGroup struct {
+	pvss.Group
+}
+
+func (renamedGroup) Name() string {
+	return "secp256k1"
+}
+
+// newKeyShares runs a dkg ceremony on secp256k1, and returns the key shares of all the parties with their verification shares.
+func newKeyShares(t *testing.T, n, threshold int) ([]*KeyShare, []*VerificationShare) {
+	g := pvss.Secp256k1()
+	config := &dkg.Config{Group: g, ID: []byte("frost test"), Epoch: 1, Threshold: threshold}
+	privates := make([]*big.Int, n)
+	for i := range privates {
+		private, public, err := pvss.GenerateKey(g, rand.Reader)
+		require.NoError(t, err)
+		privates[i] = private
+		config.PublicKeys = append(config.PublicKeys, public)
+	}
+	parties := make([]*dkg.Party, n)
+	deals := make([]*dkg.Deal, n)
+	for i, private := range privates {
+		party, err := dkg.NewParty(config, i+1, private)
+		require.NoError(t, err)
+		parties[i] = party
+		deals[i], err = party.Deal()
+		require.NoError(t, err)
+	}
+	keyShares := make([]*KeyShare, n)
+	verificationShares := make([]*VerificationShare, n)
+	for i, party := range parties {
+		for _, d := range deals {
+			complaint, err := party.ProcessDeal(d)
+			require.NoError(t, err)
+			require.Nil(t, complaint)
+		}
+		result, err := party.Finalize(deals, nil)
+		require.NoError(t, err)
+		keyShares[i], err = NewKeyShare(g, result)
+		require.NoError(t, err)
+		verificationShares[i], err = keyShares[i].VerificationShare()
+		require.NoError(t, err)
+		require.NoError(t, verificationShares[i].Verify(g, result.Commitments))
+	}
+	return keyShares, verificationShares
+}
+
+// negateKey turns the key shares of x into the ones of -x, whose public key has the other y parity.
+func negateKey(keyShares []*KeyShare, verificationShares []*VerificationShare) ([]*KeyShare, []*VerificationShare) {
+	g := keyShares[0].Group
+	commitments := make([]pvss.Element, len(keyShares[0].Commitments))
+	for j, c := range keyShares[0].Commitments {
+		commitments[j] = g.Neg(c)
+	}
+	negated := make([]*KeyShare, len(keyShares))
+	for i, k := range keyShares {
+		negated[i] = &KeyShare{
+			Group:       g,
+			Index:       k.Index,
+			SecretShare: new(big.Int).Sub(g.Order(), k.SecretShare),
+			PublicKey:   g.Neg(k.PublicKey),
+			Commitments: commitments,
+		}
+	}
+	negatedVS := make([]*VerificationShare, len(keyShares))
+	for i, k := range negated {
+		vs, err := k.VerificationShare()
+		if err != nil {
+			panic(err)
+		}
+		if vs.Verify(g, commitments) != nil || !g.Equal(vs.Y, g.Neg(verificationShares[i].Y)) {
+			panic("frost test: invalid negated verification share")
+		}
+		negatedVS[i] = vs
+	}
+	return negated, negatedVS
+}
+
+// sign runs the two rounds by the signers, and aggregates their shares.
+func sign(t *testing.T, signers []*KeyShare, verificationShares []*VerificationShare, message []byte) []byte {
+	nonces := make([]*SigningNonces, len(signers))
+	commitments := make([]*SigningCommitment, len(signers))
+	for i, k := range signers {
+		var err error
+		nonces[i], commitments[i], err = k.Commit()
+		require.NoError(t, err)
+	}
+	k0 := signers[0]
+	shares := make([]*SignatureShare, len(signers))
+	for i, k := range signers {
+		var err error
+		shares[i], err = k.Sign(nonces[i], message, commitments)
+		require.NoError(t, err)
+		require.NoError(t, VerifySignatureShare(k.Group, k.PublicKey, k.Commitments, message, commitments, verificationShares[k.Index-1], shares[i]))
+	}
+	signature, err := Aggregate(k0.Group, k0.PublicKey, k0.Commitments, message, commitments, shares, verificationShares)
+	require.NoError(t, err)
+	return signature
+}
+
+func TestFROST(t *testing.T) {
+	g := pvss.Secp256k1()
+	keyShares, verificationShares := newKeyShares(t, 5, 3)
+	negated, negatedVS := negateKey(keyShares, verificationShares)
+	message := []byte("signed by the committee")
+
+	// both parities of the public key, by any threshold or more of the signers
+	for _, keys := range []struct {
+		shares []*KeyShare
+		vs     []*VerificationShare
+	}{{keyShares, verificationShares}, {negated, negatedVS}} {
+		publicKey := XOnlyPublicKey(g, keys.shares[0].PublicKey)
+		for _, signers := range [][]*KeyShare{keys.shares[:3], keys.shares[2:], {keys.shares[4], keys.shares[0], keys.shares[2]}, keys.shares} {
+			// the group commitment R has an odd y in about half of the signatures
+			for round := 0; round < 4; round++ {
+				signature := sign(t, signers, keys.vs, message)
+				require.Len(t, signature, 64)
+				require.True(t, Verify(publicKey, message, signature))
+				require.False(t, Verify(publicKey, []byte("another message"), signature))
+			}
+		}
+	}
+	require.Equal(t, XOnlyPublicKey(g, keyShares[0].PublicKey), XOnlyPublicKey(g, negated[0].PublicKey))
+}
+
+func TestFROST_Rejects(t *testing.T) {
+	g := pvss.Secp256k1()
+	keyShares, verificationShares := newKeyShares(t, 4, 3)
+	k := keyShares[0]
+	message := []byte("message")
+
+	_, err := NewKeyShare(pvss.Ristretto255(), &dkg.Result{})
+	require.ErrorIs(t, err, ErrInvalidKeyShare)
+	// the group is recognized by its order and generator, not by its name
+	_, err = NewKeyShare(renamedGroup{pvss.P256()}, &dkg.Result{})
+	require.ErrorIs(t, err, ErrInvalidKeyShare)
+	_, err = NewKeyShare(g, &dkg.Result{Index: 1, SecretShare: big.NewInt(1), PublicKey: k.PublicKey, Commitments: k.Commitments})
+	require.ErrorIs(t, err, ErrInvalidKeyShare)
+	forgedVS := *verificationShares[1]
+	forgedVS.Index = 3
+	require.ErrorIs(t, forgedVS.Verify(g, k.Commitments), ErrInvalidVerificationShare)
+
+	signers := keyShares[:3]
+	nonces := make([]*SigningNonces, 3)
+	commitments := make([]*SigningCommitment, 3)
+	for i, k := range signers {
+		nonces[i], commitments[i], err = k.Commit()
+		require.NoError(t, err)
+	}
+
+	// fewer than the threshold of signers, a duplicate signer, and nonces of another signer
+	_, err = k.Sign(nonces[0], message, commitments[:2])
+	require.ErrorIs(t, err, ErrInvalidCommitments)
+	_, err = k.Sign(nonces[0], message, []*SigningCommitment{commitments[0], commitments[1], commitments[1]})
+	require.ErrorIs(t, err, ErrInvalidCommitments)
+	_, err = k.Sign(nonces[1], message, commitments)
+	require.ErrorIs(t, err, ErrInvalidKeyShare)
+
+	shares := make([]*SignatureShare, 3)
+	for i, k := range signers {
+		shares[i], err = k.Sign(nonces[i], message, commitments)
+		require.NoError(t, err)
+	}
+	_, err = k.Sign(nonces[0], message, commitments)
+	require.ErrorIs(t, err, ErrNonceReused)
+
+	// a tampered share is reported by its signer, or fails the signature without the verification shares
+	tampered := *shares[1]
+	tampered.Z = new(big.Int).Add(tampered.Z, big.NewInt(1))
+	forged := []*SignatureShare{shares[0], &tampered, shares[2]}
+	require.ErrorIs(t, VerifySignatureShare(g, k.PublicKey, k.Commitments, message, commitments, verificationShares[1], &tampered), ErrInvalidSignatureShare)
+	_, err = Aggregate(g, k.PublicKey, k.Commitments, message, commitments, forged, verificationShares)
+	require.ErrorIs(t, err, ErrInvalidSignatureShare)
+	require.Contains(t, err.Error(), "signer 2")
+	_, err = Aggregate(g, k.PublicKey, k.Commitments, message, commitments, forged, nil)
+	require.ErrorIs(t, err, ErrInvalidSignature)
+	_, err = Aggregate(g, k.PublicKey, k.Commitments, message, commitments, shares[:2], verificationShares)
+	require.ErrorIs(t, err, ErrInvalidSignatureShare)
+	// the shares sign the message of the commitments only
+	_, err = Aggregate(g, k.PublicKey, k.Commitments, []byte("other"), commitments, shares, verificationShares)
+	require.ErrorIs(t, err, ErrInvalidSignatureShare)
+
+	// a verification share Y' forged to match the tampered share, z'·G == ±(D_i + ρ_i·E_i) + λ_i·c·(±Y'), is not the one
+	// of the sharing
+	pkg, err := newSigningPackage(g, k.PublicKey, 3, message, commitments)
+	require.NoError(t, err)
+	nonce := g.Add(commitments[1].Hiding, g.ScalarMult(commitments[1].Binding, pkg.rhos[2]))
+	if pkg.negateNonces {
+		nonce = g.Neg(nonce)
+	}
+	factor := new(big.Int).Mul(pkg.lambda(2), pkg.challenge)
+	factor.ModInverse(factor.Mod(factor, g.Order()), g.Order())
+	Y := g.ScalarMult(g.Add(g.ScalarBaseMult(tampered.Z), g.Neg(nonce)), factor)
+	if pkg.negateKey {
+		Y = g.Neg(Y)
+	}
+	matching := *verificationShares[1]
+	matching.Y = Y
+	require.NoError(t, pkg.verifyShare(k.Commitments, verificationShares[1], shares[1]))
+	require.ErrorIs(t, VerifySignatureShare(g, k.PublicKey, k.Commitments, message, commitments, &matching, &tampered), ErrInvalidVerificationShare)
+	_, err = Aggregate(g, k.PublicKey, k.Commitments, message, commitments, forged,
+		[]*VerificationShare{verificationShares[0], &matching, verificationShares[2]})
+	require.ErrorIs(t, err, ErrInvalidVerificationShare)
+
+	signature, err := Aggregate(g, k.PublicKey, k.Commitments, message, commitments, shares, verificationShares)
+	require.NoError(t, err)
+	require.True(t, Verify(XOnlyPublicKey(g, k.PublicKey), message, signature))
+}
+
+// TestVerify checks Verify against test vectors of BIP-340.
+func TestVerify(t *testing.T) {
+	vectors := []struct {
+		publicKey, message, signature string
+		valid                         bool
+	}{
+		{"F9308A019258C31049344F85F89D5229B531C845836F99B08601F113BCE036F9", "0000000000000000000000000000000000000000000000000000000000000000",
+			"E907831F80848D1069A5371B402410364BDF1C5F8307B0084C55F1CE2DCA821525F66A4A85EA8B71E482A74F382D2CE5EBEEE8FDB2172F477DF4900D310536C0", true},
+		{"DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA659", "243F6A8885A308D313198A2E03707344A4093822299F31D0082EFA98EC4E6C89",
+			"6896BD60EEAE296DB48A229FF71DFE071BDE413E6D43F917DC8DCF8C78DE33418906D11AC976ABCCB20B091292BFF4EA897EFCB639EA871CFA95F6DE339E4B0A", true},
+		{"D69C3509BB99E412E68B0FE8544E72837DFA30746D8BE2AA65975F29D22DC7B9", "4DF3C3F68FCC83B27E9D42C90431A72499F17875C81A599B566C9889B9696703",
+			"00000000000000000000003B78CE563F89A0ED9414F5AA28AD0D96D6795F9C6376AFB1548AF603B3EB45C9F8207DEE1060CB71C04E80F593060B07D28308D7F4", true},
+		{"778CAA53B4393AC467774D09497A87224BF9FAB6F6E68B23086497324D6FD117", "",
+			"71535DB165ECD9FBBC046E5FFAEA61186BB6AD436732FCCC25291A55895464CF6069CE26BF03466228F19A3A62DB8A649F2D560FAC652827D1AF0574E427AB63", true},
+		// public key not on the curve
+		{"EEFDEA4CDB677750A420FEE807EACF21EB9898AE79B9768766E4FAA04A2D4A34", "243F6A8885A308D313198A2E03707344A4093822299F31D0082EFA98EC4E6C89",
+			"6CFF5C3BA86C69EA4B7376F31A9BCB4F74C1976089B2D9963DA2E5543E17776969E89B4C5564D00349106B8497785DD7D1D713A8AE82B32FA79D5F7FC407D39B", false},
+		// has_even_y(R) is false
+		{"DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA659", "243F6A8885A308D313198A2E03707344A4093822299F31D0082EFA98EC4E6C89",
+			"FFF97BD5755EEEA420453A14355235D382F6472F8568A18B2F057A14602975563CC27944640AC607CD107AE10923D9EF7A73C643E166BE5EBEAFA34B1AC553E2", false},
+		// sG - eP is infinite
+		{"DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA659", "243F6A8885A308D313198A2E03707344A4093822299F31D0082EFA98EC4E6C89",
+			"0000000000000000000000000000000000000000000000000000000000000000123DDA8328AF9C23A94C1FEECFD123BA4FB73476F0D594DCB65C6425BD186051", false},
+		// sig[32:64] is equal to the curve order
+		{"DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA659", "243F6A8885A308D313198A2E03707344A4093822299F31D0082EFA98EC4E6C89",
+			"6CFF5C3BA86C69EA4B7376F31A9BCB4F74C1976089B2D9963DA2E5543E177769FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141", false},
+		// public key exceeds the field size
+		{"FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEFFFFFC30", "243F6A8885A308D313198A2E03707344A4093822299F31D0082EFA98EC4E6C89",
+			"6CFF5C3BA86C69EA4B7376F31A9BCB4F74C1976089B2D9963DA2E5543E17776969E89B4C5564D00349106B8497785DD7D1D713A8AE82B32FA79D5F7FC407D39B", false},
+	}
+	for i, v := range vectors {
+		publicKey, _ := hex.DecodeString(v.publicKey)
+		message, _ := hex.DecodeString(v.message)
+		signature, _ := hex.DecodeString(v.signature)
+		require.Equal(t, v.valid, Verify(publicKey, message, signature), "vector %d", i)
+	}
+}
+
+func TestNewKeyShareFromBox(t *testing.T) {
+	threshold, n := 2, 3
+	g := pvss.Secp256k1()
+	holders := make([]*pvss.Dealer, n+1)
+	pks := make([]pvss.Element, n+1)
+	for i := range holders {
+		private, public, err := pvss.GenerateKey(g, rand.Reader)
+		require.NoError(t, err)
+		holders[i], pks[i] = pvss.NewDealer(g, private), public
+	}
+	session := pvss.NewSession(g, []byte("frost test"), 1, pks[1:], threshold)
+	kb, err := holders[0].DistributeKey(pks[1:], session)
+	require.NoError(t, err)
+
+	keyShares := make([]*KeyShare, n)
+	verificationShares := make([]*VerificationShare, n)
+	for i, holder := range holders[1:] {
+		keyShares[i], err = NewKeyShareFromBox(g, session, kb, holder)
+		require.NoError(t, err)
+		require.Equal(t, i+1, keyShares[i].Index)
+		verificationShares[i], err = keyShares[i].VerificationShare()
+		require.NoError(t, err)
+	}
+	message := []byte("signed by the holders of a dealt key")
+	signature := sign(t, []*KeyShare{keyShares[2], keyShares[0]}, verificationShares, message)
+	require.True(t, Verify(XOnlyPublicKey(g, kb.PublicKey), message, signature))
+
+	// a box of another session, a forged public key, or a signer without a share
+	_, err = NewKeyShareFromBox(g, pvss.NewSession(g, session.ID, 2, pks[1:], threshold), kb, holders[1])
+	require.ErrorIs(t, err, ErrInvalidKeyShare)
+	forged := *kb
+	forged.PublicKey = g.Generator()
+	_, err = NewKeyShareFromBox(g, session, &forged, holders[1])
+	require.ErrorIs(t, err, ErrInvalidKeyShare)
+	_, err = NewKeyShareFromBox(g, session, kb, holders[0])
+	require.ErrorIs(t, err, ErrInvalidKeyShare)
+}
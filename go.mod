@@ -3,6 +3,7 @@ module github.com/stars-labs/go-pvss
 go 1.16
 
 require (
+	github.com/gtank/ristretto255 v0.1.2
 	github.com/stretchr/testify v1.7.0
 	golang.org/x/crypto v0.0.0-20211117183948-ae814b36b871
 )
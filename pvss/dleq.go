@@ -9,8 +9,7 @@
 package pvss
 
 import (
-	"golang.org/x/crypto/sha3"
-	"hash"
+	"github.com/stars-labs/go-pvss/crypto/scalar"
 	"math/big"
 )
 
@@ -19,92 +18,112 @@ import (
 // [CP93] D. Chaum and T. P. Pedersen. Wallet databases with observers. In Advances in Cryptology—CRYPTO ’92,
 // volume 740 of Lecture Notes in Computer Science, pages 89–105, Berlin, 1993. Springer-Verlag.
 //
-// Under ECC scheme, we denote that G1,G2 are two generators of the selected curve, and no one knows the relationship of G1 and G2,
-// n is the order of the curve's base point.
+// Under ECC scheme, we denote that G1,G2 are two generators of the selected group, and no one knows the relationship of G1 and G2,
+// n is the order of the group.
 // The prover needs to prove that he knows alpha such that H1 = alpha · G1, H2 = alpha · G2.
 // We denote this protocol by DLEQ(G1,H1,G2,H2).
 //
 // And by using a secure hash function, we can turn the interactive protocol to a non-interactive protocol.
 //
 // - The prover selects a random omega (w), calculates H1 = alpha · G1, H2 = alpha · G2, A1 = w · G1, A2 = w · G2
-// - The prover then calculates Hash(H1,H2,A1,A2) as challenge c,
+// - The prover then derives the challenge c from a Transcript of the protocol, to which G1,H1,G2,H2,A1,A2 are appended,
 // - and calculates a response r = (w - alpha * c) mod n,
-// - The verifier calculates A1 = r · G1 + c · H1, A2 = r · G2 + c · H2, and verify that Hash(H1,H2,A1,A2) == c
+// - The verifier calculates A1 = r · G1 + c · H1, A2 = r · G2 + c · H2, and verify that the challenge of the same transcript == c
 type DLEQ struct {
-	G1 *Point
-	H1 *Point
-	G2 *Point
-	H2 *Point
+	Group Group
+	G1    Element
+	H1    Element
+	G2    Element
+	H2    Element
 
-	w     *big.Int
-	alpha *big.Int
-	r     *big.Int
+	w     *scalar.Scalar
+	alpha *scalar.Scalar
 }
 
-// NewDLEQ initialises DLEQ(G1,H1,G2,H2), where H1, H2 can be nil.
+// NewDLEQ initialises DLEQ(G1,H1,G2,H2) on the group g, where H1, H2 can be nil.
 // when H1,H2 are nil, then they will be calculated by H1 = alpha · G1, H2 = alpha · G2 .
 // H1,H2 should never be nil on the result.
-func NewDLEQ(G1, H1, G2, H2 *Point, w, alpha *big.Int) *DLEQ {
+func NewDLEQ(g Group, G1, H1, G2, H2 Element, w, alpha *big.Int) *DLEQ {
+	m := OrderModulus(g)
+	return NewScalarDLEQ(g, G1, H1, G2, H2, m.FromBig(w), m.FromBig(alpha))
+}
+
+// NewScalarDLEQ initialises DLEQ(G1,H1,G2,H2) like NewDLEQ, with the constant-time scalars w, alpha modulo the order of g.
+func NewScalarDLEQ(g Group, G1, H1, G2, H2 Element, w, alpha *scalar.Scalar) *DLEQ {
 	if H1 == nil {
-		h1x, h1y := theCurve.ScalarMult(G1.X, G1.Y, alpha.Bytes())
-		H1 = &Point{X: h1x, Y: h1y}
+		H1 = g.ScalarMult(G1, alpha.Big())
 	}
 	if H2 == nil {
-		h2x, h2y := theCurve.ScalarMult(G2.X, G2.Y, alpha.Bytes())
-		H2 = &Point{X: h2x, Y: h2y}
+		H2 = g.ScalarMult(G2, alpha.Big())
 	}
 	return &DLEQ{
+		Group: g,
 		G1:    G1,
 		H1:    H1,
 		G2:    G2,
 		H2:    H2,
 		w:     w,
 		alpha: alpha,
-		r:     nil,
 	}
 }
 
 // ChallengeAndResponse calculates and returns the challenge and response,
 // A1 := w·G1 , A2 := w·G2 ,
-// c := Hash(H1,H2,A1,A2) mod n ,
+// c := challenge of the transcript t with G1,H1,G2,H2,A1,A2 appended ,
 // r := (w - alpha*c) mod n .
-func (d *DLEQ) ChallengeAndResponse() (c, r *big.Int) {
+// The transcript t, which binds the protocol and the session, is not modified.
+func (d *DLEQ) ChallengeAndResponse(t *Transcript) (c, r *big.Int) {
+	_, _, c, r = d.prove(t)
+	return
+}
+
+// prove returns the commitments A1, A2 of the proof along with its challenge and response, so that the proof can be
+// verified both in the challenge form and in the commitment form.
+func (d *DLEQ) prove(t *Transcript) (a1, a2 Element, c, r *big.Int) {
 	// A1 := w·G1 A2 := w·G2
-	a1x, a1y := theCurve.ScalarMult(d.G1.X, d.G1.Y, d.w.Bytes())
-	//log.Printf("Prover A1: %s, %s\n", a1x.Text(16), a1y.Text(16))
-	a2x, a2y := theCurve.ScalarMult(d.G2.X, d.G2.Y, d.w.Bytes())
-	//log.Printf("Prover A2: %s, %s\n", a2x.Text(16), a2y.Text(16))
+	w := d.w.Big()
+	a1 = d.Group.ScalarMult(d.G1, w)
+	a2 = d.Group.ScalarMult(d.G2, w)
 
-	// c := Hash(H1,H2,A1,A2) mod n
-	hasher := sha3.New256()
-	c = HashMod(secp256k1N, hasher, d.H1.X, d.H1.Y, d.H2.X, d.H2.Y, a1x, a1y, a2x, a2y)
+	c = dleqChallenge(d.Group, t, d.G1, d.H1, d.G2, d.H2, a1, a2)
 	// r := (w - alpha*c) mod n
-	r = Response(d.w, d.alpha, c, secp256k1N)
+	r = response(d.w, d.alpha, d.w.Modulus().FromBig(c)).Big()
 	return
 }
 
 // Response calculates and returns r := (w - alpha*c) mod n
 func Response(w, alpha, c, n *big.Int) *big.Int {
-	r := new(big.Int).Mul(alpha, c) // alpha * c
-	r.Mod(r, n)                     // (alpha * c) mod n
-	r.Sub(w, r)                     // w - alpha*c
-	r.Mod(r, n)
-	return r
+	m := modulusOf(n)
+	return response(m.FromBig(w), m.FromBig(alpha), m.FromBig(c)).Big()
 }
 
-// DLEQVerify calculates A1 = r · G1 + c · H1, A2 = r · G2 + c · H2, and verify that Hash(H1,H2,A1,A2) == c
-func DLEQVerify(hasher hash.Hash, G1, H1, G2, H2 *Point, c, r *big.Int) bool {
-	//  A1 := r·G1 + c·H1,   A2 := r·G2 + c·H2
-	a1x, a1y := theCurve.ScalarMult(G1.X, G1.Y, r.Bytes())
-	h1cx, h1cy := theCurve.ScalarMult(H1.X, H1.Y, c.Bytes())
-	a1x, a1y = theCurve.Add(a1x, a1y, h1cx, h1cy)
-	//log.Printf("Verify A1: %s, %s\n", a1x.Text(16), a1y.Text(16))
+// response returns r := w - alpha*c, in constant time.
+func response(w, alpha, c *scalar.Scalar) *scalar.Scalar {
+	r := w.Modulus().NewScalar().Mul(alpha, c)
+	return r.Sub(w, r)
+}
 
-	a2x, a2y := theCurve.ScalarMult(G2.X, G2.Y, r.Bytes())
-	h2cx, h2cy := theCurve.ScalarMult(H2.X, H2.Y, c.Bytes())
-	a2x, a2y = theCurve.Add(a2x, a2y, h2cx, h2cy)
-	//log.Printf("Verify A2: %s, %s\n", a2x.Text(16), a2y.Text(16))
+// DLEQVerify calculates A1 = r · G1 + c · H1, A2 = r · G2 + c · H2, and verify that the challenge of the transcript t
+// with G1,H1,G2,H2,A1,A2 appended == c. The transcript t is not modified.
+func DLEQVerify(g Group, t *Transcript, G1, H1, G2, H2 Element, c, r *big.Int) bool {
+	//  A1 := r·G1 + c·H1,   A2 := r·G2 + c·H2
+	a1 := g.Add(g.ScalarMult(G1, r), g.ScalarMult(H1, c))
+	a2 := g.Add(g.ScalarMult(G2, r), g.ScalarMult(H2, c))
 
-	localChallenge := HashMod(secp256k1N, hasher, H1.X, H1.Y, H2.X, H2.Y, a1x, a1y, a2x, a2y)
+	localChallenge := dleqChallenge(g, t, G1, H1, G2, H2, a1, a2)
 	return localChallenge.Cmp(c) == 0
 }
+
+// dleqChallenge derives the challenge of DLEQ(G1,H1,G2,H2) with the commitments A1,A2 from a copy of the transcript t.
+func dleqChallenge(g Group, t *Transcript, G1, H1, G2, H2, a1, a2 Element) *big.Int {
+	t = t.Clone()
+	t.AppendMessage("proof", []byte("DLEQ"))
+	t.AppendMessage("group", []byte(g.Name()))
+	t.AppendElement(g, "G1", G1)
+	t.AppendElement(g, "H1", H1)
+	t.AppendElement(g, "G2", G2)
+	t.AppendElement(g, "H2", H2)
+	t.AppendElement(g, "A1", a1)
+	t.AppendElement(g, "A2", a2)
+	return t.ChallengeScalar(g, "c")
+}
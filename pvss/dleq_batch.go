@@ -0,0 +1,205 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pvss
+
+import (
+	"crypto/rand"
+	"io"
+	"math/big"
+)
+
+// batchWeightBits is the size of the random weights of a batch, a batch with a faulty proof passes with probability 2^-128.
+const batchWeightBits = 128
+
+// DLEQProof is a DLEQ(G1,H1,G2,H2) proof in the commitment form (A1, A2, r), where the challenge c is derived from the
+// transcript with A1,A2. Unlike the challenge form (c, r), whose commitments have to be recomputed one by one to hash them,
+// the verification equations A1 == r·G1 + c·H1, A2 == r·G2 + c·H2 of many proofs can be checked together by BatchDLEQVerifier.
+type DLEQProof struct {
+	A1 Element
+	A2 Element
+	R  *big.Int
+}
+
+// Prove returns the proof of d in the commitment form, with the challenge derived from the transcript t like ChallengeAndResponse.
+// The transcript t is not modified.
+func (d *DLEQ) Prove(t *Transcript) *DLEQProof {
+	a1, a2, _, r := d.prove(t)
+	return &DLEQProof{A1: a1, A2: a2, R: r}
+}
+
+// Challenge returns the challenge c of the proof of DLEQ(G1,H1,G2,H2), so that (c, p.R) is the proof in the challenge form,
+// which is accepted by DLEQVerify. The transcript t is not modified.
+func (p *DLEQProof) Challenge(g Group, t *Transcript, G1, H1, G2, H2 Element) *big.Int {
+	return dleqChallenge(g, t, G1, H1, G2, H2, p.A1, p.A2)
+}
+
+// BatchDLEQVerifier verifies many DLEQ proofs at once.
+//
+// Every proof i adds the equations r_i·G1_i + c_i·H1_i - A1_i == 0 and r_i·G2_i + c_i·H2_i - A2_i == 0, which are combined
+// with random weights into a single multi-scalar multiplication. When the batch fails, it's bisected with fresh weights
+// to find the faulty proofs.
+type BatchDLEQVerifier struct {
+	group   Group
+	random  io.Reader
+	entries []*batchDLEQEntry
+}
+
+type batchDLEQEntry struct {
+	G1, H1, G2, H2, A1, A2 Element
+	c, r                   *big.Int
+	malformed              bool
+}
+
+// NewBatchDLEQVerifier creates an empty batch of DLEQ proofs on the group g.
+func NewBatchDLEQVerifier(g Group) *BatchDLEQVerifier {
+	return &BatchDLEQVerifier{group: g, random: rand.Reader}
+}
+
+// Add adds the proof of DLEQ(G1,H1,G2,H2) made with the transcript t to the batch, its challenge is derived right away,
+// so the transcript t can be reused after. The proofs are numbered from 0 in the order they are added.
+func (b *BatchDLEQVerifier) Add(t *Transcript, G1, H1, G2, H2 Element, proof *DLEQProof) {
+	entry := &batchDLEQEntry{G1: G1, H1: H1, G2: G2, H2: H2}
+	if G1 == nil || H1 == nil || G2 == nil || H2 == nil || proof == nil || proof.A1 == nil || proof.A2 == nil || proof.R == nil {
+		entry.malformed = true
+	} else {
+		entry.A1, entry.A2, entry.r = proof.A1, proof.A2, proof.R
+		entry.c = proof.Challenge(b.group, t, G1, H1, G2, H2)
+	}
+	b.entries = append(b.entries, entry)
+}
+
+// Len returns the number of the proofs in the batch.
+func (b *BatchDLEQVerifier) Len() int {
+	return len(b.entries)
+}
+
+// Verify reports whether all the proofs of the batch are valid, with a single multi-scalar multiplication.
+// An empty batch is valid.
+func (b *BatchDLEQVerifier) Verify() bool {
+	return b.verify(b.entries)
+}
+
+// Faulty returns the numbers of the invalid proofs in ascending order, or nil if the batch is valid.
+// It costs a single multi-scalar multiplication for a valid batch, and O(k·log(n)) smaller ones for k invalid proofs.
+func (b *BatchDLEQVerifier) Faulty() []int {
+	var faulty []int
+	var bisect func(offset int, entries []*batchDLEQEntry)
+	bisect = func(offset int, entries []*batchDLEQEntry) {
+		if b.verify(entries) {
+			return
+		}
+		if len(entries) == 1 {
+			faulty = append(faulty, offset)
+			return
+		}
+		half := len(entries) / 2
+		bisect(offset, entries[:half])
+		bisect(offset+half, entries[half:])
+	}
+	if len(b.entries) != 0 {
+		bisect(0, b.entries)
+	}
+	return faulty
+}
+
+// verify checks ∑ ρ_i·(r_i·G1_i + c_i·H1_i - A1_i) + σ_i·(r_i·G2_i + c_i·H2_i - A2_i) == 0 with random weights ρ_i, σ_i.
+// The scalars of the same point, like a shared base, are summed up first.
+func (b *BatchDLEQVerifier) verify(entries []*batchDLEQEntry) bool {
+	g := b.group
+	n := g.Order()
+	var points []Element
+	var scalars []*big.Int
+	indices := make(map[string]int)
+	add := func(p Element, k *big.Int) {
+		key := string(g.Encode(p))
+		if i, ok := indices[key]; ok {
+			scalars[i].Add(scalars[i], k).Mod(scalars[i], n)
+			return
+		}
+		indices[key] = len(points)
+		points = append(points, p)
+		scalars = append(scalars, new(big.Int).Mod(k, n))
+	}
+
+	bound := new(big.Int).Lsh(big.NewInt(1), batchWeightBits)
+	for _, e := range entries {
+		if e.malformed {
+			return false
+		}
+		rho, err := rand.Int(b.random, bound)
+		if err != nil {
+			return false
+		}
+		sigma, err := rand.Int(b.random, bound)
+		if err != nil {
+			return false
+		}
+		add(e.G1, new(big.Int).Mul(rho, e.r))
+		add(e.H1, new(big.Int).Mul(rho, e.c))
+		add(e.A1, new(big.Int).Neg(rho))
+		add(e.G2, new(big.Int).Mul(sigma, e.r))
+		add(e.H2, new(big.Int).Mul(sigma, e.c))
+		add(e.A2, new(big.Int).Neg(sigma))
+	}
+	if len(points) == 0 {
+		return true
+	}
+	return isIdentity(g, multiScalarMult(g, points, scalars))
+}
+
+// proofBatch verifies the DLEQ proofs of many shares, which are kept in the challenge form (c, r) along with their
+// commitments A1,A2: the proofs with commitments are verified together by a BatchDLEQVerifier, whose proof is also faulty
+// if c is not the challenge derived from A1,A2, and the other ones one by one. The faulty proofs found by the batch are
+// verified again in the challenge form, so that exactly the proofs accepted by DLEQVerify are accepted, whatever their
+// commitments.
+type proofBatch struct {
+	group   Group
+	batch   *BatchDLEQVerifier
+	batched []*batchedProof
+	failed  map[int]bool
+}
+
+// batchedProof is a proof of the batch, in the challenge form, of the share at index.
+type batchedProof struct {
+	index          int
+	t              *Transcript
+	G1, H1, G2, H2 Element
+	c, r           *big.Int
+}
+
+func newProofBatch(g Group) *proofBatch {
+	return &proofBatch{group: g, batch: NewBatchDLEQVerifier(g), failed: make(map[int]bool)}
+}
+
+// add adds the proof (c, r) of DLEQ(G1,H1,G2,H2) of the share at index, whose commitments a1, a2 can be nil.
+// The transcript t must not be modified until the batch is verified.
+func (b *proofBatch) add(index int, t *Transcript, G1, H1, G2, H2, a1, a2 Element, c, r *big.Int) {
+	if a1 == nil || a2 == nil {
+		if !DLEQVerify(b.group, t, G1, H1, G2, H2, c, r) {
+			b.failed[index] = true
+		}
+		return
+	}
+	b.batch.Add(t, G1, H1, G2, H2, &DLEQProof{A1: a1, A2: a2, R: r})
+	if e := b.batch.entries[len(b.batch.entries)-1]; !e.malformed && e.c.Cmp(c) != 0 {
+		e.malformed = true
+	}
+	b.batched = append(b.batched, &batchedProof{index: index, t: t, G1: G1, H1: H1, G2: G2, H2: H2, c: c, r: r})
+}
+
+// failures returns the indices of the shares with a faulty proof.
+func (b *proofBatch) failures() map[int]bool {
+	for _, k := range b.batch.Faulty() {
+		p := b.batched[k]
+		if !DLEQVerify(b.group, p.t, p.G1, p.H1, p.G2, p.H2, p.c, p.r) {
+			b.failed[p.index] = true
+		}
+	}
+	return b.failed
+}
@@ -9,62 +9,175 @@
 package pvss
 
 import (
-	"crypto/ecdsa"
 	"crypto/rand"
 	"github.com/stretchr/testify/require"
-	"golang.org/x/crypto/sha3"
+	"math/big"
 	"testing"
 )
 
 func TestNewDLEQ(t *testing.T) {
-	private, err := ecdsa.GenerateKey(theCurve, rand.Reader)
+	g := Secp256k1()
+	private, public, err := GenerateKey(g, rand.Reader)
 	require.NoError(t, err, "GenerateKey")
-	h2x, h2y := theCurve.ScalarMult(Hx, Hy, private.D.Bytes())
-	w, err := rand.Int(rand.Reader, secp256k1N)
+	h2 := g.ScalarMult(g.SecondGenerator(), private)
+	w, err := rand.Int(rand.Reader, g.Order())
 	require.NoError(t, err, "rand.Int")
-	dleq := NewDLEQ(G1, nil, G2, nil, w, private.D)
+	dleq := NewDLEQ(g, g.Generator(), nil, g.SecondGenerator(), nil, w, private)
 
-	require.Equal(t, 0, dleq.H1.X.Cmp(private.X))
-	require.Equal(t, 0, dleq.H1.Y.Cmp(private.Y))
-	require.Equal(t, 0, dleq.H2.X.Cmp(h2x))
-	require.Equal(t, 0, dleq.H2.Y.Cmp(h2y))
+	require.True(t, g.Equal(dleq.H1, public))
+	require.True(t, g.Equal(dleq.H2, h2))
 }
 
 func TestDLEQVerify(t *testing.T) {
-	private, err := ecdsa.GenerateKey(theCurve, rand.Reader)
+	g := Secp256k1()
+	private, _, err := GenerateKey(g, rand.Reader)
 	require.NoError(t, err, "GenerateKey")
-	//h2x, h2y := theCurve.ScalarMult(Hx, Hy, private.D.Bytes())
-	w, err := rand.Int(rand.Reader, secp256k1N)
+	w, err := rand.Int(rand.Reader, g.Order())
 	require.NoError(t, err, "rand.Int")
-	dleq := NewDLEQ(G1, nil, G2, nil, w, private.D)
+	dleq := NewDLEQ(g, g.Generator(), nil, g.SecondGenerator(), nil, w, private)
 
-	c, r := dleq.ChallengeAndResponse()
+	c, r := dleq.ChallengeAndResponse(NewTranscript("test", nil))
 
-	ok := DLEQVerify(sha3.New256(), dleq.G1, dleq.H1, dleq.G2, dleq.H2, c, r)
+	ok := DLEQVerify(g, NewTranscript("test", nil), dleq.G1, dleq.H1, dleq.G2, dleq.H2, c, r)
 	require.True(t, ok)
 }
 
+func TestResponse(t *testing.T) {
+	for _, g := range testGroups {
+		n := g.Order()
+		w, _ := rand.Int(rand.Reader, n)
+		alpha, _ := rand.Int(rand.Reader, n)
+		c, _ := rand.Int(rand.Reader, n)
+		want := new(big.Int).Mul(alpha, c)
+		want.Sub(w, want).Mod(want, n)
+		require.Equal(t, 0, want.Cmp(Response(w, alpha, c, n)), g.Name())
+	}
+	// r := w - alpha*c wraps around n
+	n := big.NewInt(11)
+	require.Equal(t, int64(2), Response(big.NewInt(1), big.NewInt(3), big.NewInt(7), n).Int64())
+}
+
+func TestDLEQTranscriptBinding(t *testing.T) {
+	g := Secp256k1()
+	private, _, err := GenerateKey(g, rand.Reader)
+	require.NoError(t, err, "GenerateKey")
+	w, err := rand.Int(rand.Reader, g.Order())
+	require.NoError(t, err, "rand.Int")
+	dleq := NewDLEQ(g, g.Generator(), nil, g.SecondGenerator(), nil, w, private)
+	transcript := NewTranscript("test", []byte("session 1"))
+	c, r := dleq.ChallengeAndResponse(transcript)
+
+	// the transcript is not consumed by the proof
+	require.True(t, DLEQVerify(g, transcript, dleq.G1, dleq.H1, dleq.G2, dleq.H2, c, r))
+	require.True(t, DLEQVerify(g, NewTranscript("test", []byte("session 1")), dleq.G1, dleq.H1, dleq.G2, dleq.H2, c, r))
+	// another protocol or session
+	require.False(t, DLEQVerify(g, NewTranscript("other", []byte("session 1")), dleq.G1, dleq.H1, dleq.G2, dleq.H2, c, r))
+	require.False(t, DLEQVerify(g, NewTranscript("test", []byte("session 2")), dleq.G1, dleq.H1, dleq.G2, dleq.H2, c, r))
+	// the label and the message are not ambiguous
+	require.False(t, DLEQVerify(g, NewTranscript("tes", []byte("tsession 1")), dleq.G1, dleq.H1, dleq.G2, dleq.H2, c, r))
+	// the bases are bound
+	require.False(t, DLEQVerify(g, transcript, dleq.G2, dleq.H2, dleq.G1, dleq.H1, c, r))
+}
+
 func BenchmarkDLEQ_ChallengeAndResponse(b *testing.B) {
-	private, err := ecdsa.GenerateKey(theCurve, rand.Reader)
+	g := Secp256k1()
+	private, _, err := GenerateKey(g, rand.Reader)
 	require.NoError(b, err, "GenerateKey")
-	w, err := rand.Int(rand.Reader, secp256k1N)
+	w, err := rand.Int(rand.Reader, g.Order())
 	require.NoError(b, err, "rand.Int")
-	dleq := NewDLEQ(G1, nil, G2, nil, w, private.D)
+	dleq := NewDLEQ(g, g.Generator(), nil, g.SecondGenerator(), nil, w, private)
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _ = dleq.ChallengeAndResponse()
+		_, _ = dleq.ChallengeAndResponse(NewTranscript("test", nil))
 	}
 }
 
 func BenchmarkDLEQVerify(b *testing.B) {
-	private, err := ecdsa.GenerateKey(theCurve, rand.Reader)
+	g := Secp256k1()
+	private, _, err := GenerateKey(g, rand.Reader)
 	require.NoError(b, err, "GenerateKey")
-	w, err := rand.Int(rand.Reader, secp256k1N)
+	w, err := rand.Int(rand.Reader, g.Order())
 	require.NoError(b, err, "rand.Int")
-	dleq := NewDLEQ(G1, nil, G2, nil, w, private.D)
-	c, r := dleq.ChallengeAndResponse()
+	dleq := NewDLEQ(g, g.Generator(), nil, g.SecondGenerator(), nil, w, private)
+	c, r := dleq.ChallengeAndResponse(NewTranscript("test", nil))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		DLEQVerify(g, NewTranscript("test", nil), dleq.G1, dleq.H1, dleq.G2, dleq.H2, c, r)
+	}
+}
+
+func TestBatchDLEQVerifier(t *testing.T) {
+	for _, g := range testGroups {
+		t.Run(g.Name(), func(t *testing.T) {
+			transcript := NewTranscript("test", []byte("batch"))
+			batch := NewBatchDLEQVerifier(g)
+			require.True(t, batch.Verify())
+			require.Nil(t, batch.Faulty())
+
+			dleqs := make([]*DLEQ, 9)
+			proofs := make([]*DLEQProof, len(dleqs))
+			for i := range dleqs {
+				private, _, err := GenerateKey(g, rand.Reader)
+				require.NoError(t, err, "GenerateKey")
+				w, err := rand.Int(rand.Reader, g.Order())
+				require.NoError(t, err, "rand.Int")
+				// the proofs share the base G1, like the decrypted shares
+				_, G2, err := GenerateKey(g, rand.Reader)
+				require.NoError(t, err, "GenerateKey")
+				dleqs[i] = NewDLEQ(g, g.Generator(), nil, G2, nil, w, private)
+				proofs[i] = dleqs[i].Prove(transcript)
+
+				// the commitment form converts to the challenge form
+				c := proofs[i].Challenge(g, transcript, dleqs[i].G1, dleqs[i].H1, dleqs[i].G2, dleqs[i].H2)
+				require.True(t, DLEQVerify(g, transcript, dleqs[i].G1, dleqs[i].H1, dleqs[i].G2, dleqs[i].H2, c, proofs[i].R))
+			}
+			add := func(batch *BatchDLEQVerifier, i int, proof *DLEQProof) {
+				batch.Add(transcript, dleqs[i].G1, dleqs[i].H1, dleqs[i].G2, dleqs[i].H2, proof)
+			}
+
+			for i := range dleqs {
+				add(batch, i, proofs[i])
+			}
+			require.Equal(t, len(dleqs), batch.Len())
+			require.True(t, batch.Verify())
+			require.Nil(t, batch.Faulty())
+
+			// a forged response, a swapped commitment, a missing proof and a proof of another session are found
+			batch = NewBatchDLEQVerifier(g)
+			for i := range dleqs {
+				proof := proofs[i]
+				switch i {
+				case 1:
+					proof = &DLEQProof{A1: proof.A1, A2: proof.A2, R: new(big.Int).Add(proof.R, big.NewInt(1))}
+				case 4:
+					proof = &DLEQProof{A1: proof.A2, A2: proof.A1, R: proof.R}
+				case 5:
+					proof = nil
+				case 8:
+					proof = dleqs[i].Prove(NewTranscript("test", []byte("another batch")))
+				}
+				add(batch, i, proof)
+			}
+			require.False(t, batch.Verify())
+			require.Equal(t, []int{1, 4, 5, 8}, batch.Faulty())
+		})
+	}
+}
+
+func BenchmarkBatchDLEQVerifier(b *testing.B) {
+	g := Secp256k1()
+	transcript := NewTranscript("test", nil)
+	batch := NewBatchDLEQVerifier(g)
+	for i := 0; i < 20; i++ {
+		private, _, err := GenerateKey(g, rand.Reader)
+		require.NoError(b, err, "GenerateKey")
+		w, err := rand.Int(rand.Reader, g.Order())
+		require.NoError(b, err, "rand.Int")
+		dleq := NewDLEQ(g, g.Generator(), nil, g.SecondGenerator(), nil, w, private)
+		batch.Add(transcript, dleq.G1, dleq.H1, dleq.G2, dleq.H2, dleq.Prove(transcript))
+	}
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		DLEQVerify(sha3.New256(), dleq.G1, dleq.H1, dleq.G2, dleq.H2, c, r)
+		batch.Verify()
 	}
 }
@@ -0,0 +1,84 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pvss
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"errors"
+	"fmt"
+)
+
+// CurveGroup is a Group of the points of an elliptic curve, the participants of which can use crypto/ecdsa keys of the curve.
+type CurveGroup interface {
+	Group
+	// Curve returns the elliptic curve of the group.
+	Curve() elliptic.Curve
+}
+
+var (
+	ErrNotCurveGroup = errors.New("group does not support ecdsa keys")
+	ErrCurveMismatch = errors.New("key curve does not match the group")
+)
+
+// PublicKeyFromECDSA converts an ecdsa public key to an element of the group g.
+// The key is rejected if its Curve is not the curve of g, so before using a key with a distribution shares box,
+// calling PublicKeyFromECDSA(box.Group, key) guards against a key of another curve.
+func PublicKeyFromECDSA(g Group, pk *ecdsa.PublicKey) (Element, error) {
+	cg, ok := g.(CurveGroup)
+	if !ok {
+		return nil, ErrNotCurveGroup
+	}
+	if pk == nil || pk.Curve == nil || pk.X == nil || pk.Y == nil {
+		return nil, ErrInvalidPoint
+	}
+	if !sameCurve(pk.Curve, cg.Curve()) {
+		return nil, fmt.Errorf("%w: %s", ErrCurveMismatch, g.Name())
+	}
+	if !cg.Curve().IsOnCurve(pk.X, pk.Y) {
+		return nil, ErrInvalidPoint
+	}
+	return &Point{X: pk.X, Y: pk.Y}, nil
+}
+
+// PublicKeysFromECDSA converts the ecdsa public keys of the participants to elements of the group g.
+func PublicKeysFromECDSA(g Group, pks []*ecdsa.PublicKey) ([]Element, error) {
+	elements := make([]Element, len(pks))
+	for i, pk := range pks {
+		e, err := PublicKeyFromECDSA(g, pk)
+		if err != nil {
+			return nil, fmt.Errorf("pubkey of participant %d: %w", i+1, err)
+		}
+		elements[i] = e
+	}
+	return elements, nil
+}
+
+// NewDealerFromECDSA creates a dealer on the group g with an ecdsa private key, whose Curve must be the curve of g.
+func NewDealerFromECDSA(g Group, privateKey *ecdsa.PrivateKey) (*Dealer, error) {
+	if privateKey == nil || privateKey.D == nil {
+		return nil, ErrInvalidScalar
+	}
+	pk, err := PublicKeyFromECDSA(g, &privateKey.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	d := NewDealer(g, privateKey.D)
+	if !g.Equal(d.PK, pk) {
+		return nil, errors.New("public key does not match the private key")
+	}
+	return d, nil
+}
+
+// sameCurve reports whether a and b have the same domain parameters.
+func sameCurve(a, b elliptic.Curve) bool {
+	pa, pb := a.Params(), b.Params()
+	return pa.P.Cmp(pb.P) == 0 && pa.N.Cmp(pb.N) == 0 && pa.B.Cmp(pb.B) == 0 &&
+		pa.Gx.Cmp(pb.Gx) == 0 && pa.Gy.Cmp(pb.Gy) == 0
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pvss
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"github.com/stars-labs/go-pvss/crypto/secp256k1"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+func TestECDSAKeys(t *testing.T) {
+	g := P256()
+	threshold, n := 2, 3
+	privates := make([]*ecdsa.PrivateKey, n+1)
+	publics := make([]*ecdsa.PublicKey, n+1)
+	for i := range privates {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		privates[i], publics[i] = key, &key.PublicKey
+	}
+	dealer, err := NewDealerFromECDSA(g, privates[0])
+	require.NoError(t, err)
+	pks, err := PublicKeysFromECDSA(g, publics[1:])
+	require.NoError(t, err)
+
+	secret := big.NewInt(20211117)
+	sharebox, err := dealer.DistributeSecret(secret, pks, testSession(g, pks, threshold))
+	require.NoError(t, err)
+	require.True(t, VerifyDistributionShares(g, sharebox.Session, sharebox))
+
+	decShares := make([]*DecryptedShare, 0, n)
+	for _, key := range privates[1:] {
+		// the key is checked against the group of the box
+		participant, err := NewDealerFromECDSA(sharebox.Group, key)
+		require.NoError(t, err)
+		ds, err := participant.ExtractSecretShare(sharebox)
+		require.NoError(t, err)
+		require.True(t, VerifyDecryptedShare(g, sharebox.Session, ds))
+		decShares = append(decShares, ds)
+	}
+	require.Equal(t, secret, ReconstructSecret(g, decShares[:threshold], sharebox.U))
+
+	// a secp256k1 key is rejected by a box dealt on P-256, and vice versa
+	k1Key, err := ecdsa.GenerateKey(secp256k1.S256(), rand.Reader)
+	require.NoError(t, err)
+	_, err = PublicKeyFromECDSA(sharebox.Group, &k1Key.PublicKey)
+	require.ErrorIs(t, err, ErrCurveMismatch)
+	_, err = NewDealerFromECDSA(sharebox.Group, k1Key)
+	require.ErrorIs(t, err, ErrCurveMismatch)
+	_, err = PublicKeysFromECDSA(Secp256k1(), publics)
+	require.ErrorIs(t, err, ErrCurveMismatch)
+	k1Pk, err := PublicKeyFromECDSA(Secp256k1(), &k1Key.PublicKey)
+	require.NoError(t, err)
+	require.True(t, Secp256k1().Equal(k1Pk, Secp256k1().ScalarBaseMult(k1Key.D)))
+
+	// groups without a curve do not take ecdsa keys
+	_, err = PublicKeyFromECDSA(Ristretto255(), publics[0])
+	require.ErrorIs(t, err, ErrNotCurveGroup)
+
+	// a point off the curve is rejected
+	_, err = PublicKeyFromECDSA(g, &ecdsa.PublicKey{Curve: elliptic.P256(), X: big.NewInt(1), Y: big.NewInt(1)})
+	require.ErrorIs(t, err, ErrInvalidPoint)
+}
@@ -0,0 +1,209 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pvss
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/sha3"
+	"io"
+	"math/big"
+)
+
+// The threshold ElGamal decryption of the package, on the commitments of a sharing of the key s:
+// the public key is C_0 = s·H, the commitment of the secret of a box or of a DKG, and X_i = ∑ C_j·i^j = p(i)·H is the
+// commitment of the share p(i), so the holders of the scalar shares decrypt without reconstructing s.
+//
+//   - The sender picks a random r, publishes R = r·H, and encrypts the message by the AEAD with the key derived from r·C_0
+//     and the session of the sharing.
+//   - The holder of p(i) publishes D_i = p(i)·R with a DLEQ(H,X_i,R,D_i) proof, bound to the session of the sharing, the
+//     ciphertext and the position i, so it's not replayed for another sharing, ciphertext or position.
+//   - Any threshold of the valid D_i are combined into ∑ λ_i·D_i = s·R = r·C_0, which derives the key.
+
+var ErrInvalidPublicKey = errors.New("invalid public key")
+
+// ElGamalCiphertext is a message encrypted to the public key C_0 of a sharing, see EncryptElGamal.
+type ElGamalCiphertext struct {
+	R       Element // r·H
+	Payload *Payload
+}
+
+// DecryptionShare is the partial decryption D_i = p(i)·R of a ciphertext by the holder of the share p(i) at Position,
+// with the DLEQ(H,X_i,R,D_i) proof.
+type DecryptionShare struct {
+	Position  int
+	D         Element
+	Challenge *big.Int
+	Response  *big.Int
+}
+
+// EncryptElGamal encrypts the plaintext with the associated data to the public key C_0 = s·H of a sharing in the session,
+// which is the first commitment of a box or of a DKG result. The AEAD key is derived from r·C_0 with HKDF-SHA3-256.
+func EncryptElGamal(g Group, session *Session, publicKey Element, plaintext, associatedData []byte, algorithm AEADAlgorithm) (*ElGamalCiphertext, error) {
+	if session == nil {
+		return nil, errors.New("missing session")
+	}
+	if publicKey == nil || isIdentity(g, publicKey) {
+		return nil, ErrInvalidPublicKey
+	}
+	k, err := OrderModulus(g).Random(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	r := k.Big()
+	R := g.ScalarMult(g.SecondGenerator(), r)
+	aead, err := algorithm.new(elgamalKey(g, session, publicKey, R, algorithm, g.ScalarMult(publicKey, r)))
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return &ElGamalCiphertext{
+		R: R,
+		Payload: &Payload{
+			Algorithm:  algorithm,
+			Nonce:      nonce,
+			Ciphertext: aead.Seal(nil, nonce, plaintext, associatedData),
+		},
+	}, nil
+}
+
+// PartialDecrypt returns the decryption share of the ciphertext by the holder of the scalar share p(i) at the position,
+// in the session of the sharing.
+func PartialDecrypt(g Group, session *Session, ciphertext *ElGamalCiphertext, position int, share *big.Int) (*DecryptionShare, error) {
+	if session == nil {
+		return nil, errors.New("missing session")
+	}
+	if ciphertext == nil || ciphertext.R == nil || ciphertext.Payload == nil {
+		return nil, ErrMissingPayload
+	}
+	if position < 1 {
+		return nil, ErrInvalidPosition
+	}
+	m := OrderModulus(g)
+	w, err := m.Random(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	dleq := NewScalarDLEQ(g, g.SecondGenerator(), nil, ciphertext.R, nil, w, m.FromBig(share))
+	c, r := dleq.ChallengeAndResponse(decryptionShareTranscript(g, session, ciphertext, position))
+	return &DecryptionShare{
+		Position:  position,
+		D:         dleq.H2,
+		Challenge: c,
+		Response:  r,
+	}, nil
+}
+
+// CheckDecryptionShare verifies a decryption share of the ciphertext against the commitments Cj of the sharing, in the
+// expected session of the sharing. A faulty share is reported as a *ShareVerificationError.
+func CheckDecryptionShare(g Group, expected *Session, commitments []Element, ciphertext *ElGamalCiphertext, decShare *DecryptionShare) error {
+	if expected == nil {
+		return fmt.Errorf("%w: missing session", ErrSessionMismatch)
+	}
+	if len(commitments) == 0 || ciphertext == nil || ciphertext.R == nil || ciphertext.Payload == nil {
+		return fmt.Errorf("%w: missing commitments or ciphertext", ErrInvalidBox)
+	}
+	if decShare == nil {
+		return &ShareVerificationError{Reason: ErrMissingShare}
+	}
+	fail := func(reason error) error {
+		return &ShareVerificationError{Position: decShare.Position, Reason: reason}
+	}
+	if decShare.Position < 1 {
+		return fail(ErrInvalidPosition)
+	}
+	if decShare.D == nil || decShare.Challenge == nil || decShare.Response == nil {
+		return fail(ErrMissingProof)
+	}
+	// DLEQ(H,X_i,R,D_i)
+	Xi := CommitmentAt(g, commitments, decShare.Position)
+	t := decryptionShareTranscript(g, expected, ciphertext, decShare.Position)
+	if !DLEQVerify(g, t, g.SecondGenerator(), Xi, ciphertext.R, decShare.D, decShare.Challenge, decShare.Response) {
+		return fail(ErrInvalidProof)
+	}
+	return nil
+}
+
+// DecryptElGamal verifies the decryption shares of the ciphertext against the commitments Cj of the sharing in the expected
+// session, whose threshold is len(commitments), combines them into r·C_0 by Lagrange interpolation, and decrypts the
+// ciphertext with the associated data. The first faulty share is reported as a *ShareVerificationError, other associated
+// data or a tampered ciphertext by ErrDecryptionFailed.
+func DecryptElGamal(g Group, expected *Session, commitments []Element, ciphertext *ElGamalCiphertext, decShares []*DecryptionShare, associatedData []byte) ([]byte, error) {
+	if ciphertext == nil || ciphertext.R == nil || ciphertext.Payload == nil {
+		return nil, ErrMissingPayload
+	}
+	bigjs := make(map[int]*big.Int, len(decShares))
+	for _, ds := range decShares {
+		if err := CheckDecryptionShare(g, expected, commitments, ciphertext, ds); err != nil {
+			return nil, err
+		}
+		if bigjs[ds.Position] != nil {
+			return nil, &ShareVerificationError{Position: ds.Position, Reason: ErrDuplicateShare}
+		}
+		bigjs[ds.Position] = big.NewInt(int64(ds.Position))
+	}
+	if len(decShares) < len(commitments) {
+		return nil, fmt.Errorf("%w: %d of threshold %d", ErrNotEnoughShares, len(decShares), len(commitments))
+	}
+
+	// ∑ λ_i·D_i = (∑ λ_i·p(i))·R = s·R = r·C_0
+	points, lambdas := make([]Element, len(decShares)), make([]*big.Int, len(decShares))
+	for i, ds := range decShares {
+		points[i], lambdas[i] = ds.D, lagrangeCoefficient(ds.Position, bigjs, g.Order())
+	}
+	p := ciphertext.Payload
+	aead, err := p.Algorithm.new(elgamalKey(g, expected, commitments[0], ciphertext.R, p.Algorithm, multiScalarMult(g, points, lambdas)))
+	if err != nil {
+		return nil, err
+	}
+	if len(p.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("%w: invalid nonce", ErrDecryptionFailed)
+	}
+	plaintext, err := aead.Open(nil, p.Nonce, p.Ciphertext, associatedData)
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+	return plaintext, nil
+}
+
+// decryptionShareTranscript returns the transcript of the proof of the decryption share at the position, in the session
+// of the sharing, to which the ciphertext and the position are appended.
+func decryptionShareTranscript(g Group, session *Session, ciphertext *ElGamalCiphertext, position int) *Transcript {
+	t := session.transcript(elgamalProtocol)
+	t.AppendElement(g, "R", ciphertext.R)
+	p := ciphertext.Payload
+	t.AppendMessage("aead", []byte(p.Algorithm.String()))
+	t.AppendMessage("nonce", p.Nonce)
+	t.AppendMessage("ciphertext", p.Ciphertext)
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(position))
+	t.AppendMessage("position", b[:])
+	return t
+}
+
+// elgamalKey derives the AEAD key from r·C_0 with HKDF-SHA3-256, the info binds the session, the group, the algorithm,
+// C_0 and R.
+func elgamalKey(g Group, session *Session, publicKey, R Element, algorithm AEADAlgorithm, rC0 Element) []byte {
+	t := session.transcript(elgamalKeyProtocol)
+	t.AppendMessage("group", []byte(g.Name()))
+	t.AppendMessage("aead", []byte(algorithm.String()))
+	t.AppendElement(g, "public-key", publicKey)
+	t.AppendElement(g, "R", R)
+	key := make([]byte, aeadKeyLen)
+	if _, err := io.ReadFull(hkdf.New(sha3.New256, g.Encode(rC0), nil, t.buf), key); err != nil {
+		panic("pvss: HKDF failed: " + err.Error())
+	}
+	return key
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pvss
+
+import (
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+// elgamalSharing deals a key on a polynomial known to the test, and returns the box with the scalar shares of the holders.
+func elgamalSharing(t *testing.T, g Group, threshold, n int) (*DistributionSharesBox, []*big.Int) {
+	dealers, pks := genDealers(g, n+1)
+	poly, err := InitPolynomial(threshold-1, g.Order())
+	require.NoError(t, err)
+	sharebox, err := dealers[0].DistributePolynomial(new(big.Int), poly, pks[1:], testSession(g, pks[1:], threshold))
+	require.NoError(t, err)
+	shares := make([]*big.Int, n)
+	for i := range shares {
+		shares[i] = poly.GetValue(big.NewInt(int64(i+1)), g.Order())
+	}
+	return sharebox, shares
+}
+
+func TestElGamal(t *testing.T) {
+	threshold, n := 3, 5
+	message, ad := []byte("a message to the committee"), []byte("header")
+	for _, g := range testGroups {
+		sharebox, shares := elgamalSharing(t, g, threshold, n)
+		ciphertext, err := EncryptElGamal(g, sharebox.Session, sharebox.Commitments[0], message, ad, ChaCha20Poly1305)
+		require.NoError(t, err)
+
+		decShares := make([]*DecryptionShare, n)
+		for i, share := range shares {
+			decShares[i], err = PartialDecrypt(g, sharebox.Session, ciphertext, i+1, share)
+			require.NoError(t, err)
+			require.NoError(t, CheckDecryptionShare(g, sharebox.Session, sharebox.Commitments, ciphertext, decShares[i]))
+		}
+		for _, subset := range [][]*DecryptionShare{decShares[:threshold], decShares[n-threshold:], decShares} {
+			plaintext, err := DecryptElGamal(g, sharebox.Session, sharebox.Commitments, ciphertext, subset, ad)
+			require.NoError(t, err, g.Name())
+			require.Equal(t, message, plaintext)
+		}
+		_, err = DecryptElGamal(g, sharebox.Session, sharebox.Commitments, ciphertext, decShares[:threshold], []byte("other"))
+		require.ErrorIs(t, err, ErrDecryptionFailed)
+	}
+}
+
+func TestElGamal_Rejects(t *testing.T) {
+	threshold, n := 3, 5
+	g := Secp256k1()
+	sharebox, shares := elgamalSharing(t, g, threshold, n)
+	commitments, session := sharebox.Commitments, sharebox.Session
+	_, err := EncryptElGamal(g, sharebox.Session, g.Identity(), nil, nil, AES256GCM)
+	require.ErrorIs(t, err, ErrInvalidPublicKey)
+	ciphertext, err := EncryptElGamal(g, session, commitments[0], []byte("message"), nil, AES256GCM)
+	require.NoError(t, err)
+	decShares := make([]*DecryptionShare, n)
+	for i, share := range shares {
+		decShares[i], err = PartialDecrypt(g, sharebox.Session, ciphertext, i+1, share)
+		require.NoError(t, err)
+	}
+	requireShareError := func(err error, position int, reason error) {
+		var shareErr *ShareVerificationError
+		require.ErrorAs(t, err, &shareErr)
+		require.Equal(t, position, shareErr.Position)
+		require.ErrorIs(t, err, reason)
+	}
+
+	_, err = DecryptElGamal(g, session, commitments, ciphertext, decShares[:threshold-1], nil)
+	require.ErrorIs(t, err, ErrNotEnoughShares)
+	_, err = DecryptElGamal(g, session, commitments, ciphertext, []*DecryptionShare{decShares[0], decShares[1], decShares[1]}, nil)
+	requireShareError(err, 2, ErrDuplicateShare)
+
+	// a wrong D_i, a share of another position, and a proof of another ciphertext
+	forged := *decShares[2]
+	forged.D = g.Add(forged.D, g.Generator())
+	_, err = DecryptElGamal(g, session, commitments, ciphertext, []*DecryptionShare{decShares[0], decShares[1], &forged}, nil)
+	requireShareError(err, 3, ErrInvalidProof)
+	forged = *decShares[2]
+	forged.Position = 4
+	requireShareError(CheckDecryptionShare(g, session, commitments, ciphertext, &forged), 4, ErrInvalidProof)
+	other, err := EncryptElGamal(g, session, commitments[0], []byte("message"), nil, AES256GCM)
+	require.NoError(t, err)
+	requireShareError(CheckDecryptionShare(g, session, commitments, other, decShares[2]), 3, ErrInvalidProof)
+	wrong, err := PartialDecrypt(g, sharebox.Session, ciphertext, 3, new(big.Int).Add(shares[2], big.NewInt(1)))
+	require.NoError(t, err)
+	requireShareError(CheckDecryptionShare(g, session, commitments, ciphertext, wrong), 3, ErrInvalidProof)
+
+	// a proof of another session of the same commitments, or of a tampered ciphertext
+	otherSession := NewSession(g, session.ID, session.Epoch+1, nil, threshold)
+	requireShareError(CheckDecryptionShare(g, otherSession, commitments, ciphertext, decShares[2]), 3, ErrInvalidProof)
+	_, err = DecryptElGamal(g, otherSession, commitments, ciphertext, decShares[:threshold], nil)
+	requireShareError(err, 1, ErrInvalidProof)
+	tampered := *ciphertext
+	tampered.Payload = &Payload{Algorithm: ciphertext.Payload.Algorithm, Nonce: ciphertext.Payload.Nonce,
+		Ciphertext: append([]byte{ciphertext.Payload.Ciphertext[0] ^ 1}, ciphertext.Payload.Ciphertext[1:]...)}
+	requireShareError(CheckDecryptionShare(g, session, commitments, &tampered, decShares[2]), 3, ErrInvalidProof)
+}
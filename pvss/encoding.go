@@ -0,0 +1,574 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pvss
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// The binary wire format.
+//
+// Every top level object starts with a header: the format version, the object kind and the name of the group,
+// which is prefixed by its length as 1 byte. Elements are encoded by Group.Encode, scalars are big-endian with
+// the byte length of the group order, integers are 4 bytes big-endian and variable-length fields are prefixed
+// by their length as 4 bytes big-endian.
+//
+//	Share:                 header | position | PK | S | challenge | response | commitments
+//	DecryptedShare:        header | position | PK | S | Y | challenge | response | commitments
+//	commitments:           0, or 1 | A1 | A2
+//	DistributionSharesBox: header | Session | len(Commitments) | Commitments... | len(ShareCommitments) | ShareCommitments... |
+//	                       len(Shares) | (len(share) | share)... | U | len(Payload) | Payload
+//	Payload:               algorithm | len(Nonce) | Nonce | len(Ciphertext) | Ciphertext
+//	Session:               len(ID) | ID | epoch | len(ParticipantsHash) | ParticipantsHash | threshold
+//
+// U is big-endian with the fixed length of 32 bytes, the epoch is 8 bytes big-endian, the algorithm of the payload is 1 byte,
+// and a box without payload has an empty one. The commitments A1,A2 of a proof are optional, they start with 1 byte telling
+// whether they are present.
+//
+// The objects are encoded with the current version, and the objects of the older versions are still decoded:
+// the boxes of version 1 end with U, they have no payload, the shares of the versions 1 and 2 end with the response,
+// they have no commitments, and the boxes of the versions 1 to 3 have len(U) | U, where U is big-endian without leading
+// zeros, which is still the only accepted encoding of U in these versions.
+const (
+	encodingVersion byte = 4
+
+	// payloadVersion is the first version whose boxes end with the payload
+	payloadVersion byte = 2
+	// commitmentsVersion is the first version whose shares end with the commitments of their proofs
+	commitmentsVersion byte = 3
+	// fixedUVersion is the first version whose U has the fixed length secretLength
+	fixedUVersion byte = 4
+
+	kindShare                 byte = 1
+	kindDecryptedShare        byte = 2
+	kindDistributionSharesBox byte = 3
+
+	headerLen = 3 // without the group name
+	uint32Len = 4
+	uint64Len = 8
+)
+
+var (
+	ErrInvalidEncoding    = errors.New("invalid encoding")
+	ErrUnsupportedVersion = errors.New("unsupported encoding version")
+	ErrTrailingBytes      = errors.New("trailing bytes after encoding")
+	ErrInvalidPoint       = errors.New("invalid curve point")
+	ErrInvalidScalar      = errors.New("scalar out of range")
+	ErrInvalidPosition    = errors.New("invalid share position")
+)
+
+// MarshalBinary encodes the share, including its DLEQ proof, into the binary wire format.
+func (s *Share) MarshalBinary() ([]byte, error) {
+	e := newEncoder(kindShare, s.Group)
+	e.putPosition(s.Position)
+	e.putElement(s.PK)
+	e.putElement(s.S)
+	e.putScalar(s.challenge)
+	e.putScalar(s.response)
+	e.putCommitments(s.a1, s.a2)
+	return e.bytes()
+}
+
+// UnmarshalBinary decodes a share encoded by MarshalBinary.
+func (s *Share) UnmarshalBinary(data []byte) error {
+	d, err := newDecoder(data, kindShare)
+	if err != nil {
+		return err
+	}
+	share := Share{
+		Group:     d.group,
+		Position:  d.position(),
+		PK:        d.element(),
+		S:         d.element(),
+		challenge: d.scalar(),
+		response:  d.scalar(),
+	}
+	share.a1, share.a2 = d.commitments()
+	if err := d.finish(); err != nil {
+		return err
+	}
+	*s = share
+	return nil
+}
+
+// MarshalBinary encodes the decrypted share, including its DLEQ proof, into the binary wire format.
+func (ds *DecryptedShare) MarshalBinary() ([]byte, error) {
+	e := newEncoder(kindDecryptedShare, ds.Group)
+	e.putPosition(ds.Position)
+	e.putElement(ds.PK)
+	e.putElement(ds.S)
+	e.putElement(ds.Y)
+	e.putScalar(ds.challenge)
+	e.putScalar(ds.response)
+	e.putCommitments(ds.a1, ds.a2)
+	return e.bytes()
+}
+
+// UnmarshalBinary decodes a decrypted share encoded by MarshalBinary.
+func (ds *DecryptedShare) UnmarshalBinary(data []byte) error {
+	d, err := newDecoder(data, kindDecryptedShare)
+	if err != nil {
+		return err
+	}
+	decShare := DecryptedShare{
+		Group:     d.group,
+		Position:  d.position(),
+		PK:        d.element(),
+		S:         d.element(),
+		Y:         d.element(),
+		challenge: d.scalar(),
+		response:  d.scalar(),
+	}
+	decShare.a1, decShare.a2 = d.commitments()
+	if err := d.finish(); err != nil {
+		return err
+	}
+	*ds = decShare
+	return nil
+}
+
+// MarshalBinary encodes the commitments, the encrypted shares with their proofs and U into the binary wire format.
+func (b *DistributionSharesBox) MarshalBinary() ([]byte, error) {
+	e := newEncoder(kindDistributionSharesBox, b.Group)
+	e.putSession(b.Session)
+	e.putUint32(uint32(len(b.Commitments)))
+	for _, c := range b.Commitments {
+		e.putElement(c)
+	}
+	e.putUint32(uint32(len(b.ShareCommitments)))
+	for _, v := range b.ShareCommitments {
+		e.putElement(v)
+	}
+	e.putUint32(uint32(len(b.Shares)))
+	for _, s := range b.Shares {
+		if s == nil {
+			e.fail(errors.New("nil share"))
+			break
+		}
+		if !sameGroup(s.Group, b.Group) {
+			e.fail(ErrGroupMismatch)
+			break
+		}
+		data, err := s.MarshalBinary()
+		if err != nil {
+			e.fail(err)
+			break
+		}
+		e.putBytes(data)
+	}
+	e.putU(b.U)
+	e.putPayload(b.Payload)
+	return e.bytes()
+}
+
+// UnmarshalBinary decodes a distribution shares box encoded by MarshalBinary.
+// Every element is checked to be in the group and every scalar to be less than the group order.
+func (b *DistributionSharesBox) UnmarshalBinary(data []byte) error {
+	d, err := newDecoder(data, kindDistributionSharesBox)
+	if err != nil {
+		return err
+	}
+	session := d.session()
+	commitments := make([]Element, d.count(d.group.ElementLen()))
+	for i := range commitments {
+		commitments[i] = d.element()
+	}
+	var shareCommitments []Element
+	if count := d.count(d.group.ElementLen()); count != 0 {
+		shareCommitments = make([]Element, count)
+		for i := range shareCommitments {
+			shareCommitments[i] = d.element()
+		}
+	}
+	// each share takes at least its length prefix and its header
+	shares := make([]*Share, d.count(uint32Len+headerLen))
+	for i := range shares {
+		shareData := d.bytes()
+		if d.err != nil {
+			break
+		}
+		shares[i] = new(Share)
+		if err := shares[i].UnmarshalBinary(shareData); err != nil {
+			return err
+		}
+		if !sameGroup(shares[i].Group, d.group) {
+			return ErrGroupMismatch
+		}
+	}
+	u := d.u()
+	var payload *Payload
+	if d.version >= payloadVersion {
+		payload = d.payload()
+	}
+	if err := d.finish(); err != nil {
+		return err
+	}
+	*b = DistributionSharesBox{
+		Group:            d.group,
+		Session:          session,
+		Commitments:      commitments,
+		ShareCommitments: shareCommitments,
+		Shares:           shares,
+		U:                u,
+		Payload:          payload,
+	}
+	return nil
+}
+
+// marshalScalar returns k as big-endian bytes with the length of the group order, k must be in [0, n).
+func marshalScalar(g Group, k *big.Int) ([]byte, error) {
+	if k == nil || k.Sign() < 0 || k.Cmp(g.Order()) >= 0 {
+		return nil, ErrInvalidScalar
+	}
+	return k.FillBytes(make([]byte, scalarLength(g))), nil
+}
+
+// unmarshalScalar parses a big-endian scalar, and makes sure it's less than the group order.
+func unmarshalScalar(g Group, b []byte) (*big.Int, error) {
+	if len(b) != scalarLength(g) {
+		return nil, ErrInvalidScalar
+	}
+	k := new(big.Int).SetBytes(b)
+	if k.Cmp(g.Order()) >= 0 {
+		return nil, ErrInvalidScalar
+	}
+	return k, nil
+}
+
+// marshalU returns U as big-endian bytes with the fixed length secretLength.
+func marshalU(u *big.Int) ([]byte, error) {
+	if u == nil || u.Sign() < 0 || u.BitLen() > 8*secretLength {
+		return nil, fmt.Errorf("%w: invalid U", ErrInvalidEncoding)
+	}
+	return u.FillBytes(make([]byte, secretLength)), nil
+}
+
+// unmarshalU parses U, which has the fixed length secretLength, or if fixed is false, which is big-endian without leading
+// zeros and at most secretLength bytes, the encoding of U in the older versions.
+func unmarshalU(b []byte, fixed bool) (*big.Int, error) {
+	if fixed && len(b) != secretLength || !fixed && (len(b) > secretLength || len(b) != 0 && b[0] == 0) {
+		return nil, fmt.Errorf("%w: invalid U", ErrInvalidEncoding)
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// encoder appends fields to a buffer, the first error is kept and reported by bytes.
+type encoder struct {
+	group Group
+	buf   []byte
+	err   error
+}
+
+func newEncoder(kind byte, g Group) *encoder {
+	e := &encoder{group: g, buf: []byte{encodingVersion, kind}}
+	if g == nil || len(g.Name()) > 255 {
+		e.fail(ErrUnknownGroup)
+		return e
+	}
+	e.buf = append(e.buf, byte(len(g.Name())))
+	e.buf = append(e.buf, g.Name()...)
+	return e
+}
+
+func (e *encoder) fail(err error) {
+	if e.err == nil {
+		e.err = err
+	}
+}
+
+func (e *encoder) putUint32(v uint32) {
+	var b [uint32Len]byte
+	binary.BigEndian.PutUint32(b[:], v)
+	e.buf = append(e.buf, b[:]...)
+}
+
+func (e *encoder) putUint64(v uint64) {
+	var b [uint64Len]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	e.buf = append(e.buf, b[:]...)
+}
+
+func (e *encoder) putSession(s *Session) {
+	if s == nil || s.Threshold < 1 || int64(s.Threshold) > int64(^uint32(0)) {
+		e.fail(fmt.Errorf("%w: invalid session", ErrInvalidEncoding))
+		return
+	}
+	e.putBytes(s.ID)
+	e.putUint64(s.Epoch)
+	e.putBytes(s.ParticipantsHash)
+	e.putUint32(uint32(s.Threshold))
+}
+
+func (e *encoder) putPayload(p *Payload) {
+	if p == nil {
+		e.putBytes(nil)
+		return
+	}
+	pe := &encoder{group: e.group, buf: []byte{byte(p.Algorithm)}}
+	pe.putBytes(p.Nonce)
+	pe.putBytes(p.Ciphertext)
+	e.putBytes(pe.buf)
+}
+
+// putU puts U with the fixed length secretLength.
+func (e *encoder) putU(u *big.Int) {
+	b, err := marshalU(u)
+	if err != nil {
+		e.fail(err)
+		return
+	}
+	e.buf = append(e.buf, b...)
+}
+
+func (e *encoder) putPosition(position int) {
+	if position < 1 || int64(position) > int64(^uint32(0)) {
+		e.fail(ErrInvalidPosition)
+		return
+	}
+	e.putUint32(uint32(position))
+}
+
+func (e *encoder) putElement(a Element) {
+	if e.err != nil {
+		return
+	}
+	if a == nil {
+		e.fail(ErrInvalidPoint)
+		return
+	}
+	e.buf = append(e.buf, e.group.Encode(a)...)
+}
+
+// putCommitments puts the optional commitments of a proof, which are either both present or both missing.
+func (e *encoder) putCommitments(a1, a2 Element) {
+	if a1 == nil && a2 == nil {
+		e.buf = append(e.buf, 0)
+		return
+	}
+	e.buf = append(e.buf, 1)
+	e.putElement(a1)
+	e.putElement(a2)
+}
+
+func (e *encoder) putScalar(k *big.Int) {
+	if e.err != nil {
+		return
+	}
+	b, err := marshalScalar(e.group, k)
+	if err != nil {
+		e.fail(err)
+		return
+	}
+	e.buf = append(e.buf, b...)
+}
+
+func (e *encoder) putBytes(data []byte) {
+	e.putUint32(uint32(len(data)))
+	e.buf = append(e.buf, data...)
+}
+
+func (e *encoder) bytes() ([]byte, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.buf, nil
+}
+
+// decoder consumes fields from an encoding, the first error is kept and reported by finish.
+// After an error every read returns a zero value.
+type decoder struct {
+	group   Group
+	version byte
+	data    []byte
+	err     error
+}
+
+func newDecoder(data []byte, kind byte) (*decoder, error) {
+	if len(data) < headerLen {
+		return nil, ErrInvalidEncoding
+	}
+	if data[0] < 1 || data[0] > encodingVersion {
+		return nil, ErrUnsupportedVersion
+	}
+	if data[1] != kind {
+		return nil, fmt.Errorf("%w: unexpected object kind %d", ErrInvalidEncoding, data[1])
+	}
+	nameLen := int(data[2])
+	if len(data) < headerLen+nameLen {
+		return nil, ErrInvalidEncoding
+	}
+	g, err := LookupGroup(string(data[headerLen : headerLen+nameLen]))
+	if err != nil {
+		return nil, err
+	}
+	return &decoder{group: g, version: data[0], data: data[headerLen+nameLen:]}, nil
+}
+
+func (d *decoder) fail(err error) {
+	if d.err == nil {
+		d.err = err
+	}
+}
+
+func (d *decoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || len(d.data) < n {
+		d.fail(ErrInvalidEncoding)
+		return nil
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b
+}
+
+func (d *decoder) uint32() uint32 {
+	b := d.next(uint32Len)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(b)
+}
+
+func (d *decoder) uint64() uint64 {
+	b := d.next(uint64Len)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint64(b)
+}
+
+func (d *decoder) session() *Session {
+	s := &Session{
+		ID:               append([]byte(nil), d.bytes()...),
+		Epoch:            d.uint64(),
+		ParticipantsHash: append([]byte(nil), d.bytes()...),
+		Threshold:        int(d.uint32()),
+	}
+	if d.err == nil && s.Threshold == 0 {
+		d.fail(fmt.Errorf("%w: invalid session", ErrInvalidEncoding))
+	}
+	return s
+}
+
+// payload reads the payload of a box, nil if it's empty.
+func (d *decoder) payload() *Payload {
+	data := d.bytes()
+	if d.err != nil || len(data) == 0 {
+		return nil
+	}
+	pd := &decoder{group: d.group, data: data}
+	p := &Payload{Algorithm: AEADAlgorithm(pd.next(1)[0])}
+	p.Nonce = append([]byte(nil), pd.bytes()...)
+	p.Ciphertext = append([]byte(nil), pd.bytes()...)
+	if err := pd.finish(); err != nil || !p.Algorithm.supported() {
+		d.fail(ErrInvalidEncoding)
+		return nil
+	}
+	return p
+}
+
+// count reads an element count, and makes sure the remaining data can hold that many elements
+// of at least minLen bytes each before anything is allocated for them.
+func (d *decoder) count(minLen int) int {
+	n := d.uint32()
+	if d.err != nil {
+		return 0
+	}
+	if uint64(n)*uint64(minLen) > uint64(len(d.data)) {
+		d.fail(ErrInvalidEncoding)
+		return 0
+	}
+	return int(n)
+}
+
+func (d *decoder) position() int {
+	position := d.uint32()
+	if d.err == nil && position == 0 {
+		d.fail(ErrInvalidPosition)
+	}
+	return int(position)
+}
+
+func (d *decoder) element() Element {
+	b := d.next(d.group.ElementLen())
+	if b == nil {
+		return nil
+	}
+	a, err := d.group.Decode(b)
+	if err != nil {
+		d.fail(err)
+	}
+	return a
+}
+
+// commitments reads the optional commitments of a proof, which the encodings older than commitmentsVersion don't have.
+func (d *decoder) commitments() (a1, a2 Element) {
+	if d.version < commitmentsVersion {
+		return nil, nil
+	}
+	present := d.next(1)
+	if present == nil || present[0] == 0 {
+		return nil, nil
+	}
+	if present[0] != 1 {
+		d.fail(ErrInvalidEncoding)
+		return nil, nil
+	}
+	return d.element(), d.element()
+}
+
+// u reads U, with the fixed length secretLength, or in the versions older than fixedUVersion, prefixed by its length.
+func (d *decoder) u() *big.Int {
+	var b []byte
+	if d.version >= fixedUVersion {
+		b = d.next(secretLength)
+	} else {
+		b = d.bytes()
+	}
+	if d.err != nil {
+		return nil
+	}
+	u, err := unmarshalU(b, d.version >= fixedUVersion)
+	if err != nil {
+		d.fail(err)
+	}
+	return u
+}
+
+func (d *decoder) scalar() *big.Int {
+	b := d.next(scalarLength(d.group))
+	if b == nil {
+		return nil
+	}
+	k, err := unmarshalScalar(d.group, b)
+	if err != nil {
+		d.fail(err)
+	}
+	return k
+}
+
+func (d *decoder) bytes() []byte {
+	n := d.uint32()
+	return d.next(int(n))
+}
+
+func (d *decoder) finish() error {
+	if d.err != nil {
+		return d.err
+	}
+	if len(d.data) != 0 {
+		return ErrTrailingBytes
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pvss
+
+import (
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+func TestDistributionSharesBox_MarshalBinary(t *testing.T) {
+	threshold, n := 3, 4
+	g := Secp256k1()
+	dealers, pks := genDealers(g, n+1)
+	secret := new(big.Int).SetBytes([]byte("Hello, go-pvss under ECC"))
+	sharebox, err := dealers[0].DistributeSecret(secret, pks[1:], testSession(g, pks[1:], threshold))
+	require.NoError(t, err, "DistributeSecret")
+
+	data, err := sharebox.MarshalBinary()
+	require.NoError(t, err, "MarshalBinary")
+
+	decoded := new(DistributionSharesBox)
+	require.NoError(t, decoded.UnmarshalBinary(data), "UnmarshalBinary")
+	require.Equal(t, threshold, len(decoded.Commitments))
+	require.Equal(t, n, len(decoded.Shares))
+	require.Equal(t, 0, decoded.U.Cmp(sharebox.U))
+	require.True(t, VerifyDistributionShares(g, sharebox.Session, decoded), "VerifyDistributionShares")
+
+	again, err := decoded.MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, data, again)
+
+	// U has the fixed length of the mask, whatever its value
+	small := *sharebox
+	small.U = big.NewInt(1)
+	smallData, err := small.MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, len(data), len(smallData))
+	small.U = new(big.Int).Lsh(big.NewInt(1), 256)
+	_, err = small.MarshalBinary()
+	require.ErrorIs(t, err, ErrInvalidEncoding)
+
+	// the decoded box is still usable by the participants
+	decShares := make([]*DecryptedShare, 0, n)
+	for i, d := range dealers[1:] {
+		decShare, err := d.ExtractSecretShare(decoded)
+		require.NoError(t, err, "ExtractSecretShare", i)
+		decShares = append(decShares, decShare)
+	}
+	s := ReconstructSecret(g, decShares[:threshold], decoded.U)
+	require.Equal(t, 0, s.Cmp(secret))
+}
+
+func TestDecryptedShare_MarshalBinary(t *testing.T) {
+	g := Secp256k1()
+	dealers, pks := genDealers(g, 4)
+	secret := big.NewInt(42)
+	sharebox, err := dealers[0].DistributeSecret(secret, pks[1:], testSession(g, pks[1:], 2))
+	require.NoError(t, err)
+	decShare, err := dealers[1].ExtractSecretShare(sharebox)
+	require.NoError(t, err)
+
+	data, err := decShare.MarshalBinary()
+	require.NoError(t, err)
+	decoded := new(DecryptedShare)
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	require.Equal(t, decShare.Position, decoded.Position)
+	require.True(t, VerifyDecryptedShare(g, sharebox.Session, decoded))
+
+	// a share can not be decoded as a decrypted share
+	shareData, err := sharebox.Shares[0].MarshalBinary()
+	require.NoError(t, err)
+	require.ErrorIs(t, decoded.UnmarshalBinary(shareData), ErrInvalidEncoding)
+}
+
+func TestUnmarshalBinary_Rejects(t *testing.T) {
+	g := Secp256k1()
+	dealers, pks := genDealers(g, 4)
+	sharebox, err := dealers[0].DistributeSecret(big.NewInt(42), pks[1:], testSession(g, pks[1:], 2))
+	require.NoError(t, err)
+	data, err := sharebox.Shares[0].MarshalBinary()
+	require.NoError(t, err)
+	// header | position | PK | S | challenge | response
+	bodyOffset := headerLen + len(g.Name())
+	sOffset := bodyOffset + uint32Len + g.ElementLen()
+	challengeOffset := sOffset + g.ElementLen()
+
+	share := new(Share)
+	require.NoError(t, share.UnmarshalBinary(data))
+
+	trailing := append(append([]byte{}, data...), 0)
+	require.ErrorIs(t, share.UnmarshalBinary(trailing), ErrTrailingBytes)
+	require.ErrorIs(t, share.UnmarshalBinary(data[:len(data)-1]), ErrInvalidEncoding)
+
+	version := append([]byte{}, data...)
+	version[0] = encodingVersion + 1
+	require.ErrorIs(t, share.UnmarshalBinary(version), ErrUnsupportedVersion)
+
+	unknownGroup := append([]byte{}, data...)
+	unknownGroup[headerLen] ^= 0xff
+	require.ErrorIs(t, share.UnmarshalBinary(unknownGroup), ErrUnknownGroup)
+
+	position := append([]byte{}, data...)
+	copy(position[bodyOffset:], []byte{0, 0, 0, 0})
+	require.ErrorIs(t, share.UnmarshalBinary(position), ErrInvalidPosition)
+
+	// x = 5 is not the x-coordinate of any point on secp256k1
+	offCurve := append([]byte{}, data...)
+	copy(offCurve[sOffset:], append([]byte{2}, new(big.Int).SetInt64(5).FillBytes(make([]byte, 32))...))
+	require.ErrorIs(t, share.UnmarshalBinary(offCurve), ErrInvalidPoint)
+
+	outOfRange := append([]byte{}, data...)
+	copy(outOfRange[challengeOffset:], g.Order().Bytes())
+	require.ErrorIs(t, share.UnmarshalBinary(outOfRange), ErrInvalidScalar)
+
+	boxData, err := sharebox.MarshalBinary()
+	require.NoError(t, err)
+	box := new(DistributionSharesBox)
+	require.ErrorIs(t, box.UnmarshalBinary(append(boxData, 0)), ErrTrailingBytes)
+	huge := append([]byte{}, boxData...)
+	copy(huge[bodyOffset:], []byte{0xff, 0xff, 0xff, 0xff})
+	require.ErrorIs(t, box.UnmarshalBinary(huge), ErrInvalidEncoding)
+}
+
+func TestDistributionSharesBox_UnmarshalBinaryVersion1(t *testing.T) {
+	g := Secp256k1()
+	dealers, pks := genDealers(g, 4)
+	sharebox, err := dealers[0].DistributeSecret(big.NewInt(42), pks[1:], testSession(g, pks[1:], 2))
+	require.NoError(t, err)
+	data, err := sharebox.MarshalBinary()
+	require.NoError(t, err)
+
+	// a box of version 1 ends with U prefixed by its length, without the payload
+	legacy := func(version byte, u []byte) []byte {
+		old := append([]byte{}, data[:len(data)-secretLength-uint32Len]...)
+		old[0] = version
+		old = append(old, 0, 0, 0, byte(len(u)))
+		return append(old, u...)
+	}
+	v1 := legacy(1, sharebox.U.Bytes())
+	decoded := new(DistributionSharesBox)
+	require.NoError(t, decoded.UnmarshalBinary(v1))
+	require.Nil(t, decoded.Payload)
+	require.Equal(t, 0, decoded.U.Cmp(sharebox.U))
+	require.True(t, VerifyDistributionShares(g, sharebox.Session, decoded))
+
+	again, err := decoded.MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, data, again)
+
+	// and a box of version 3 has the payload after it
+	decoded = new(DistributionSharesBox)
+	require.NoError(t, decoded.UnmarshalBinary(append(legacy(3, sharebox.U.Bytes()), 0, 0, 0, 0)))
+	require.Equal(t, 0, decoded.U.Cmp(sharebox.U))
+
+	// the payload field is not part of version 1
+	require.ErrorIs(t, decoded.UnmarshalBinary(append(v1, 0, 0, 0, 0)), ErrTrailingBytes)
+	// U has a single encoding in the older versions too: no leading zero, at most 32 bytes
+	require.ErrorIs(t, decoded.UnmarshalBinary(legacy(1, append([]byte{0}, sharebox.U.Bytes()...))), ErrInvalidEncoding)
+	require.ErrorIs(t, decoded.UnmarshalBinary(legacy(1, append([]byte{1}, sharebox.U.FillBytes(make([]byte, secretLength))...))), ErrInvalidEncoding)
+}
+
+func TestUnmarshalBinary_Version2Shares(t *testing.T) {
+	g := Secp256k1()
+	sharebox, decShares := dealAndDecrypt(t, g, 2, 3, big.NewInt(42), false)
+	commitmentsLen := 1 + 2*g.ElementLen()
+
+	data, err := sharebox.Shares[0].MarshalBinary()
+	require.NoError(t, err)
+	share := new(Share)
+	require.NoError(t, share.UnmarshalBinary(data))
+	require.True(t, g.Equal(sharebox.Shares[0].a1, share.a1))
+	require.True(t, g.Equal(sharebox.Shares[0].a2, share.a2))
+
+	// a share of version 2 ends with the response
+	v2 := append([]byte{}, data[:len(data)-commitmentsLen]...)
+	v2[0] = 2
+	require.NoError(t, share.UnmarshalBinary(v2))
+	require.Nil(t, share.a1)
+	require.Nil(t, share.a2)
+	require.ErrorIs(t, share.UnmarshalBinary(append(v2, 0)), ErrTrailingBytes)
+	again, err := share.MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, append(append([]byte{encodingVersion}, v2[1:]...), 0), again)
+
+	data, err = decShares[0].MarshalBinary()
+	require.NoError(t, err)
+	v2 = append([]byte{}, data[:len(data)-commitmentsLen]...)
+	v2[0] = 2
+	decShare := new(DecryptedShare)
+	require.NoError(t, decShare.UnmarshalBinary(v2))
+	require.Nil(t, decShare.a1)
+	require.True(t, VerifyDecryptedShare(g, sharebox.Session, decShare))
+
+	// the commitments are both present or both missing
+	invalid := append([]byte{}, data...)
+	invalid[len(data)-commitmentsLen] = 2
+	require.ErrorIs(t, decShare.UnmarshalBinary(invalid), ErrInvalidEncoding)
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pvss
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// The reasons of a ShareVerificationError, besides ErrInvalidPosition and ErrGroupMismatch.
+var (
+	ErrMissingShare = errors.New("missing share")
+	ErrMissingProof = errors.New("missing share or proof values")
+	ErrInvalidProof = errors.New("invalid DLEQ proof")
+)
+
+// ErrInvalidBox is reported for a distribution shares box which is malformed as a whole.
+var ErrInvalidBox = errors.New("invalid distribution shares box")
+
+// ShareVerificationError identifies a share which fails the verification, so that the dealer or the participant can be accused.
+type ShareVerificationError struct {
+	Position int     // position of the share, 0 if unknown
+	PK       Element // public key of the share's owner, nil if unknown
+	Reason   error
+}
+
+func (e *ShareVerificationError) Error() string {
+	return fmt.Sprintf("share %d: %v", e.Position, e.Reason)
+}
+
+func (e *ShareVerificationError) Unwrap() error {
+	return e.Reason
+}
+
+// ShareVerificationErrors reports all the shares which fail the verification, in the order of their positions.
+type ShareVerificationErrors []*ShareVerificationError
+
+func (errs ShareVerificationErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d shares failed the verification: %s", len(errs), strings.Join(msgs, "; "))
+}
+
+// Positions returns the positions of the faulty shares.
+func (errs ShareVerificationErrors) Positions() []int {
+	positions := make([]int, len(errs))
+	for i, err := range errs {
+		positions[i] = err.Position
+	}
+	return positions
+}
+
+// Is reports whether any of the errors matches target, so errors.Is finds the reasons of the faulty shares.
+func (errs ShareVerificationErrors) Is(target error) bool {
+	for _, err := range errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// compactShareErrors returns the errors which are not nil, in the same order.
+func compactShareErrors(errs []*ShareVerificationError) ShareVerificationErrors {
+	var compact ShareVerificationErrors
+	for _, err := range errs {
+		if err != nil {
+			compact = append(compact, err)
+		}
+	}
+	return compact
+}
@@ -0,0 +1,182 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pvss
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stars-labs/go-pvss/crypto/scalar"
+	"io"
+	"math/big"
+	"sync"
+)
+
+// Element is an element of a Group.
+// An element is only meaningful to the group which created it, and is never modified after creation.
+type Element interface{}
+
+// Group is a cyclic group of prime order n, in which the PVSS scheme runs.
+//
+// Scalars are integers modulo n, represented as *big.Int, and every operation of the group reduces
+// its scalar argument modulo n first. The scheme needs two generators G and H, where no one knows
+// the discrete logarithm of H with respect to G.
+type Group interface {
+	// Name identifies the group, it's carried in the encodings of the PVSS transcripts.
+	Name() string
+	// Order returns the prime order n of the group.
+	Order() *big.Int
+	// Generator returns the generator G, which is used by the participants' keys.
+	Generator() Element
+	// SecondGenerator returns the generator H, which is used by the polynomial commitments.
+	SecondGenerator() Element
+	// Identity returns the identity element.
+	Identity() Element
+	// Add returns a + b.
+	Add(a, b Element) Element
+	// Neg returns -a.
+	Neg(a Element) Element
+	// ScalarMult returns k·a.
+	ScalarMult(a Element, k *big.Int) Element
+	// ScalarBaseMult returns k·G.
+	ScalarBaseMult(k *big.Int) Element
+	// Equal reports whether a and b are the same element.
+	Equal(a, b Element) bool
+	// ElementLen returns the length of an encoded element.
+	ElementLen() int
+	// Encode returns the canonical encoding of a.
+	Encode(a Element) []byte
+	// Decode parses the canonical encoding of an element,
+	// anything which is not an element of the group is rejected.
+	Decode(data []byte) (Element, error)
+	// HashToElement hashes msg to an element whose discrete logarithm is unknown,
+	// dst is the domain separation tag.
+	HashToElement(msg, dst []byte) Element
+}
+
+var (
+	groupsMu sync.RWMutex
+	groups   = make(map[string]Group)
+)
+
+// RegisterGroup makes a group available by its name, so that transcripts dealt on it can be decoded.
+// It panics if a group with the same name is already registered.
+func RegisterGroup(g Group) {
+	groupsMu.Lock()
+	defer groupsMu.Unlock()
+	if _, ok := groups[g.Name()]; ok {
+		panic("pvss: RegisterGroup called twice for group " + g.Name())
+	}
+	groups[g.Name()] = g
+}
+
+// LookupGroup returns the registered group of the given name.
+func LookupGroup(name string) (Group, error) {
+	groupsMu.RLock()
+	defer groupsMu.RUnlock()
+	g, ok := groups[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownGroup, name)
+	}
+	return g, nil
+}
+
+// secondGeneratorDeriver is implemented by the groups whose second generator is derived publicly by hashing to the group.
+type secondGeneratorDeriver interface {
+	deriveSecondGenerator() Element
+}
+
+// VerifySecondGenerator recomputes the second generator H of g from its public derivation,
+// and reports whether it's the one used by g, so that no one knows log_G(H).
+// It returns false for the groups without a public derivation of H.
+func VerifySecondGenerator(g Group) bool {
+	d, ok := g.(secondGeneratorDeriver)
+	if !ok {
+		return false
+	}
+	h := d.deriveSecondGenerator()
+	return !isIdentity(g, h) && g.Equal(h, g.SecondGenerator())
+}
+
+// multiScalarMulter is implemented by the groups with a faster multi-scalar multiplication than the sum of ScalarMult.
+type multiScalarMulter interface {
+	multiScalarMult(points []Element, scalars []*big.Int) Element
+}
+
+// multiScalarMult returns ∑ scalars[i]·points[i] on g, the points and the scalars must have the same length.
+// It's not constant time on every group, so it's only used on public values.
+func multiScalarMult(g Group, points []Element, scalars []*big.Int) Element {
+	if len(points) != len(scalars) {
+		panic("pvss: multiScalarMult with different numbers of points and scalars")
+	}
+	if m, ok := g.(multiScalarMulter); ok {
+		return m.multiScalarMult(points, scalars)
+	}
+	sum := g.Identity()
+	for i, p := range points {
+		sum = g.Add(sum, g.ScalarMult(p, scalars[i]))
+	}
+	return sum
+}
+
+var (
+	ErrUnknownGroup  = errors.New("unknown group")
+	ErrGroupMismatch = errors.New("group mismatch")
+)
+
+// sameGroup reports whether a and b are the same group.
+func sameGroup(a, b Group) bool {
+	return a != nil && b != nil && a.Name() == b.Name()
+}
+
+// isIdentity reports whether a is the identity element of g.
+func isIdentity(g Group, a Element) bool {
+	return g.Equal(a, g.Identity())
+}
+
+// scalarLength returns the length of an encoded scalar of g.
+func scalarLength(g Group) int {
+	return (g.Order().BitLen() + 7) / 8
+}
+
+var moduli sync.Map // the *scalar.Modulus of the orders, by their hex strings
+
+// modulusOf returns the modulus of the constant-time scalars modulo the order n, in which the secret scalars of the scheme,
+// the polynomial coefficients, the private keys and the nonces, are computed.
+func modulusOf(n *big.Int) *scalar.Modulus {
+	key := n.Text(16)
+	if m, ok := moduli.Load(key); ok {
+		return m.(*scalar.Modulus)
+	}
+	m, err := scalar.NewModulus(n)
+	if err != nil {
+		panic("pvss: the order of a group must be an odd prime")
+	}
+	moduli.Store(key, m)
+	return m
+}
+
+// OrderModulus returns the modulus of the constant-time scalars of g, which is cached per group order, see modulusOf.
+func OrderModulus(g Group) *scalar.Modulus {
+	return modulusOf(g.Order())
+}
+
+// randomScalar returns a random scalar in [1, n).
+func randomScalar(g Group, random io.Reader) (*scalar.Scalar, error) {
+	return OrderModulus(g).RandomNonZero(random)
+}
+
+// GenerateKey generates a participant's key pair on g: a private key x in [1, n) and the public key x·G.
+func GenerateKey(g Group, random io.Reader) (privateKey *big.Int, publicKey Element, err error) {
+	k, err := randomScalar(g, random)
+	if err != nil {
+		return nil, nil, err
+	}
+	privateKey = k.Big()
+	return privateKey, g.ScalarBaseMult(privateKey), nil
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pvss
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+// testGroupLaws checks the group operations and the encoding of a Group implementation.
+func testGroupLaws(t *testing.T, g Group) {
+	a, A, err := GenerateKey(g, rand.Reader)
+	require.NoError(t, err)
+	b, B, err := GenerateKey(g, rand.Reader)
+	require.NoError(t, err)
+	O := g.Identity()
+
+	// (a+b)·G == a·G + b·G
+	sum := new(big.Int).Add(a, b)
+	require.True(t, g.Equal(g.ScalarBaseMult(sum), g.Add(A, B)))
+	require.True(t, g.Equal(g.Add(A, B), g.Add(B, A)))
+	require.True(t, g.Equal(g.Add(A, O), A))
+	require.True(t, g.Equal(g.Add(O, A), A))
+	require.True(t, g.Equal(g.Add(A, g.Neg(A)), O))
+	require.True(t, g.Equal(g.Add(A, A), g.ScalarMult(A, big.NewInt(2))))
+	// n·A == O, (n+1)·A == A
+	require.True(t, g.Equal(g.ScalarMult(A, g.Order()), O))
+	require.True(t, g.Equal(g.ScalarMult(A, new(big.Int).Add(g.Order(), big.NewInt(1))), A))
+	require.True(t, g.Equal(g.ScalarMult(O, a), O))
+	require.False(t, g.Equal(A, B))
+	require.False(t, g.Equal(g.Generator(), g.SecondGenerator()))
+	require.True(t, VerifySecondGenerator(g))
+	// a·A + b·B + (n-1)·A + a·O + 0·H == a·A + b·B - A
+	msm := multiScalarMult(g, []Element{A, B, A, O, g.SecondGenerator()},
+		[]*big.Int{a, b, new(big.Int).Sub(g.Order(), big.NewInt(1)), a, new(big.Int)})
+	require.True(t, g.Equal(msm, g.Add(g.Add(g.ScalarMult(A, a), g.ScalarMult(B, b)), g.Neg(A))))
+	require.True(t, g.Equal(multiScalarMult(g, nil, nil), O))
+
+	for _, e := range []Element{A, B, O, g.Generator(), g.SecondGenerator()} {
+		data := g.Encode(e)
+		require.Equal(t, g.ElementLen(), len(data))
+		decoded, err := g.Decode(data)
+		require.NoError(t, err)
+		require.True(t, g.Equal(e, decoded))
+	}
+	_, err = g.Decode(make([]byte, g.ElementLen()-1))
+	require.Error(t, err)
+
+	h1 := g.HashToElement([]byte("msg"), []byte("dst"))
+	require.True(t, g.Equal(h1, g.HashToElement([]byte("msg"), []byte("dst"))))
+	require.False(t, g.Equal(h1, g.HashToElement([]byte("msg"), []byte("other dst"))))
+	require.False(t, isIdentity(g, h1))
+
+	registered, err := LookupGroup(g.Name())
+	require.NoError(t, err)
+	require.True(t, sameGroup(g, registered))
+}
+
+func TestSecp256k1Group(t *testing.T) {
+	g := Secp256k1()
+	testGroupLaws(t, g)
+	require.True(t, g.Equal(g.SecondGenerator(), &Point{Hx, Hy}))
+
+	_, err := LookupGroup("no such group")
+	require.ErrorIs(t, err, ErrUnknownGroup)
+
+	// a point built off the curve doesn't crash the multi-scalar multiplication, nor its callers
+	offCurve := &Point{X: big.NewInt(5), Y: big.NewInt(7)}
+	require.True(t, isIdentity(g, multiScalarMult(g, []Element{g.Generator(), offCurve}, []*big.Int{big.NewInt(2), big.NewInt(3)})))
+	require.True(t, isIdentity(g, CommitmentAt(g, []Element{g.Generator(), offCurve}, 2)))
+	decShares := []*DecryptedShare{{Group: g, Position: 1, S: offCurve}, {Group: g, Position: 2, S: g.Generator()}}
+	require.NotNil(t, ReconstructSecret(g, decShares, big.NewInt(42)))
+}
+
+func TestNewSecp256k1Group(t *testing.T) {
+	require.True(t, sameGroup(NewSecp256k1Group(""), Secp256k1()))
+
+	g := NewSecp256k1Group("test deployment")
+	require.Equal(t, "secp256k1/test deployment", g.Name())
+	require.True(t, VerifySecondGenerator(g))
+	require.False(t, g.Equal(g.SecondGenerator(), Secp256k1().SecondGenerator()))
+	require.False(t, g.Equal(g.SecondGenerator(), NewSecp256k1Group("other deployment").SecondGenerator()))
+
+	// a transcript dealt on the deployment group decodes once the group is registered
+	dealers, pks := genDealers(g, 3)
+	sharebox, err := dealers[0].DistributeSecret(big.NewInt(7), pks[1:], testSession(g, pks[1:], 2))
+	require.NoError(t, err)
+	require.True(t, VerifyDistributionShares(g, sharebox.Session, sharebox))
+	require.False(t, VerifyDistributionShares(Secp256k1(), sharebox.Session, sharebox))
+	data, err := sharebox.MarshalBinary()
+	require.NoError(t, err)
+	decoded := new(DistributionSharesBox)
+	require.ErrorIs(t, decoded.UnmarshalBinary(data), ErrUnknownGroup)
+	RegisterGroup(g)
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	require.True(t, VerifyDistributionShares(g, sharebox.Session, decoded))
+
+	// a group with a second generator of unknown origin
+	forged := &secp256k1Group{name: "forged", curve: theSecp256k1.curve, g: theSecp256k1.g, h: toPoint(g.ScalarBaseMult(big.NewInt(2)))}
+	require.False(t, VerifySecondGenerator(forged))
+}
+
+func TestRistretto255Group(t *testing.T) {
+	testGroupLaws(t, Ristretto255())
+}
+
+func TestP256Group(t *testing.T) {
+	g := P256()
+	testGroupLaws(t, g)
+	require.True(t, VerifySecondGenerator(g))
+
+	// the test vectors of P256_XMD:SHA-256_SSWU_RO_, RFC 9380 appendix J.1.1
+	dst := []byte("QUUX-V01-CS02-with-P256_XMD:SHA-256_SSWU_RO_")
+	for _, v := range []struct{ msg, x, y string }{
+		{"", "2c15230b26dbc6fc9a37051158c95b79656e17a1a920b11394ca91c44247d3e4", "8a7a74985cc5c776cdfe4b1f19884970453912e9d31528c060be9ab5c43e8415"},
+		{"abc", "0bb8b87485551aa43ed54f009230450b492fead5f1cc91658775dac4a3388a0f", "5c41b3d0731a27a7b14bc0bf0ccded2d8751f83493404c84a88e71ffd424212e"},
+	} {
+		p := toPoint(g.HashToElement([]byte(v.msg), dst))
+		require.Equal(t, v.x, hex.EncodeToString(p.X.FillBytes(make([]byte, 32))), v.msg)
+		require.Equal(t, v.y, hex.EncodeToString(p.Y.FillBytes(make([]byte, 32))), v.msg)
+	}
+}
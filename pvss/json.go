@@ -0,0 +1,583 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pvss
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+)
+
+// jsonSchemaVersion is the value of the "version" field of every JSON encoded transcript object.
+// Elements are hex encoded by Group.Encode, scalars are hex encoded big-endian with the byte length of the group order.
+// The optional fields, the share commitments and the payload of a box and the commitments a1, a2 of a proof, are omitted
+// when they are missing.
+//
+// U is hex encoded big-endian with the fixed length of 32 bytes, in the versions older than 5 it has no leading zeros.
+// All the other fields are required, a missing or null one is rejected.
+//
+// Every new field bumps the version. The objects are encoded with the current version, and the objects of the older
+// versions are still decoded, but without the fields which came after their version.
+const (
+	jsonSchemaVersion = 5
+
+	// jsonShareCommitmentsVersion is the first version with the share_commitments of a box in the SCRAPE mode
+	jsonShareCommitmentsVersion = 2
+	// jsonPayloadVersion is the first version with the payload of a box
+	jsonPayloadVersion = 3
+	// jsonCommitmentsVersion is the first version with the commitments a1, a2 of a proof
+	jsonCommitmentsVersion = 4
+	// jsonFixedUVersion is the first version whose U has the fixed length secretLength
+	jsonFixedUVersion = 5
+)
+
+type jsonShare struct {
+	Version   int    `json:"version"`
+	Group     string `json:"group"`
+	Position  int    `json:"position"`
+	PK        string `json:"pk"`
+	S         string `json:"s"`
+	Challenge string `json:"challenge"`
+	Response  string `json:"response"`
+	A1        string `json:"a1,omitempty"`
+	A2        string `json:"a2,omitempty"`
+}
+
+type jsonDecryptedShare struct {
+	Version   int    `json:"version"`
+	Group     string `json:"group"`
+	Position  int    `json:"position"`
+	PK        string `json:"pk"`
+	S         string `json:"s"`
+	Y         string `json:"y"`
+	Challenge string `json:"challenge"`
+	Response  string `json:"response"`
+	A1        string `json:"a1,omitempty"`
+	A2        string `json:"a2,omitempty"`
+}
+
+type jsonDistributionSharesBox struct {
+	Version          int          `json:"version"`
+	Group            string       `json:"group"`
+	Session          *jsonSession `json:"session"`
+	Commitments      []string     `json:"commitments"`
+	ShareCommitments []string     `json:"share_commitments,omitempty"`
+	Shares           []*Share     `json:"shares"`
+	U                string       `json:"u"`
+	Payload          *jsonPayload `json:"payload,omitempty"`
+}
+
+type jsonPayload struct {
+	Algorithm  string `json:"algorithm"`
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+type jsonSession struct {
+	ID               string `json:"id"`
+	Epoch            uint64 `json:"epoch"`
+	ParticipantsHash string `json:"participants_hash"`
+	Threshold        int    `json:"threshold"`
+}
+
+// UnmarshalJSON decodes the payload of a box, whose fields are all required.
+func (jp *jsonPayload) UnmarshalJSON(data []byte) error {
+	type plain jsonPayload
+	return decodeJSON(data, (*plain)(jp))
+}
+
+// UnmarshalJSON decodes the session of a box, whose fields are all required.
+func (js *jsonSession) UnmarshalJSON(data []byte) error {
+	type plain jsonSession
+	return decodeJSON(data, (*plain)(js))
+}
+
+// MarshalJSON encodes the point as a hex string of its encoding in the secp256k1 group, see Secp256k1().Encode.
+// The points of the other curve groups are encoded by their groups, within the shares and boxes.
+func (p *Point) MarshalJSON() ([]byte, error) {
+	if !validPoint(theSecp256k1, p) {
+		return nil, ErrInvalidPoint
+	}
+	return json.Marshal(hex.EncodeToString(theSecp256k1.Encode(p)))
+}
+
+// UnmarshalJSON decodes a point encoded by MarshalJSON by Secp256k1().Decode, which rejects the points not on the curve
+// and the non-canonical encodings.
+func (p *Point) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidEncoding, err)
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPoint, err)
+	}
+	a, err := theSecp256k1.Decode(b)
+	if err != nil {
+		return err
+	}
+	*p = *toPoint(a)
+	return nil
+}
+
+// validPoint reports whether p is the point at infinity or a point of the curve of g with canonical coordinates.
+func validPoint(g *secp256k1Group, p *Point) bool {
+	if p == nil || p.X == nil || p.Y == nil {
+		return false
+	}
+	if isInfinity(p) {
+		return true
+	}
+	for _, c := range []*big.Int{p.X, p.Y} {
+		if c.Sign() < 0 || c.Cmp(g.curve.P) >= 0 {
+			return false
+		}
+	}
+	return g.curve.IsOnCurve(p.X, p.Y)
+}
+
+// MarshalJSON encodes the share, including its DLEQ proof.
+func (s *Share) MarshalJSON() ([]byte, error) {
+	e := newJSONEncoder(s.Group)
+	js := &jsonShare{
+		Version:   jsonSchemaVersion,
+		Group:     e.groupName(),
+		Position:  e.position(s.Position),
+		PK:        e.element(s.PK),
+		S:         e.element(s.S),
+		Challenge: e.scalar(s.challenge),
+		Response:  e.scalar(s.response),
+	}
+	js.A1, js.A2 = e.commitments(s.a1, s.a2)
+	if e.err != nil {
+		return nil, e.err
+	}
+	return json.Marshal(js)
+}
+
+// UnmarshalJSON decodes a share encoded by MarshalJSON.
+// Unknown and missing fields, elements not in the group and scalars not less than the group order are rejected.
+func (s *Share) UnmarshalJSON(data []byte) error {
+	var js jsonShare
+	if err := decodeJSON(data, &js); err != nil {
+		return err
+	}
+	d, err := newJSONDecoder(js.Version, js.Group)
+	if err != nil {
+		return err
+	}
+	share := Share{
+		Group:     d.group,
+		PK:        d.element(js.PK),
+		Position:  d.position(js.Position),
+		S:         d.element(js.S),
+		challenge: d.scalar(js.Challenge),
+		response:  d.scalar(js.Response),
+	}
+	share.a1, share.a2 = d.commitments(js.A1, js.A2)
+	if d.err != nil {
+		return d.err
+	}
+	*s = share
+	return nil
+}
+
+// MarshalJSON encodes the decrypted share, including its DLEQ proof.
+func (ds *DecryptedShare) MarshalJSON() ([]byte, error) {
+	e := newJSONEncoder(ds.Group)
+	jds := &jsonDecryptedShare{
+		Version:   jsonSchemaVersion,
+		Group:     e.groupName(),
+		Position:  e.position(ds.Position),
+		PK:        e.element(ds.PK),
+		S:         e.element(ds.S),
+		Y:         e.element(ds.Y),
+		Challenge: e.scalar(ds.challenge),
+		Response:  e.scalar(ds.response),
+	}
+	jds.A1, jds.A2 = e.commitments(ds.a1, ds.a2)
+	if e.err != nil {
+		return nil, e.err
+	}
+	return json.Marshal(jds)
+}
+
+// UnmarshalJSON decodes a decrypted share encoded by MarshalJSON.
+// Unknown and missing fields, elements not in the group and scalars not less than the group order are rejected.
+func (ds *DecryptedShare) UnmarshalJSON(data []byte) error {
+	var jds jsonDecryptedShare
+	if err := decodeJSON(data, &jds); err != nil {
+		return err
+	}
+	d, err := newJSONDecoder(jds.Version, jds.Group)
+	if err != nil {
+		return err
+	}
+	decShare := DecryptedShare{
+		Group:     d.group,
+		PK:        d.element(jds.PK),
+		Position:  d.position(jds.Position),
+		S:         d.element(jds.S),
+		Y:         d.element(jds.Y),
+		challenge: d.scalar(jds.Challenge),
+		response:  d.scalar(jds.Response),
+	}
+	decShare.a1, decShare.a2 = d.commitments(jds.A1, jds.A2)
+	if d.err != nil {
+		return d.err
+	}
+	*ds = decShare
+	return nil
+}
+
+// MarshalJSON encodes the commitments, the encrypted shares with their proofs and U.
+func (b *DistributionSharesBox) MarshalJSON() ([]byte, error) {
+	e := newJSONEncoder(b.Group)
+	jb := &jsonDistributionSharesBox{
+		Version:     jsonSchemaVersion,
+		Group:       e.groupName(),
+		Session:     e.session(b.Session),
+		Commitments: make([]string, 0, len(b.Commitments)),
+		Shares:      b.Shares,
+	}
+	for _, c := range b.Commitments {
+		jb.Commitments = append(jb.Commitments, e.element(c))
+	}
+	for _, v := range b.ShareCommitments {
+		jb.ShareCommitments = append(jb.ShareCommitments, e.element(v))
+	}
+	for _, s := range b.Shares {
+		if s == nil || !sameGroup(s.Group, b.Group) {
+			e.fail(ErrGroupMismatch)
+		}
+	}
+	if u, err := marshalU(b.U); err != nil {
+		e.fail(err)
+	} else {
+		jb.U = hex.EncodeToString(u)
+	}
+	if p := b.Payload; p != nil {
+		jb.Payload = &jsonPayload{
+			Algorithm:  p.Algorithm.String(),
+			Nonce:      hex.EncodeToString(p.Nonce),
+			Ciphertext: hex.EncodeToString(p.Ciphertext),
+		}
+	}
+	if e.err != nil {
+		return nil, e.err
+	}
+	return json.Marshal(jb)
+}
+
+// UnmarshalJSON decodes a distribution shares box encoded by MarshalJSON.
+// Unknown and missing fields, elements not in the group and scalars not less than the group order are rejected.
+func (b *DistributionSharesBox) UnmarshalJSON(data []byte) error {
+	var jb jsonDistributionSharesBox
+	if err := decodeJSON(data, &jb); err != nil {
+		return err
+	}
+	d, err := newJSONDecoder(jb.Version, jb.Group)
+	if err != nil {
+		return err
+	}
+	session := d.session(jb.Session)
+	commitments := make([]Element, 0, len(jb.Commitments))
+	for _, c := range jb.Commitments {
+		commitments = append(commitments, d.element(c))
+	}
+	var shareCommitments []Element
+	if jb.ShareCommitments != nil {
+		d.since(jsonShareCommitmentsVersion, "share_commitments")
+	}
+	for _, v := range jb.ShareCommitments {
+		shareCommitments = append(shareCommitments, d.element(v))
+	}
+	for _, s := range jb.Shares {
+		if s == nil {
+			d.fail(fmt.Errorf("%w: missing share", ErrInvalidEncoding))
+		} else if !sameGroup(s.Group, d.group) {
+			d.fail(ErrGroupMismatch)
+		}
+	}
+	u := d.u(jb.U)
+	payload := d.payload(jb.Payload)
+	if d.err != nil {
+		return d.err
+	}
+	*b = DistributionSharesBox{
+		Group:            d.group,
+		Session:          session,
+		Commitments:      commitments,
+		ShareCommitments: shareCommitments,
+		Shares:           jb.Shares,
+		U:                u,
+		Payload:          payload,
+	}
+	return nil
+}
+
+// decodeJSON decodes data into v, a pointer to a struct. Fields of data not present in v are rejected, and so are
+// the fields of v, but the omitempty ones, which are missing or null in data.
+func decodeJSON(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		// errors of the nested objects are already meaningful
+		for _, target := range []error{ErrInvalidEncoding, ErrUnsupportedVersion, ErrUnknownGroup, ErrGroupMismatch, ErrInvalidPoint, ErrInvalidScalar, ErrInvalidPosition} {
+			if errors.Is(err, target) {
+				return err
+			}
+		}
+		return fmt.Errorf("%w: %v", ErrInvalidEncoding, err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidEncoding, err)
+	}
+	t := reflect.TypeOf(v).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		tag := strings.Split(t.Field(i).Tag.Get("json"), ",")
+		if len(tag) > 1 && tag[1] == "omitempty" {
+			continue
+		}
+		if value, ok := fields[tag[0]]; !ok || string(value) == "null" {
+			return fmt.Errorf("%w: missing field %q", ErrInvalidEncoding, tag[0])
+		}
+	}
+	return nil
+}
+
+// jsonEncoder converts fields to their JSON representations, the first error is kept.
+type jsonEncoder struct {
+	group Group
+	err   error
+}
+
+func newJSONEncoder(g Group) *jsonEncoder {
+	e := &jsonEncoder{group: g}
+	if g == nil {
+		e.fail(ErrUnknownGroup)
+	}
+	return e
+}
+
+func (e *jsonEncoder) fail(err error) {
+	if e.err == nil {
+		e.err = err
+	}
+}
+
+func (e *jsonEncoder) groupName() string {
+	if e.err != nil {
+		return ""
+	}
+	return e.group.Name()
+}
+
+func (e *jsonEncoder) position(position int) int {
+	if position < 1 {
+		e.fail(ErrInvalidPosition)
+	}
+	return position
+}
+
+func (e *jsonEncoder) session(s *Session) *jsonSession {
+	if s == nil || s.Threshold < 1 {
+		e.fail(fmt.Errorf("%w: invalid session", ErrInvalidEncoding))
+		return nil
+	}
+	return &jsonSession{
+		ID:               hex.EncodeToString(s.ID),
+		Epoch:            s.Epoch,
+		ParticipantsHash: hex.EncodeToString(s.ParticipantsHash),
+		Threshold:        s.Threshold,
+	}
+}
+
+func (e *jsonEncoder) element(a Element) string {
+	if e.err != nil {
+		return ""
+	}
+	if a == nil {
+		e.fail(ErrInvalidPoint)
+		return ""
+	}
+	return hex.EncodeToString(e.group.Encode(a))
+}
+
+// commitments encodes the optional commitments of a proof, which are either both present or both omitted.
+func (e *jsonEncoder) commitments(a1, a2 Element) (string, string) {
+	if a1 == nil && a2 == nil {
+		return "", ""
+	}
+	return e.element(a1), e.element(a2)
+}
+
+func (e *jsonEncoder) scalar(k *big.Int) string {
+	if e.err != nil {
+		return ""
+	}
+	b, err := marshalScalar(e.group, k)
+	if err != nil {
+		e.fail(err)
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// jsonDecoder parses and validates fields from their JSON representations, the first error is kept.
+type jsonDecoder struct {
+	group   Group
+	version int
+	err     error
+}
+
+func newJSONDecoder(version int, groupName string) (*jsonDecoder, error) {
+	if version < 1 || version > jsonSchemaVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, version)
+	}
+	g, err := LookupGroup(groupName)
+	if err != nil {
+		return nil, err
+	}
+	return &jsonDecoder{group: g, version: version}, nil
+}
+
+func (d *jsonDecoder) fail(err error) {
+	if d.err == nil {
+		d.err = err
+	}
+}
+
+// since rejects the field, which is present, if it came after the version of the object.
+func (d *jsonDecoder) since(version int, field string) {
+	if d.version < version {
+		d.fail(fmt.Errorf("%w: unknown field %q in version %d", ErrInvalidEncoding, field, d.version))
+	}
+}
+
+func (d *jsonDecoder) position(position int) int {
+	if position < 1 {
+		d.fail(ErrInvalidPosition)
+	}
+	return position
+}
+
+func (d *jsonDecoder) session(js *jsonSession) *Session {
+	if d.err != nil {
+		return nil
+	}
+	if js == nil || js.Threshold < 1 {
+		d.fail(fmt.Errorf("%w: invalid session", ErrInvalidEncoding))
+		return nil
+	}
+	id, err := hex.DecodeString(js.ID)
+	if err != nil {
+		d.fail(fmt.Errorf("%w: invalid session id: %v", ErrInvalidEncoding, err))
+		return nil
+	}
+	participantsHash, err := hex.DecodeString(js.ParticipantsHash)
+	if err != nil {
+		d.fail(fmt.Errorf("%w: invalid participants hash: %v", ErrInvalidEncoding, err))
+		return nil
+	}
+	return &Session{ID: id, Epoch: js.Epoch, ParticipantsHash: participantsHash, Threshold: js.Threshold}
+}
+
+func (d *jsonDecoder) payload(jp *jsonPayload) *Payload {
+	if d.err != nil || jp == nil {
+		return nil
+	}
+	if d.since(jsonPayloadVersion, "payload"); d.err != nil {
+		return nil
+	}
+	algorithm, err := parseAEADAlgorithm(jp.Algorithm)
+	if err != nil {
+		d.fail(fmt.Errorf("%w: %v", ErrInvalidEncoding, err))
+		return nil
+	}
+	nonce, err := hex.DecodeString(jp.Nonce)
+	if err != nil {
+		d.fail(fmt.Errorf("%w: invalid nonce: %v", ErrInvalidEncoding, err))
+		return nil
+	}
+	ciphertext, err := hex.DecodeString(jp.Ciphertext)
+	if err != nil {
+		d.fail(fmt.Errorf("%w: invalid ciphertext: %v", ErrInvalidEncoding, err))
+		return nil
+	}
+	return &Payload{Algorithm: algorithm, Nonce: nonce, Ciphertext: ciphertext}
+}
+
+func (d *jsonDecoder) element(s string) Element {
+	if d.err != nil {
+		return nil
+	}
+	if s == "" {
+		d.fail(fmt.Errorf("%w: missing element", ErrInvalidEncoding))
+		return nil
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		d.fail(fmt.Errorf("%w: %v", ErrInvalidPoint, err))
+		return nil
+	}
+	a, err := d.group.Decode(b)
+	if err != nil {
+		d.fail(err)
+	}
+	return a
+}
+
+// commitments decodes the optional commitments of a proof, which are either both present or both omitted.
+func (d *jsonDecoder) commitments(a1, a2 string) (Element, Element) {
+	if a1 == "" && a2 == "" {
+		return nil, nil
+	}
+	d.since(jsonCommitmentsVersion, "a1")
+	return d.element(a1), d.element(a2)
+}
+
+// u decodes U, which has the fixed length secretLength since jsonFixedUVersion.
+func (d *jsonDecoder) u(s string) *big.Int {
+	if d.err != nil {
+		return nil
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		d.fail(fmt.Errorf("%w: invalid U: %v", ErrInvalidEncoding, err))
+		return nil
+	}
+	u, err := unmarshalU(b, d.version >= jsonFixedUVersion)
+	if err != nil {
+		d.fail(err)
+	}
+	return u
+}
+
+func (d *jsonDecoder) scalar(s string) *big.Int {
+	if d.err != nil {
+		return nil
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		d.fail(fmt.Errorf("%w: %v", ErrInvalidScalar, err))
+		return nil
+	}
+	k, err := unmarshalScalar(d.group, b)
+	if err != nil {
+		d.fail(err)
+	}
+	return k
+}
@@ -0,0 +1,250 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pvss
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/stars-labs/go-pvss/crypto/secp256k1"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDistributionSharesBox_MarshalJSON(t *testing.T) {
+	threshold, n := 3, 4
+	g := Secp256k1()
+	dealers, pks := genDealers(g, n+1)
+	secret := new(big.Int).SetBytes([]byte("Hello, go-pvss under ECC"))
+	sharebox, err := dealers[0].DistributeSecret(secret, pks[1:], testSession(g, pks[1:], threshold))
+	require.NoError(t, err, "DistributeSecret")
+
+	data, err := json.Marshal(sharebox)
+	require.NoError(t, err, "Marshal")
+
+	decoded := new(DistributionSharesBox)
+	require.NoError(t, json.Unmarshal(data, decoded), "Unmarshal")
+	require.Equal(t, threshold, len(decoded.Commitments))
+	require.Equal(t, n, len(decoded.Shares))
+	require.Equal(t, 0, decoded.U.Cmp(sharebox.U))
+	require.True(t, VerifyDistributionShares(g, sharebox.Session, decoded), "VerifyDistributionShares")
+
+	decShares := make([]*DecryptedShare, 0, n)
+	for i, d := range dealers[1:] {
+		decShare, err := d.ExtractSecretShare(decoded)
+		require.NoError(t, err, "ExtractSecretShare", i)
+
+		data, err := json.Marshal(decShare)
+		require.NoError(t, err)
+		decodedShare := new(DecryptedShare)
+		require.NoError(t, json.Unmarshal(data, decodedShare))
+		require.True(t, VerifyDecryptedShare(g, sharebox.Session, decodedShare), i)
+		decShares = append(decShares, decodedShare)
+	}
+	s := ReconstructSecret(g, decShares[:threshold], decoded.U)
+	require.Equal(t, 0, s.Cmp(secret))
+}
+
+func TestUnmarshalJSON_Rejects(t *testing.T) {
+	g := Secp256k1()
+	dealers, pks := genDealers(g, 4)
+	sharebox, err := dealers[0].DistributeSecret(big.NewInt(42), pks[1:], testSession(g, pks[1:], 2))
+	require.NoError(t, err)
+	data, err := json.Marshal(sharebox.Shares[0])
+	require.NoError(t, err)
+
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &fields))
+	require.EqualValues(t, jsonSchemaVersion, fields["version"])
+	mutate := func(key string, value interface{}) []byte {
+		m := make(map[string]interface{}, len(fields))
+		for k, v := range fields {
+			m[k] = v
+		}
+		if value == nil {
+			delete(m, key)
+		} else {
+			m[key] = value
+		}
+		b, err := json.Marshal(m)
+		require.NoError(t, err)
+		return b
+	}
+
+	share := new(Share)
+	require.NoError(t, json.Unmarshal(data, share))
+	require.ErrorIs(t, json.Unmarshal(mutate("version", jsonSchemaVersion+1), share), ErrUnsupportedVersion)
+	require.ErrorIs(t, json.Unmarshal(mutate("version", 0), share), ErrUnsupportedVersion)
+	require.ErrorIs(t, json.Unmarshal(mutate("extra", "field"), share), ErrInvalidEncoding)
+	require.ErrorIs(t, json.Unmarshal(mutate("s", nil), share), ErrInvalidEncoding)
+	require.ErrorIs(t, json.Unmarshal(mutate("position", 0), share), ErrInvalidPosition)
+	// x = 5 is not the x-coordinate of any point on secp256k1
+	offCurve := "02" + strings.Repeat("0", 63) + "5"
+	require.ErrorIs(t, json.Unmarshal(mutate("s", offCurve), share), ErrInvalidPoint)
+	require.ErrorIs(t, json.Unmarshal(mutate("challenge", hex.EncodeToString(g.Order().Bytes())), share), ErrInvalidScalar)
+	require.ErrorIs(t, json.Unmarshal(mutate("response", "00"), share), ErrInvalidScalar)
+
+	// the commitments of the proof are optional, but they are both present or both missing
+	require.NoError(t, json.Unmarshal(data, share))
+	require.True(t, g.Equal(sharebox.Shares[0].a1, share.a1))
+	require.ErrorIs(t, json.Unmarshal(mutate("a2", nil), share), ErrInvalidEncoding)
+	delete(fields, "a1")
+	require.ErrorIs(t, json.Unmarshal(mutate("a2", offCurve), share), ErrInvalidEncoding)
+	require.NoError(t, json.Unmarshal(mutate("a2", nil), share))
+	require.Nil(t, share.a1)
+	require.Nil(t, share.a2)
+}
+
+func TestUnmarshalJSON_OlderVersions(t *testing.T) {
+	g := Secp256k1()
+	sharebox, decShares := dealAndDecrypt(t, g, 2, 3, big.NewInt(42), true)
+	withVersion := func(v interface{}, version int, drop ...string) []byte {
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+		var fields map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(data, &fields))
+		fields["version"] = json.RawMessage(strconv.Itoa(version))
+		if u, ok := fields["u"]; ok && version < jsonFixedUVersion {
+			// U has no leading zeros before it had a fixed length
+			var s string
+			require.NoError(t, json.Unmarshal(u, &s))
+			b, err := hex.DecodeString(s)
+			require.NoError(t, err)
+			fields["u"], err = json.Marshal(hex.EncodeToString(new(big.Int).SetBytes(b).Bytes()))
+			require.NoError(t, err)
+		}
+		for _, key := range drop {
+			delete(fields, key)
+		}
+		data, err = json.Marshal(fields)
+		require.NoError(t, err)
+		return data
+	}
+
+	// a decrypted share of version 1 has no commitments a1, a2, which came with version 4
+	decShare := new(DecryptedShare)
+	require.ErrorIs(t, json.Unmarshal(withVersion(decShares[0], jsonCommitmentsVersion-1), decShare), ErrInvalidEncoding)
+	require.NoError(t, json.Unmarshal(withVersion(decShares[0], 1, "a1", "a2"), decShare))
+	require.Nil(t, decShare.a1)
+	require.True(t, VerifyDecryptedShare(g, sharebox.Session, decShare))
+
+	// the share commitments of the SCRAPE mode came with version 2
+	box := new(DistributionSharesBox)
+	require.ErrorIs(t, json.Unmarshal(withVersion(sharebox, 1), box), ErrInvalidEncoding)
+	require.NoError(t, json.Unmarshal(withVersion(sharebox, jsonShareCommitmentsVersion), box))
+	require.True(t, VerifyDistributionShares(g, sharebox.Session, box))
+
+	// and the payload with version 3
+	dealers, pks := genDealers(g, 3)
+	payloadBox, err := dealers[0].DistributePayload([]byte("payload"), nil, pks[1:], testSession(g, pks[1:], 2), ChaCha20Poly1305)
+	require.NoError(t, err)
+	require.ErrorIs(t, json.Unmarshal(withVersion(payloadBox, jsonPayloadVersion-1), box), ErrInvalidEncoding)
+	require.NoError(t, json.Unmarshal(withVersion(payloadBox, jsonPayloadVersion), box))
+	require.NotNil(t, box.Payload)
+}
+
+func TestPoint_JSON(t *testing.T) {
+	g := Secp256k1()
+	_, pk, err := GenerateKey(g, rand.Reader)
+	require.NoError(t, err)
+	for _, point := range []Element{pk, g.Identity()} {
+		data, err := json.Marshal(point)
+		require.NoError(t, err)
+		var s string
+		require.NoError(t, json.Unmarshal(data, &s))
+		require.Equal(t, hex.EncodeToString(g.Encode(point)), s, "compressed SEC1")
+		decoded := new(Point)
+		require.NoError(t, json.Unmarshal(data, decoded))
+		require.True(t, g.Equal(point, decoded))
+	}
+
+	p := toPoint(pk)
+	offCurve := &Point{X: p.X, Y: new(big.Int).Add(p.Y, big.NewInt(1))}
+	_, err = json.Marshal(offCurve)
+	require.ErrorIs(t, err, ErrInvalidPoint)
+	_, err = json.Marshal(&Point{X: new(big.Int).Add(p.X, g.(*secp256k1Group).curve.P), Y: p.Y})
+	require.ErrorIs(t, err, ErrInvalidPoint)
+
+	decoded := new(Point)
+	uncompressed := hex.EncodeToString(secp256k1.S256().Marshal(p.X, p.Y))
+	for _, s := range []string{
+		"02" + strings.Repeat("0", 63) + "5", // x = 5 is not on the curve
+		uncompressed,                         // not the canonical encoding
+		"05" + uncompressed[2:66],            // invalid prefix
+		"zz",
+		"",
+	} {
+		data, _ := json.Marshal(s)
+		require.ErrorIs(t, json.Unmarshal(data, decoded), ErrInvalidPoint, s)
+	}
+	require.ErrorIs(t, json.Unmarshal([]byte(`{"x":1,"y":2}`), decoded), ErrInvalidEncoding)
+}
+
+func TestUnmarshalJSON_RejectsSession(t *testing.T) {
+	g := Secp256k1()
+	dealers, pks := genDealers(g, 4)
+	sharebox, err := dealers[0].DistributeSecret(big.NewInt(42), pks[1:], testSession(g, pks[1:], 2))
+	require.NoError(t, err)
+	data, err := json.Marshal(sharebox)
+	require.NoError(t, err)
+	decoded := new(DistributionSharesBox)
+	require.NoError(t, json.Unmarshal(data, decoded))
+	require.True(t, sharebox.Session.Equal(decoded.Session))
+
+	var fields map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(data, &fields))
+	delete(fields, "session")
+	data, err = json.Marshal(fields)
+	require.NoError(t, err)
+	require.ErrorIs(t, json.Unmarshal(data, decoded), ErrInvalidEncoding)
+
+	fields["session"] = json.RawMessage(`{"id":"00","epoch":1,"participants_hash":"00","threshold":0}`)
+	data, err = json.Marshal(fields)
+	require.NoError(t, err)
+	require.ErrorIs(t, json.Unmarshal(data, decoded), ErrInvalidEncoding)
+}
+
+func TestUnmarshalJSON_RejectsMissingFields(t *testing.T) {
+	g := Secp256k1()
+	dealers, pks := genDealers(g, 4)
+	sharebox, err := dealers[0].DistributeSecret(big.NewInt(42), pks[1:], testSession(g, pks[1:], 2))
+	require.NoError(t, err)
+	data, err := json.Marshal(sharebox)
+	require.NoError(t, err)
+	mutate := func(key string, value json.RawMessage) []byte {
+		var fields map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(data, &fields))
+		if value == nil {
+			delete(fields, key)
+		} else {
+			fields[key] = value
+		}
+		b, err := json.Marshal(fields)
+		require.NoError(t, err)
+		return b
+	}
+
+	decoded := new(DistributionSharesBox)
+	for _, key := range []string{"u", "commitments", "shares", "group", "version"} {
+		require.ErrorIs(t, json.Unmarshal(mutate(key, nil), decoded), ErrInvalidEncoding, key)
+		require.ErrorIs(t, json.Unmarshal(mutate(key, json.RawMessage("null")), decoded), ErrInvalidEncoding, key)
+	}
+	require.ErrorIs(t, json.Unmarshal(mutate("session", json.RawMessage(`{"id":"00","participants_hash":"00","threshold":2}`)), decoded), ErrInvalidEncoding)
+
+	// U has the fixed length of 32 bytes
+	u := hex.EncodeToString(sharebox.U.FillBytes(make([]byte, secretLength)))
+	require.NoError(t, json.Unmarshal(mutate("u", json.RawMessage(`"`+u+`"`)), decoded))
+	require.Equal(t, 0, decoded.U.Cmp(sharebox.U))
+	require.ErrorIs(t, json.Unmarshal(mutate("u", json.RawMessage(`"`+u[2:]+`"`)), decoded), ErrInvalidEncoding)
+	require.ErrorIs(t, json.Unmarshal(mutate("u", json.RawMessage(`"00`+u+`"`)), decoded), ErrInvalidEncoding)
+	require.ErrorIs(t, json.Unmarshal(mutate("u", json.RawMessage(`""`)), decoded), ErrInvalidEncoding)
+}
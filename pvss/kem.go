@@ -0,0 +1,167 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pvss
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/sha3"
+	"io"
+	"math/big"
+)
+
+var (
+	ErrUnsupportedAEAD  = errors.New("unsupported AEAD algorithm")
+	ErrMissingPayload   = errors.New("missing payload")
+	ErrDecryptionFailed = errors.New("payload decryption failed")
+)
+
+// AEADAlgorithm identifies the AEAD which encrypts the payload of a hybrid distribution.
+type AEADAlgorithm byte
+
+const (
+	ChaCha20Poly1305 AEADAlgorithm = 1
+	AES256GCM        AEADAlgorithm = 2
+)
+
+// aeadKeyLen is the key length of every supported AEAD.
+const aeadKeyLen = 32
+
+func (a AEADAlgorithm) String() string {
+	switch a {
+	case ChaCha20Poly1305:
+		return "chacha20-poly1305"
+	case AES256GCM:
+		return "aes-256-gcm"
+	}
+	return fmt.Sprintf("AEADAlgorithm(%d)", byte(a))
+}
+
+func (a AEADAlgorithm) supported() bool {
+	return a == ChaCha20Poly1305 || a == AES256GCM
+}
+
+// parseAEADAlgorithm returns the algorithm named by AEADAlgorithm.String.
+func parseAEADAlgorithm(name string) (AEADAlgorithm, error) {
+	for _, a := range []AEADAlgorithm{ChaCha20Poly1305, AES256GCM} {
+		if a.String() == name {
+			return a, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %q", ErrUnsupportedAEAD, name)
+}
+
+func (a AEADAlgorithm) new(key []byte) (cipher.AEAD, error) {
+	switch a {
+	case ChaCha20Poly1305:
+		return chacha20poly1305.New(key)
+	case AES256GCM:
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	}
+	return nil, fmt.Errorf("%w: %v", ErrUnsupportedAEAD, a)
+}
+
+// Payload is an arbitrary-length payload shared by a hybrid distribution, encrypted by the AEAD with the key derived from s·G.
+type Payload struct {
+	Algorithm  AEADAlgorithm
+	Nonce      []byte
+	Ciphertext []byte // with the authentication tag
+}
+
+// DistributePayload shares an arbitrary-length payload to the participants pks in the session, like DistributeSecret.
+//
+// It's a hybrid encryption: the PVSS distribution of a random s encapsulates the key s·G, which is run through HKDF-SHA3-256
+// to key the AEAD algorithm, and the payload is encrypted with the associated data into the Payload of the box.
+// The associated data is not carried in the box, it must be supplied again to ReconstructPayload.
+// The U of the box is zero, it doesn't hide anything.
+func (d *Dealer) DistributePayload(payload, associatedData []byte, pks []Element, session *Session, algorithm AEADAlgorithm) (*DistributionSharesBox, error) {
+	if session == nil {
+		return nil, errors.New("missing session")
+	}
+	threshold := session.Threshold
+	if threshold < 1 {
+		return nil, errors.New(fmt.Sprintf("invalid threshold(%d). ", threshold))
+	}
+	poly, err := InitPolynomial(threshold-1, d.Group.Order())
+	if err != nil {
+		return nil, err
+	}
+	shares, err := d.prepareShares(pks, session)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := algorithm.new(payloadKey(d.Group, session, algorithm, d.Group.ScalarBaseMult(poly.coefficients[0].Big())))
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	box, err := d.distribute(shares, session, poly, new(big.Int))
+	if err != nil {
+		return nil, err
+	}
+	box.Payload = &Payload{
+		Algorithm:  algorithm,
+		Nonce:      nonce,
+		Ciphertext: aead.Seal(nil, nonce, payload, associatedData),
+	}
+	return box, nil
+}
+
+// ReconstructPayload reconstructs s·G from no-less-than threshold number of decrypted shares of the box like ReconstructSecret,
+// and decrypts the payload of the box with the associated data. Unlike ReconstructSecret, wrong shares, a tampered payload or
+// other associated data are reported by ErrDecryptionFailed.
+func ReconstructPayload(g Group, decShares []*DecryptedShare, sharesBox *DistributionSharesBox, associatedData []byte) ([]byte, error) {
+	if sharesBox == nil || sharesBox.Payload == nil {
+		return nil, ErrMissingPayload
+	}
+	if !sameGroup(sharesBox.Group, g) {
+		return nil, ErrGroupMismatch
+	}
+	if sharesBox.Session == nil {
+		return nil, errors.New("missing session")
+	}
+	p := sharesBox.Payload
+	aead, err := p.Algorithm.new(payloadKey(g, sharesBox.Session, p.Algorithm, reconstructSG(g, decShares)))
+	if err != nil {
+		return nil, err
+	}
+	if len(p.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("%w: invalid nonce", ErrDecryptionFailed)
+	}
+	plaintext, err := aead.Open(nil, p.Nonce, p.Ciphertext, associatedData)
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+	return plaintext, nil
+}
+
+// payloadKey derives the AEAD key from s·G with HKDF-SHA3-256, the info binds the session, the group and the algorithm.
+func payloadKey(g Group, session *Session, algorithm AEADAlgorithm, sG Element) []byte {
+	t := session.transcript(payloadProtocol)
+	t.AppendMessage("group", []byte(g.Name()))
+	t.AppendMessage("aead", []byte(algorithm.String()))
+	key := make([]byte, aeadKeyLen)
+	if _, err := io.ReadFull(hkdf.New(sha3.New256, g.Encode(sG), nil, t.buf), key); err != nil {
+		panic("pvss: HKDF failed: " + err.Error())
+	}
+	return key
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pvss
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+func TestDistributePayload(t *testing.T) {
+	for _, g := range testGroups {
+		for _, algorithm := range []AEADAlgorithm{ChaCha20Poly1305, AES256GCM} {
+			t.Run(g.Name()+"/"+algorithm.String(), func(t *testing.T) {
+				testDistributePayload(t, g, algorithm)
+			})
+		}
+	}
+}
+
+func testDistributePayload(t *testing.T, g Group, algorithm AEADAlgorithm) {
+	threshold, n := 3, 5
+	dealers, pks := genDealers(g, n+1)
+	session := testSession(g, pks[1:], threshold)
+	payload := make([]byte, 3000)
+	_, err := rand.Read(payload)
+	require.NoError(t, err)
+	ad := []byte("file.bin")
+
+	sharebox, err := dealers[0].DistributePayload(payload, ad, pks[1:], session, algorithm)
+	require.NoError(t, err, "DistributePayload")
+	require.NotNil(t, sharebox.Payload)
+	require.Equal(t, 0, sharebox.U.Sign())
+	require.NoError(t, CheckDistributionShares(g, session, sharebox))
+
+	decShares := make([]*DecryptedShare, 0, n)
+	for i, d := range dealers[1:] {
+		decShare, err := d.ExtractSecretShare(sharebox)
+		require.NoError(t, err, "ExtractSecretShare", i)
+		decShares = append(decShares, decShare)
+	}
+	plaintext, err := ReconstructPayload(g, decShares[1:1+threshold], sharebox, ad)
+	require.NoError(t, err)
+	require.Equal(t, payload, plaintext)
+
+	// the payload survives the encodings
+	data, err := sharebox.MarshalBinary()
+	require.NoError(t, err)
+	decoded := new(DistributionSharesBox)
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	plaintext, err = ReconstructPayload(g, decShares[:threshold], decoded, ad)
+	require.NoError(t, err)
+	require.Equal(t, payload, plaintext)
+
+	data, err = json.Marshal(sharebox)
+	require.NoError(t, err)
+	decoded = new(DistributionSharesBox)
+	require.NoError(t, json.Unmarshal(data, decoded))
+	plaintext, err = ReconstructPayload(g, decShares[:threshold], decoded, ad)
+	require.NoError(t, err)
+	require.Equal(t, payload, plaintext)
+
+	// too few shares, other associated data and a tampered payload fail loudly
+	_, err = ReconstructPayload(g, decShares[:threshold-1], sharebox, ad)
+	require.ErrorIs(t, err, ErrDecryptionFailed)
+	_, err = ReconstructPayload(g, decShares[:threshold], sharebox, []byte("other.bin"))
+	require.ErrorIs(t, err, ErrDecryptionFailed)
+	tampered := *sharebox.Payload
+	tampered.Ciphertext = append([]byte(nil), tampered.Ciphertext...)
+	tampered.Ciphertext[7] ^= 1
+	box := *sharebox
+	box.Payload = &tampered
+	_, err = ReconstructPayload(g, decShares[:threshold], &box, ad)
+	require.ErrorIs(t, err, ErrDecryptionFailed)
+}
+
+func TestDistributePayload_Rejects(t *testing.T) {
+	g := Secp256k1()
+	dealers, pks := genDealers(g, 4)
+	session := testSession(g, pks[1:], 2)
+	_, err := dealers[0].DistributePayload([]byte("payload"), nil, pks[1:], session, AEADAlgorithm(9))
+	require.ErrorIs(t, err, ErrUnsupportedAEAD)
+
+	sharebox, err := dealers[0].DistributeSecret(big.NewInt(42), pks[1:], session)
+	require.NoError(t, err)
+	_, err = ReconstructPayload(g, nil, sharebox, nil)
+	require.ErrorIs(t, err, ErrMissingPayload)
+
+	var fields map[string]json.RawMessage
+	data, err := json.Marshal(sharebox)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &fields))
+	fields["payload"] = json.RawMessage(`{"algorithm":"rot13","nonce":"00","ciphertext":"00"}`)
+	data, err = json.Marshal(fields)
+	require.NoError(t, err)
+	require.ErrorIs(t, json.Unmarshal(data, new(DistributionSharesBox)), ErrInvalidEncoding)
+}
@@ -0,0 +1,157 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pvss
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var ErrInvalidKeyBox = errors.New("invalid key box")
+
+// KeyBox deals a random key x = p(0) like a box, and also delivers the scalar shares p(i), masked for their holders by
+// the Diffie-Hellman key of the dealer and the holder, in the way of package dkg.
+//
+// The decrypted shares of a box are the points p(i)·G, which only reconstruct s·G. The scalar shares p(i) are used by a
+// threshold scheme without reconstructing x, like the signing of package frost. They are bound to the box by its
+// commitments, p(i)·H == X_i, and the public key x·G to C_0 = x·H by a DLEQ proof.
+type KeyBox struct {
+	Box       *DistributionSharesBox
+	DealerPK  Element  // the public key of the dealer, by which the shares are masked
+	PublicKey Element  // x·G
+	Challenge *big.Int // DLEQ(G,x·G,H,C_0)
+	Response  *big.Int
+	// EncryptedShares[i-1] is p(i) masked for the participant at position i.
+	EncryptedShares []*big.Int
+}
+
+// DistributeKey deals a random key to the participants pks in the session, see KeyBox.
+// The U of the box is zero, the key is never reconstructed.
+func (d *Dealer) DistributeKey(pks []Element, session *Session) (*KeyBox, error) {
+	if session == nil {
+		return nil, errors.New("missing session")
+	}
+	threshold := session.Threshold
+	if threshold < 1 {
+		return nil, errors.New(fmt.Sprintf("invalid threshold(%d). ", threshold))
+	}
+	g, m := d.Group, OrderModulus(d.Group)
+	poly, err := InitPolynomial(threshold-1, g.Order())
+	if err != nil {
+		return nil, err
+	}
+	shares, err := d.prepareShares(pks, session)
+	if err != nil {
+		return nil, err
+	}
+	box, err := d.distribute(shares, session, poly, new(big.Int))
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := m.Random(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	dleq := NewScalarDLEQ(g, g.Generator(), nil, g.SecondGenerator(), box.Commitments[0], w, poly.coefficients[0])
+	c, r := dleq.ChallengeAndResponse(keyTranscript(g, session, d.PK))
+	kb := &KeyBox{
+		Box:             box,
+		DealerPK:        d.PK,
+		PublicKey:       dleq.H1,
+		Challenge:       c,
+		Response:        r,
+		EncryptedShares: make([]*big.Int, len(pks)),
+	}
+	for i, pk := range pks {
+		mask := m.FromBig(keyShareMask(g, session, g.ScalarMult(pk, d.privateKey.Big()), i+1))
+		share := poly.Evaluate(m.FromBig(big.NewInt(int64(i + 1))))
+		kb.EncryptedShares[i] = share.Add(share, mask).Big()
+	}
+	return kb, nil
+}
+
+// CheckKeyBox verifies publicly the key box, which is dealt on the group g in the expected session: the box, the range
+// of the encrypted shares and the proof of the public key.
+func CheckKeyBox(g Group, expected *Session, kb *KeyBox) error {
+	if kb == nil || kb.Box == nil {
+		return fmt.Errorf("%w: nil box", ErrInvalidKeyBox)
+	}
+	if err := CheckDistributionShares(g, expected, kb.Box); err != nil {
+		return err
+	}
+	if kb.Box.isSCRAPE() || kb.Box.Payload != nil || len(kb.EncryptedShares) != len(kb.Box.Shares) {
+		return fmt.Errorf("%w: malformed key box", ErrInvalidKeyBox)
+	}
+	for _, e := range kb.EncryptedShares {
+		if e == nil || e.Sign() < 0 || e.Cmp(g.Order()) >= 0 {
+			return fmt.Errorf("%w: malformed encrypted share", ErrInvalidKeyBox)
+		}
+	}
+	if kb.DealerPK == nil || isIdentity(g, kb.DealerPK) || kb.PublicKey == nil || kb.Challenge == nil || kb.Response == nil {
+		return fmt.Errorf("%w: malformed key box", ErrInvalidKeyBox)
+	}
+	ok := DLEQVerify(g, keyTranscript(g, expected, kb.DealerPK),
+		g.Generator(), kb.PublicKey, g.SecondGenerator(), kb.Box.Commitments[0], kb.Challenge, kb.Response)
+	if !ok {
+		return fmt.Errorf("%w: public key proof", ErrInvalidKeyBox)
+	}
+	return nil
+}
+
+// ExtractKeyShare unmasks the scalar share p(i) of the dealer, as a participant, from a key box checked by CheckKeyBox,
+// and returns it with its position i, after checking it against the commitments of the box.
+func (d *Dealer) ExtractKeyShare(kb *KeyBox) (position int, share *big.Int, err error) {
+	if kb == nil || kb.Box == nil || kb.DealerPK == nil {
+		return 0, nil, fmt.Errorf("%w: nil box", ErrInvalidKeyBox)
+	}
+	box := kb.Box
+	if !sameGroup(box.Group, d.Group) {
+		return 0, nil, ErrGroupMismatch
+	}
+	if box.Session == nil {
+		return 0, nil, errors.New("missing session")
+	}
+	for i, s := range box.Shares {
+		if s != nil && d.Group.Equal(s.PK, d.PK) {
+			position = i + 1
+			break
+		}
+	}
+	if position == 0 || len(kb.EncryptedShares) != len(box.Shares) || kb.EncryptedShares[position-1] == nil {
+		return 0, nil, errors.New("no share for me")
+	}
+	g, m := d.Group, OrderModulus(d.Group)
+	mask := m.FromBig(keyShareMask(g, box.Session, g.ScalarMult(kb.DealerPK, d.privateKey.Big()), position))
+	pi := m.NewScalar().Sub(m.FromBig(kb.EncryptedShares[position-1]), mask)
+	if !g.Equal(g.ScalarMult(g.SecondGenerator(), pi.Big()), CommitmentAt(g, box.Commitments, position)) {
+		return 0, nil, fmt.Errorf("%w: share %d does not match the commitments", ErrInvalidKeyBox, position)
+	}
+	return position, pi.Big(), nil
+}
+
+// keyTranscript returns the transcript of the proof of the public key of a key box, bound to the dealer.
+func keyTranscript(g Group, session *Session, dealerPK Element) *Transcript {
+	t := session.transcript(keyProtocol)
+	t.AppendElement(g, "dealer", dealerPK)
+	return t
+}
+
+// keyShareMask derives the mask of the share at the position from the Diffie-Hellman key of the dealer and the participant.
+func keyShareMask(g Group, session *Session, key Element, position int) *big.Int {
+	t := session.transcript(keyShareProtocol)
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(position))
+	t.AppendMessage("position", b[:])
+	t.AppendElement(g, "key", key)
+	return t.ChallengeScalar(g, "mask")
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pvss
+
+import (
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+func TestDistributeKey(t *testing.T) {
+	threshold, n := 3, 5
+	for _, g := range testGroups {
+		dealers, pks := genDealers(g, n+1)
+		session := testSession(g, pks[1:], threshold)
+		kb, err := dealers[0].DistributeKey(pks[1:], session)
+		require.NoError(t, err)
+		require.NoError(t, CheckKeyBox(g, session, kb), g.Name())
+
+		// the scalar shares interpolate the key of the public key
+		positions := []int{1, 3, 5}
+		x := new(big.Int)
+		for _, position := range positions {
+			i, share, err := dealers[position].ExtractKeyShare(kb)
+			require.NoError(t, err)
+			require.Equal(t, position, i)
+			require.True(t, g.Equal(g.ScalarMult(g.SecondGenerator(), share), CommitmentAt(g, kb.Box.Commitments, i)))
+			x.Add(x, new(big.Int).Mul(share, LagrangeCoefficient(i, positions, g.Order())))
+		}
+		require.True(t, g.Equal(g.ScalarBaseMult(x.Mod(x, g.Order())), kb.PublicKey), g.Name())
+
+		// the box is still a box, whose decrypted shares are p(i)·G
+		decShare, err := dealers[1].ExtractSecretShare(kb.Box)
+		require.NoError(t, err)
+		_, share, err := dealers[1].ExtractKeyShare(kb)
+		require.NoError(t, err)
+		require.True(t, g.Equal(g.ScalarBaseMult(share), decShare.S))
+	}
+}
+
+func TestDistributeKey_Rejects(t *testing.T) {
+	threshold, n := 2, 3
+	g := Secp256k1()
+	dealers, pks := genDealers(g, n+2)
+	session := testSession(g, pks[1:n+1], threshold)
+	kb, err := dealers[0].DistributeKey(pks[1:n+1], session)
+	require.NoError(t, err)
+
+	// a key box of another session, or with another public key or dealer
+	require.Error(t, CheckKeyBox(g, NewSession(g, session.ID, session.Epoch+1, pks[1:n+1], threshold), kb))
+	forged := *kb
+	forged.PublicKey = g.Generator()
+	require.ErrorIs(t, CheckKeyBox(g, session, &forged), ErrInvalidKeyBox)
+	forged = *kb
+	forged.DealerPK = pks[n+1]
+	require.ErrorIs(t, CheckKeyBox(g, session, &forged), ErrInvalidKeyBox)
+	_, _, err = dealers[1].ExtractKeyShare(&forged)
+	require.ErrorIs(t, err, ErrInvalidKeyBox)
+
+	// malformed or tampered encrypted shares
+	forged = *kb
+	forged.EncryptedShares = kb.EncryptedShares[:n-1]
+	require.ErrorIs(t, CheckKeyBox(g, session, &forged), ErrInvalidKeyBox)
+	forged.EncryptedShares = append([]*big.Int{g.Order()}, kb.EncryptedShares[1:]...)
+	require.ErrorIs(t, CheckKeyBox(g, session, &forged), ErrInvalidKeyBox)
+	forged.EncryptedShares = append([]*big.Int{new(big.Int).Add(kb.EncryptedShares[0], big.NewInt(1))}, kb.EncryptedShares[1:]...)
+	require.NoError(t, CheckKeyBox(g, session, &forged))
+	_, _, err = dealers[1].ExtractKeyShare(&forged)
+	require.ErrorIs(t, err, ErrInvalidKeyBox)
+	_, _, err = dealers[2].ExtractKeyShare(&forged)
+	require.NoError(t, err)
+
+	// not a participant
+	_, _, err = dealers[n+1].ExtractKeyShare(kb)
+	require.Error(t, err)
+}
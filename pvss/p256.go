@@ -0,0 +1,217 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pvss
+
+import (
+	"crypto/elliptic"
+	"crypto/sha256"
+	"github.com/stars-labs/go-pvss/crypto/secp256k1"
+	"math/big"
+)
+
+// The suite P256_XMD:SHA-256_SSWU_RO_ of RFC 9380, P-256 has A = -3, so the simplified SWU map runs on the curve itself.
+var (
+	p256SSWUZ = big.NewInt(-10) // Z, see RFC 9380 section 8.2
+	p256A     = big.NewInt(-3)
+)
+
+// p256HashToFieldLen is L = ceil((ceil(log2(p)) + k) / 8) of the suite, where k = 128.
+const p256HashToFieldLen = 48
+
+// p256Group is the NIST P-256 curve, its keys are interchangeable with crypto/ecdsa P-256 keys.
+// Elements are *Point, and encoded in the compressed SEC1 format, except that the point at infinity is encoded as 33 zero bytes.
+type p256Group struct {
+	curve elliptic.Curve
+	g     *Point
+	h     *Point
+}
+
+var theP256 = newP256Group()
+
+func newP256Group() *p256Group {
+	curve := elliptic.P256()
+	g := &p256Group{
+		curve: curve,
+		g:     &Point{curve.Params().Gx, curve.Params().Gy},
+	}
+	g.h = toPoint(g.deriveSecondGenerator())
+	return g
+}
+
+func init() {
+	RegisterGroup(theP256)
+}
+
+// P256 returns the NIST P-256 group.
+func P256() Group {
+	return theP256
+}
+
+func (c *p256Group) Name() string {
+	return "P-256"
+}
+
+func (c *p256Group) Order() *big.Int {
+	return c.curve.Params().N
+}
+
+func (c *p256Group) Generator() Element {
+	return c.g
+}
+
+func (c *p256Group) SecondGenerator() Element {
+	return c.h
+}
+
+func (c *p256Group) Identity() Element {
+	return &Point{X: new(big.Int), Y: new(big.Int)}
+}
+
+func (c *p256Group) Add(a, b Element) Element {
+	p, q := toPoint(a), toPoint(b)
+	if isInfinity(p) {
+		return q
+	}
+	if isInfinity(q) {
+		return p
+	}
+	x, y := c.curve.Add(p.X, p.Y, q.X, q.Y)
+	return &Point{X: x, Y: y}
+}
+
+func (c *p256Group) Neg(a Element) Element {
+	p := toPoint(a)
+	if isInfinity(p) {
+		return p
+	}
+	return &Point{X: p.X, Y: new(big.Int).Sub(c.curve.Params().P, p.Y)}
+}
+
+func (c *p256Group) ScalarMult(a Element, k *big.Int) Element {
+	p := toPoint(a)
+	k = new(big.Int).Mod(k, c.Order())
+	if isInfinity(p) || k.Sign() == 0 {
+		return c.Identity()
+	}
+	x, y := c.curve.ScalarMult(p.X, p.Y, k.Bytes())
+	return &Point{X: x, Y: y}
+}
+
+func (c *p256Group) ScalarBaseMult(k *big.Int) Element {
+	k = new(big.Int).Mod(k, c.Order())
+	if k.Sign() == 0 {
+		return c.Identity()
+	}
+	x, y := c.curve.ScalarBaseMult(k.Bytes())
+	return &Point{X: x, Y: y}
+}
+
+func (c *p256Group) Equal(a, b Element) bool {
+	p, q := toPoint(a), toPoint(b)
+	return p.X.Cmp(q.X) == 0 && p.Y.Cmp(q.Y) == 0
+}
+
+func (c *p256Group) ElementLen() int {
+	return 33
+}
+
+func (c *p256Group) Encode(a Element) []byte {
+	p := toPoint(a)
+	if isInfinity(p) {
+		return make([]byte, c.ElementLen())
+	}
+	return elliptic.MarshalCompressed(c.curve, p.X, p.Y)
+}
+
+func (c *p256Group) Decode(data []byte) (Element, error) {
+	if len(data) != c.ElementLen() {
+		return nil, ErrInvalidPoint
+	}
+	if isZeros(data) {
+		return c.Identity(), nil
+	}
+	x, y := elliptic.UnmarshalCompressed(c.curve, data)
+	if x == nil {
+		return nil, ErrInvalidPoint
+	}
+	return &Point{X: x, Y: y}, nil
+}
+
+// HashToElement implements hash_to_curve of the suite P256_XMD:SHA-256_SSWU_RO_, see RFC 9380.
+// The point at infinity, which happens with negligible probability, is returned as (0, 0), like the identity.
+func (c *p256Group) HashToElement(msg, dst []byte) Element {
+	p := c.curve.Params().P
+	uniform := secp256k1.ExpandMessageXMD(sha256.New, msg, dst, 2*p256HashToFieldLen)
+	u0 := new(big.Int).SetBytes(uniform[:p256HashToFieldLen])
+	u1 := new(big.Int).SetBytes(uniform[p256HashToFieldLen:])
+	x0, y0 := c.mapToCurve(u0.Mod(u0, p))
+	x1, y1 := c.mapToCurve(u1.Mod(u1, p))
+	// the cofactor of P-256 is 1, so clear_cofactor is a no-op, and Add handles Q0 == ±Q1
+	x, y := c.curve.Add(x0, y0, x1, y1)
+	return &Point{X: x, Y: y}
+}
+
+// mapToCurve maps the field element u to P-256 by map_to_curve_simple_swu, RFC 9380 section 6.6.2.
+func (c *p256Group) mapToCurve(u *big.Int) (x, y *big.Int) {
+	params := c.curve.Params()
+	p := params.P
+	z, a := new(big.Int).Mod(p256SSWUZ, p), new(big.Int).Mod(p256A, p)
+
+	// tv1 = inv0(Z²·u⁴ + Z·u²)
+	zu2 := new(big.Int).Mul(u, u)
+	zu2.Mul(zu2, z).Mod(zu2, p)
+	tv1 := new(big.Int).Mul(zu2, zu2)
+	tv1.Add(tv1, zu2).Mod(tv1, p)
+	var x1 *big.Int
+	if tv1.Sign() == 0 {
+		// x1 = B / (Z·A)
+		x1 = new(big.Int).Mul(z, a)
+		x1.ModInverse(x1, p)
+		x1.Mul(x1, params.B)
+	} else {
+		// x1 = (-B / A)·(1 + tv1)
+		tv1.ModInverse(tv1, p)
+		x1 = new(big.Int).ModInverse(a, p)
+		x1.Mul(x1, new(big.Int).Sub(p, params.B))
+		x1.Mul(x1, tv1.Add(tv1, big.NewInt(1)))
+	}
+	x1.Mod(x1, p)
+
+	x, y = x1, new(big.Int).ModSqrt(c.polynomial(x1), p)
+	if y == nil {
+		// x2 = Z·u²·x1
+		x = new(big.Int).Mul(zu2, x1)
+		x.Mod(x, p)
+		y = new(big.Int).ModSqrt(c.polynomial(x), p)
+	}
+	if u.Bit(0) != y.Bit(0) {
+		y.Sub(p, y).Mod(y, p)
+	}
+	return x, y
+}
+
+// polynomial returns x³ - 3·x + B of the curve.
+func (c *p256Group) polynomial(x *big.Int) *big.Int {
+	params := c.curve.Params()
+	gx := new(big.Int).Mul(x, x)
+	gx.Add(gx, p256A)
+	gx.Mul(gx, x)
+	gx.Add(gx, params.B)
+	return gx.Mod(gx, params.P)
+}
+
+// deriveSecondGenerator hashes the encoding of G to H, so no one knows log_G(H).
+func (c *p256Group) deriveSecondGenerator() Element {
+	return c.HashToElement(c.Encode(c.g), []byte("go-pvss-P256-H"))
+}
+
+// Curve returns the elliptic curve of the group, whose crypto/ecdsa keys can be used by the participants.
+func (c *p256Group) Curve() elliptic.Curve {
+	return c.curve
+}
@@ -9,69 +9,139 @@
 package pvss
 
 import (
-	"crypto/ecdsa"
+	"bytes"
 	"crypto/rand"
 	"errors"
 	"fmt"
+	"github.com/stars-labs/go-pvss/crypto/scalar"
 	"golang.org/x/crypto/sha3"
 	"math/big"
 )
 
+// secretLength is the length of U in bytes, the length of the mask SHA3-256(s·G), so a secret has at most 256 bits:
+// any higher bit would be left in U in the clear.
+const secretLength = 32
+
+// ErrSecretTooLarge is returned for a secret which doesn't fit in the secretLength bytes of U.
+var ErrSecretTooLarge = errors.New("secret is longer than 256 bits")
+
 type Participant struct {
-	PK        *ecdsa.PublicKey
+	Group     Group
+	PK        Element
 	position  int
-	share     *Point
+	share     Element
 	challenge *big.Int
 	response  *big.Int
 }
 
 type Dealer struct {
 	Participant
-	privateKey *ecdsa.PrivateKey
+	privateKey *scalar.Scalar
 }
 
-func NewDealer(privateKey *ecdsa.PrivateKey) *Dealer {
+// NewDealer creates a dealer on the group g, whose public key is privateKey·G.
+func NewDealer(g Group, privateKey *big.Int) *Dealer {
 	return &Dealer{
-		Participant: Participant{PK: &privateKey.PublicKey},
-		privateKey:  privateKey,
+		Participant: Participant{Group: g, PK: g.ScalarBaseMult(privateKey)},
+		privateKey:  OrderModulus(g).FromBig(privateKey),
 	}
 }
 
-func (d *Dealer) DistributeSecret(secret *big.Int, pks []*ecdsa.PublicKey, threshold int) (*DistributionSharesBox, error) {
-	if len(pks) < threshold {
-		return nil, errors.New(fmt.Sprintf("len of pubkeys(%d) < threshold(%d). ", len(pks), threshold))
+// DistributeSecret shares the secret to the participants pks in the session, whose threshold is the number of
+// shares needed to reconstruct the secret. The session must be created for the same participants.
+// The secret is hidden by U, so it's a non-negative number of at most 256 bits.
+func (d *Dealer) DistributeSecret(secret *big.Int, pks []Element, session *Session) (*DistributionSharesBox, error) {
+	if session == nil {
+		return nil, errors.New("missing session")
+	}
+	threshold := session.Threshold
+	if threshold < 1 {
+		return nil, errors.New(fmt.Sprintf("invalid threshold(%d). ", threshold))
 	}
 	// generates a random polynomial of degree t-1
-	poly, err := InitPolynomial(threshold-1, secp256k1N)
+	poly, err := InitPolynomial(threshold-1, d.Group.Order())
+	if err != nil {
+		return nil, err
+	}
+	return d.DistributePolynomial(secret, poly, pks, session)
+}
+
+// DistributePolynomial shares the secret like DistributeSecret, but on a polynomial p chosen by the caller,
+// whose degree must be threshold-1. The participant at position i gets p(i)·G, and the secret is hidden by p(0)·G.
+// The caller is responsible for the randomness of p.
+// The secret must be a non-negative number of at most 256 bits, otherwise ErrSecretTooLarge is returned.
+func (d *Dealer) DistributePolynomial(secret *big.Int, poly *Polynomial, pks []Element, session *Session) (*DistributionSharesBox, error) {
+	if session == nil {
+		return nil, errors.New("missing session")
+	}
+	threshold := session.Threshold
+	if threshold < 1 || poly == nil || poly.Degree() != threshold-1 {
+		return nil, errors.New(fmt.Sprintf("invalid threshold(%d) for the polynomial. ", threshold))
+	}
+	if err := checkSecretRange(secret); err != nil {
+		return nil, err
+	}
+	shares, err := d.prepareShares(pks, session)
 	if err != nil {
 		return nil, err
 	}
+
+	// Calc U = secret xor SHA256(s · G) = secret xor SHA256(p(0)·G).
+	// The paper uses prime scheme, in [Section 4]
+	// σ ∈ Σ, where 2 ≤ |Σ| ≤ q.
+	// the general procedure is to let the dealer first run the distribution protocol for a random value s ∈ Zq, and then publish U = σ ⊕ H(G^s),
+	// where H is an appropriate cryptographic hash function. The reconstruction protocol will yield G^s, from which we obtain σ = U ⊕ H(G^s).
+	// U is bound to the proofs of the shares, see distributionTranscript.
+	u := maskSecret(d.Group, d.Group.ScalarBaseMult(poly.coefficients[0].Big()), secret)
+	return d.distribute(shares, session, poly, u)
+}
+
+// checkSecretRange checks that the secret fits in U.
+func checkSecretRange(secret *big.Int) error {
+	if secret == nil || secret.Sign() < 0 {
+		return errors.New("invalid secret")
+	}
+	if secret.BitLen() > 8*secretLength {
+		return ErrSecretTooLarge
+	}
+	return nil
+}
+
+// prepareShares checks the participants against the session, and initializes their shares.
+func (d *Dealer) prepareShares(pks []Element, session *Session) ([]*Share, error) {
+	if len(pks) < session.Threshold {
+		return nil, errors.New(fmt.Sprintf("len of pubkeys(%d) < threshold(%d). ", len(pks), session.Threshold))
+	}
 	// initialize the participant's Position
 	shares := make([]*Share, len(pks))
 	for i, pk := range pks {
+		if pk == nil || isIdentity(d.Group, pk) {
+			return nil, errors.New(fmt.Sprintf("invalid pubkey of participant %d. ", i+1))
+		}
 		shares[i] = &Share{
+			Group:    d.Group,
 			PK:       pk,
 			Position: i + 1,
 		}
 	}
-
-	return d.distribute(secret, shares, threshold, poly)
+	if !bytes.Equal(session.ParticipantsHash, HashParticipants(d.Group, pks)) {
+		return nil, fmt.Errorf("%w: participants do not match the session", ErrSessionMismatch)
+	}
+	return shares, nil
 }
 
-func (d *Dealer) distribute(secret *big.Int, shares []*Share, threshold int, poly *Polynomial) (*DistributionSharesBox, error) {
+// distribute encrypts the shares of the polynomial and proves them, with U, what s = p(0) hides in the box, bound to the proofs.
+func (d *Dealer) distribute(shares []*Share, session *Session, poly *Polynomial, u *big.Int) (*DistributionSharesBox, error) {
+	g := d.Group
+	m := OrderModulus(g)
+	H := g.SecondGenerator()
 
 	// Calculate Polynomial Coefficients Commitments C_j := a_j·H , and  0 <= j < threshold
-	commitments := make([]*Point, 0, threshold)
-	for _, a_j := range poly.coefficients {
-		x, y := theCurve.ScalarMult(Hx, Hy, a_j.Bytes())
-		commitments = append(commitments, &Point{x, y})
-	}
+	commitments := poly.Commit(g, H)
 	// DLEQ(H,X_i,PK_i,Y_i)
 	// publicly shared values: Y_i, c_i,r_i, commitments
 	// and common known values: G,H,PK_i,
-	//reusable vars
-	bigI := new(big.Int)
-	hasher := sha3.New256()
+	transcript := distributionTranscript(session, distributionProtocol, u)
 	for _, share := range shares {
 		// Calculate Every Encrypted shares with every participant's public key generated from their own private key
 		// Y_i := (p(i)mod N)·PK_i  X_i := p(i)·H =  C_0·(i^0) + C_1·(i^1) + C_2^(i^2) + ... + C_j·(i^j)  and 1 <= i <= n  0 <= j <= threshold - 1
@@ -79,41 +149,45 @@ func (d *Dealer) distribute(secret *big.Int, shares []*Share, threshold int, pol
 		// p(i) is secret share without encrypt on the ploynomial of the degree t - 1
 		// PK_i is participant's public key
 		// Y_i is encrypted secret share
-		bigI.SetInt64(int64(share.Position))
-		pi := poly.GetValue(bigI, secp256k1N) // alpha
-		wi, err := rand.Int(rand.Reader, secp256k1N)
+		pi := poly.Evaluate(m.FromBig(big.NewInt(int64(share.Position)))) // alpha
+		wi, err := m.Random(rand.Reader)
 		if err != nil {
 			return nil, err
 		}
-		dleq := NewDLEQ(&Point{Hx, Hy}, nil, &Point{share.PK.X, share.PK.Y}, nil, wi, pi)
-		//log.Printf("Dealer Xi: %s, %s\n", dleq.H1.X.Text(16), dleq.H1.Y.Text(16))
+		dleq := NewScalarDLEQ(g, H, nil, share.PK, nil, wi, pi)
 
 		share.S = dleq.H2 // Y_i == H2
-		share.challenge, share.response = dleq.ChallengeAndResponse()
+		share.a1, share.a2, share.challenge, share.response = dleq.prove(transcript)
 	}
 
-	// Calc U = secret xor SHA256(s · G) = secret xor SHA256(p(0)·G).
-	// The paper uses prime scheme, in [Section 4]
-	// σ ∈ Σ, where 2 ≤ |Σ| ≤ q.
-	// the general procedure is to let the dealer first run the distribution protocol for a random value s ∈ Zq, and then publish U = σ ⊕ H(G^s),
-	// where H is an appropriate cryptographic hash function. The reconstruction protocol will yield G^s, from which we obtain σ = U ⊕ H(G^s).
-
-	sGx, sGy := theCurve.ScalarBaseMult(poly.coefficients[0].Bytes())
-	hash256 := Hash(hasher, sGx, sGy)
-	u := new(big.Int).Xor(secret, new(big.Int).SetBytes(hash256))
-
 	return &DistributionSharesBox{
+		Group:       g,
+		Session:     session,
 		Commitments: commitments,
 		Shares:      shares,
 		U:           u,
 	}, nil
 }
 
+// distributionTranscript returns the transcript of the proofs of the shares of a box in the session, to which U is appended:
+// a box whose U is replaced, after it's dealt, fails the verification instead of yielding another secret.
+func distributionTranscript(session *Session, protocol string, u *big.Int) *Transcript {
+	t := session.transcript(protocol)
+	t.AppendMessage("U", u.FillBytes(make([]byte, secretLength)))
+	return t
+}
+
 func (d *Dealer) ExtractSecretShare(sharesBox *DistributionSharesBox) (*DecryptedShare, error) {
+	if !sameGroup(sharesBox.Group, d.Group) {
+		return nil, ErrGroupMismatch
+	}
+	if sharesBox.Session == nil {
+		return nil, errors.New("missing session")
+	}
 	// find share for the dealer itself
 	var share *Share
 	for _, s := range sharesBox.Shares {
-		if s.PK.X.Cmp(d.privateKey.X) == 0 && s.PK.Y.Cmp(d.privateKey.Y) == 0 {
+		if d.Group.Equal(s.PK, d.PK) {
 			share = s
 			break
 		}
@@ -121,16 +195,17 @@ func (d *Dealer) ExtractSecretShare(sharesBox *DistributionSharesBox) (*Decrypte
 	if share == nil {
 		return nil, errors.New("no share for me")
 	}
-	return d.extractSecretShare(share)
+	return d.extractSecretShare(sharesBox.Session, share)
 }
 
-func (d *Dealer) extractSecretShare(share *Share) (*DecryptedShare, error) {
+func (d *Dealer) extractSecretShare(session *Session, share *Share) (*DecryptedShare, error) {
+	g := d.Group
 	// Decryption of the shares.
 	// Using its private key x_i, each participant finds the decrypted share S_i from Y_i by computing S_i = Y_i·(1/x_i mod N).
 	// Y_i is encrypted share: Y_i := (p(i)mod N)·PK_i
-	// find modular multiplicative inverses of private key
-	privateInverse := new(big.Int).ModInverse(d.privateKey.D, secp256k1N)
-	six, siy := theCurve.ScalarMult(share.S.X, share.S.Y, privateInverse.Bytes())
+	// find modular multiplicative inverses of private key, in constant time
+	privateInverse := OrderModulus(g).NewScalar().Inverse(d.privateKey)
+	si := g.ScalarMult(share.S, privateInverse.Big())
 
 	// To this end it suffices to prove knowledge of an α such that PK_i= G·α and Y'_i= S_i·α,
 	// which is accomplished by the non-interactive version of the protocol DLEQ(G,PK_i,S_i,Y'_i).
@@ -138,30 +213,50 @@ func (d *Dealer) extractSecretShare(share *Share) (*DecryptedShare, error) {
 	// where the encryted_share IS NOT the distributed share, but IS the value x_i·S_i .
 	// All of this is to prove and tell participants that the decrypted share is must use your own public key encrypted,
 	// and only you can decrypt the share with your own private key and verify the share's proof.
-	w, err := rand.Int(rand.Reader, secp256k1N)
+	w, err := OrderModulus(g).Random(rand.Reader)
 	if err != nil {
 		return nil, err
 	}
-	dleq := NewDLEQ(G1, &Point{d.PK.X, d.PK.Y}, &Point{six, siy}, nil, w, d.privateKey.D)
-	c, r := dleq.ChallengeAndResponse()
+	dleq := NewScalarDLEQ(g, g.Generator(), d.PK, si, nil, w, d.privateKey)
+	a1, a2, c, r := dleq.prove(session.transcript(decryptionProtocol))
 	decShare := &DecryptedShare{
+		Group:     g,
 		PK:        d.PK,
 		Position:  share.Position,
 		S:         dleq.G2,
 		Y:         dleq.H2,
 		challenge: c,
 		response:  r,
+		a1:        a1,
+		a2:        a2,
 	}
 	return decShare, nil
 }
 
 // VerifyDistributionShares verifies that the distribution shares are consistent so that they can be used to reconstruct the secret later.
-func VerifyDistributionShares(sharesBox *DistributionSharesBox) bool {
-	if sharesBox == nil {
-		return false
+// The box must be dealt on the group g in the expected session.
+func VerifyDistributionShares(g Group, expected *Session, sharesBox *DistributionSharesBox) bool {
+	return CheckDistributionShares(g, expected, sharesBox) == nil
+}
+
+// CheckDistributionShares verifies the distribution shares like VerifyDistributionShares, and reports why they are not consistent.
+// The first faulty share is reported as a *ShareVerificationError, other errors are about the whole box.
+func CheckDistributionShares(g Group, expected *Session, sharesBox *DistributionSharesBox) error {
+	return checkDistributionShares(g, expected, sharesBox, false)
+}
+
+// CheckAllDistributionShares verifies the distribution shares like CheckDistributionShares, but goes on after a faulty share,
+// and reports all of them in the position order as ShareVerificationErrors.
+func CheckAllDistributionShares(g Group, expected *Session, sharesBox *DistributionSharesBox) error {
+	return checkDistributionShares(g, expected, sharesBox, true)
+}
+
+func checkDistributionShares(g Group, expected *Session, sharesBox *DistributionSharesBox, all bool) error {
+	if err := checkBox(g, expected, sharesBox); err != nil {
+		return err
 	}
-	if len(sharesBox.Shares) < len(sharesBox.Commitments) {
-		return false
+	if sharesBox.isSCRAPE() {
+		return checkSCRAPEShares(g, expected, sharesBox, all)
 	}
 
 	// Verification of the shares.
@@ -169,44 +264,182 @@ func VerifyDistributionShares(sharesBox *DistributionSharesBox) bool {
 	// Using PK_i,X_i,Y_i,r_i,c_i 1 ≤ i ≤ n as input, the verifier computes A_1i,A_2i as:
 	// A_1i = H·(r_i) + X_i·c_i,   A_2i = PK_i·(r_i) + Y_i·c_i
 	// and checks that the hash of X_i,Y_i, A_1i, A_2i,  1 ≤ i ≤ n, matches c_i.
+	// The proofs are verified together, see proofBatch.
+	transcript := distributionTranscript(expected, distributionProtocol, sharesBox.U)
+	errs := checkShares(g, transcript, sharesBox.Commitments, sharesBox.Shares)
+	if len(errs) == 0 {
+		return nil
+	}
+	if !all {
+		return errs[0]
+	}
+	return errs
+}
 
-	// variables for reuse
-	hasher := sha3.New256()
-
-	Cj := sharesBox.Commitments
-	bigi, bigj, bigij := new(big.Int), new(big.Int), new(big.Int)
-	H := &Point{Hx, Hy}
-
-	Xix, Xiy, Cijx, Cijy := new(big.Int), new(big.Int), new(big.Int), new(big.Int)
-	for _, share := range sharesBox.Shares {
-		Xix.Set(Cj[0].X)
-		Xiy.Set(Cj[0].Y)
-		for j := 1; j < len(Cj); j++ {
-			bigi.SetInt64(int64(share.Position))
-			bigj.SetInt64(int64(j))
-			bigij.Exp(bigi, bigj, secp256k1N)                                 // i^j mod N
-			Cijx, Cijy = theCurve.ScalarMult(Cj[j].X, Cj[j].Y, bigij.Bytes()) // C_j · i^j
-			Xix, Xiy = theCurve.Add(Xix, Xiy, Cijx, Cijy)
+// checkShares verifies the shares against the commitments Cj, their proofs as a batch, and returns the faulty ones
+// in the position order.
+func checkShares(g Group, transcript *Transcript, Cj []Element, shares []*Share) ShareVerificationErrors {
+	errs := make([]*ShareVerificationError, len(shares))
+	batch := newProofBatch(g)
+	H := g.SecondGenerator()
+	for i, share := range shares {
+		if err := checkShareFields(g, i+1, share); err != nil {
+			errs[i] = err
+			continue
 		}
-		//log.Printf("Verify Xi: %s, %s\n", Xix.Text(16), Xiy.Text(16))
-
 		// DLEQ(H,X_i,PK_i,Y_i)
-		ok := DLEQVerify(hasher, H, &Point{X: Xix, Y: Xiy}, &Point{X: share.PK.X, Y: share.PK.Y}, share.S, share.challenge, share.response)
-		if !ok {
-			return false
+		Xi := CommitmentAt(g, Cj, i+1)
+		batch.add(i, transcript, H, Xi, share.PK, share.S, share.a1, share.a2, share.challenge, share.response)
+	}
+	for i := range batch.failures() {
+		errs[i] = &ShareVerificationError{Position: i + 1, PK: shares[i].PK, Reason: ErrInvalidProof}
+	}
+	return compactShareErrors(errs)
+}
+
+// checkBox checks the shape of the box and its session, before its shares are verified.
+func checkBox(g Group, expected *Session, sharesBox *DistributionSharesBox) error {
+	if sharesBox == nil {
+		return fmt.Errorf("%w: nil box", ErrInvalidBox)
+	}
+	if !sameGroup(sharesBox.Group, g) {
+		return ErrGroupMismatch
+	}
+	if len(sharesBox.Commitments) == 0 || len(sharesBox.Shares) < len(sharesBox.Commitments) {
+		return fmt.Errorf("%w: %d commitments for %d shares", ErrInvalidBox, len(sharesBox.Commitments), len(sharesBox.Shares))
+	}
+	if sharesBox.U == nil || sharesBox.U.Sign() < 0 || sharesBox.U.BitLen() > 8*secretLength {
+		return fmt.Errorf("%w: invalid U", ErrInvalidBox)
+	}
+	return checkSession(g, expected, sharesBox)
+}
+
+// checkShare verifies the share at the position against the commitments Cj.
+func checkShare(g Group, transcript *Transcript, Cj []Element, position int, share *Share) *ShareVerificationError {
+	if err := checkShareFields(g, position, share); err != nil {
+		return err
+	}
+
+	Xi := CommitmentAt(g, Cj, position)
+
+	// DLEQ(H,X_i,PK_i,Y_i)
+	if !DLEQVerify(g, transcript, g.SecondGenerator(), Xi, share.PK, share.S, share.challenge, share.response) {
+		return &ShareVerificationError{Position: position, PK: share.PK, Reason: ErrInvalidProof}
+	}
+	return nil
+}
+
+// CommitmentAt returns the commitment X_i = ∑ C_j · i^j = p(i)·H of the share at the position i from the commitments Cj
+// of the coefficients, by a single multi-scalar multiplication.
+func CommitmentAt(g Group, Cj []Element, position int) Element {
+	powers := make([]*big.Int, len(Cj))
+	bigi := big.NewInt(int64(position))
+	powers[0] = big.NewInt(1)
+	for j := 1; j < len(Cj); j++ {
+		powers[j] = new(big.Int).Mul(powers[j-1], bigi) // i^j mod N
+		powers[j].Mod(powers[j], g.Order())
+	}
+	return multiScalarMult(g, Cj, powers)
+}
+
+// checkShareFields checks that the share at the position is complete, before its proof is verified.
+func checkShareFields(g Group, position int, share *Share) *ShareVerificationError {
+	if share == nil {
+		return &ShareVerificationError{Position: position, Reason: ErrMissingShare}
+	}
+	fail := func(reason error) *ShareVerificationError {
+		return &ShareVerificationError{Position: position, PK: share.PK, Reason: reason}
+	}
+	if share.Position != position {
+		return fail(ErrInvalidPosition)
+	}
+	if !sameGroup(share.Group, g) {
+		return fail(ErrGroupMismatch)
+	}
+	if share.PK == nil || share.S == nil || share.challenge == nil || share.response == nil {
+		return fail(ErrMissingProof)
+	}
+	return nil
+}
+
+// VerifyDecryptedShare verify a decrypted share publicly, the share must be decrypted on the group g in the session.
+func VerifyDecryptedShare(g Group, session *Session, decShare *DecryptedShare) bool {
+	return CheckDecryptedShare(g, session, decShare) == nil
+}
+
+// CheckDecryptedShare verifies a decrypted share like VerifyDecryptedShare, and reports why it's not valid.
+// A faulty share is reported as a *ShareVerificationError.
+func CheckDecryptedShare(g Group, session *Session, decShare *DecryptedShare) error {
+	if session == nil {
+		return fmt.Errorf("%w: missing session", ErrSessionMismatch)
+	}
+	if err := checkDecryptedShareFields(g, decShare); err != nil {
+		return err
+	}
+	transcript := session.transcript(decryptionProtocol)
+	if !DLEQVerify(g, transcript, g.Generator(), decShare.PK, decShare.S, decShare.Y, decShare.challenge, decShare.response) {
+		return &ShareVerificationError{Position: decShare.Position, PK: decShare.PK, Reason: ErrInvalidProof}
+	}
+	return nil
+}
+
+// VerifyDecryptedShares verifies many decrypted shares publicly like VerifyDecryptedShare, with their proofs verified as a batch.
+func VerifyDecryptedShares(g Group, session *Session, decShares []*DecryptedShare) bool {
+	return CheckDecryptedShares(g, session, decShares) == nil
+}
+
+// CheckDecryptedShares verifies many decrypted shares like CheckDecryptedShare, with their proofs verified as a batch,
+// and reports all the faulty ones in the order of the shares as ShareVerificationErrors.
+func CheckDecryptedShares(g Group, session *Session, decShares []*DecryptedShare) error {
+	if session == nil {
+		return fmt.Errorf("%w: missing session", ErrSessionMismatch)
+	}
+	errs := make([]*ShareVerificationError, len(decShares))
+	batch := newProofBatch(g)
+	transcript := session.transcript(decryptionProtocol)
+	for i, ds := range decShares {
+		if err := checkDecryptedShareFields(g, ds); err != nil {
+			errs[i] = err
+			continue
 		}
+		// DLEQ(G,PK_i,S_i,Y_i)
+		batch.add(i, transcript, g.Generator(), ds.PK, ds.S, ds.Y, ds.a1, ds.a2, ds.challenge, ds.response)
+	}
+	for i := range batch.failures() {
+		errs[i] = &ShareVerificationError{Position: decShares[i].Position, PK: decShares[i].PK, Reason: ErrInvalidProof}
 	}
-	return true
+	if errs := compactShareErrors(errs); len(errs) != 0 {
+		return errs
+	}
+	return nil
 }
 
-// VerifyDecryptedShare verify a decrypted share publicly.
-func VerifyDecryptedShare(decShare *DecryptedShare) bool {
-	hasher := sha3.New256()
-	return DLEQVerify(hasher, G1, &Point{decShare.PK.X, decShare.PK.Y}, decShare.S, decShare.Y, decShare.challenge, decShare.response)
+// checkDecryptedShareFields checks that the decrypted share is complete, before its proof is verified.
+func checkDecryptedShareFields(g Group, decShare *DecryptedShare) *ShareVerificationError {
+	if decShare == nil {
+		return &ShareVerificationError{Reason: ErrMissingShare}
+	}
+	fail := func(reason error) *ShareVerificationError {
+		return &ShareVerificationError{Position: decShare.Position, PK: decShare.PK, Reason: reason}
+	}
+	if !sameGroup(decShare.Group, g) {
+		return fail(ErrGroupMismatch)
+	}
+	if decShare.PK == nil || decShare.S == nil || decShare.Y == nil || decShare.challenge == nil || decShare.response == nil {
+		return fail(ErrMissingProof)
+	}
+	return nil
 }
 
-// ReconstructSecret reconstruct the secret publicly by using no-less-than threshold number of decrypted shares.
-func ReconstructSecret(decShares []*DecryptedShare, u *big.Int) *big.Int {
+// ReconstructSecret reconstruct the secret publicly by using no-less-than threshold number of decrypted shares on the group g.
+// The shares are not verified, see Reconstruct for the reconstruction which authenticates its inputs against the box.
+func ReconstructSecret(g Group, decShares []*DecryptedShare, u *big.Int) *big.Int {
+	// secret = U xor SHA256(s · G)
+	return maskSecret(g, reconstructSG(g, decShares), u)
+}
+
+// reconstructSG returns s·G interpolated from the decrypted shares.
+func reconstructSG(g Group, decShares []*DecryptedShare) Element {
 	// Pooling the shares. Suppose
 	// w.l.o.g. that  participants P(i) produce  correct values for S_i, for i= 1,...,t.
 	// The secret s·G is obtained by Lagrange interpolation:
@@ -216,18 +449,29 @@ func ReconstructSecret(decShares []*DecryptedShare, u *big.Int) *big.Int {
 	for _, ds := range decShares {
 		bigjs[ds.Position] = big.NewInt(int64(ds.Position))
 	}
-	sGx, sGy := new(big.Int), new(big.Int)
-	for _, ds := range decShares {
+	points, lambdas := make([]Element, len(decShares)), make([]*big.Int, len(decShares))
+	for i, ds := range decShares {
 		//  λ_i
-		lambda := lagrangeCoefficient(ds.Position, bigjs)
-		lambdaSix, lambdaSiy := theCurve.ScalarMult(ds.S.X, ds.S.Y, lambda.Bytes())
-		sGx, sGy = theCurve.Add(sGx, sGy, lambdaSix, lambdaSiy)
+		points[i], lambdas[i] = ds.S, lagrangeCoefficient(ds.Position, bigjs, g.Order())
 	}
+	return multiScalarMult(g, points, lambdas)
+}
 
-	// secret = U xor SHA256(s · G)
-	hash256 := Hash(sha3.New256(), sGx, sGy)
-	secret := new(big.Int).Xor(u, new(big.Int).SetBytes(hash256))
-	return secret
+// maskSecret returns value xor SHA3-256(s·G), it hides the secret as U, and recovers the secret from U.
+func maskSecret(g Group, sG Element, value *big.Int) *big.Int {
+	hasher := sha3.New256()
+	hasher.Write(g.Encode(sG))
+	return new(big.Int).Xor(value, new(big.Int).SetBytes(hasher.Sum(nil)))
+}
+
+// LagrangeCoefficient returns the Lagrange coefficient λ_i = ∏(j≠i) j/(j−i) mod n of the position i, among the distinct
+// positions, which interpolates p(0) from the values p(j) at the positions.
+func LagrangeCoefficient(i int, positions []int, n *big.Int) *big.Int {
+	bigjs := make(map[int]*big.Int, len(positions))
+	for _, j := range positions {
+		bigjs[j] = big.NewInt(int64(j))
+	}
+	return lagrangeCoefficient(i, bigjs, n)
 }
 
 // lagrangeCoefficient returns  λ_i
@@ -236,7 +480,7 @@ func ReconstructSecret(decShares []*DecryptedShare, u *big.Int) *big.Int {
 // 1 <= i <= threshold  0 <= j < threshold
 // bigjs is a map of j->big.NewInt(j)
 // The returned values are already mod n
-func lagrangeCoefficient(i int, bigjs map[int]*big.Int) *big.Int {
+func lagrangeCoefficient(i int, bigjs map[int]*big.Int, n *big.Int) *big.Int {
 	numerator, denominator := big.NewInt(1), big.NewInt(1)
 	jsubi := new(big.Int)
 	bi := big.NewInt(int64(i))
@@ -248,9 +492,9 @@ func lagrangeCoefficient(i int, bigjs map[int]*big.Int) *big.Int {
 			denominator.Mul(denominator, jsubi)
 		}
 	}
-	numerator.Mod(numerator, secp256k1N)
-	inverseDenom := new(big.Int).ModInverse(denominator, secp256k1N)
+	numerator.Mod(numerator, n)
+	inverseDenom := new(big.Int).ModInverse(denominator, n)
 	numerator.Mul(numerator, inverseDenom)
-	numerator.Mod(numerator, secp256k1N)
+	numerator.Mod(numerator, n)
 	return numerator
 }
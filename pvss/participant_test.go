@@ -9,28 +9,40 @@
 package pvss
 
 import (
-	"crypto/ecdsa"
 	"crypto/rand"
+	"errors"
 	"github.com/stretchr/testify/require"
 	"math/big"
 	"testing"
 )
 
+// testGroups are the groups every end-to-end test runs on.
+var testGroups = []Group{Secp256k1(), Ristretto255(), P256()}
+
 func TestAllPVSS(t *testing.T) {
+	for _, g := range testGroups {
+		t.Run(g.Name(), func(t *testing.T) {
+			testAllPVSS(t, g)
+		})
+	}
+}
+
+func testAllPVSS(t *testing.T, g Group) {
 	threshold, n := 3, 4
-	dealers, pks := genDealers(n + 1)
+	dealers, pks := genDealers(g, n+1)
 	dealer := dealers[0]
 
 	// 1. dealer distributes a secret
 	secret := new(big.Int).SetBytes([]byte("Hello, go-pvss under ECC"))
-	sharebox, err := dealer.DistributeSecret(secret, pks[1:], threshold)
+	session := NewSession(g, []byte("go-pvss test"), 1, pks[1:], threshold)
+	sharebox, err := dealer.DistributeSecret(secret, pks[1:], session)
 	require.NoError(t, err, "DistributeSecret")
 	require.Equal(t, threshold, len(sharebox.Commitments))
 	require.Equal(t, n, len(sharebox.Shares))
 	require.NotEqual(t, 0, sharebox.U.Cmp(secret))
 
 	// 2. the distribution shares are publicly verifiable
-	ok := VerifyDistributionShares(sharebox)
+	ok := VerifyDistributionShares(g, session, sharebox)
 	require.True(t, ok, "VerifyDistributionShares")
 
 	// 3. each participant can decrypts its part of share
@@ -44,62 +56,226 @@ func TestAllPVSS(t *testing.T) {
 
 	// 4. each decrypted share can be verified publicly
 	for i, decShare := range decShares {
-		ok := VerifyDecryptedShare(decShare)
+		ok := VerifyDecryptedShare(g, session, decShare)
 		require.True(t, ok, i)
 	}
 
 	// 5. reconstruct the secret by using threshold's decrypted shares
 	ds1, ds2, ds3, ds4 := decShares[0], decShares[1], decShares[2], decShares[3]
-	s := ReconstructSecret([]*DecryptedShare{ds1, ds2, ds3}, sharebox.U)
+	s := ReconstructSecret(g, []*DecryptedShare{ds1, ds2, ds3}, sharebox.U)
 	require.NotNil(t, s)
 	require.Equal(t, 0, s.Cmp(secret))
 
-	s = ReconstructSecret([]*DecryptedShare{ds4, ds2, ds3}, sharebox.U)
+	s = ReconstructSecret(g, []*DecryptedShare{ds4, ds2, ds3}, sharebox.U)
 	require.NotNil(t, s)
 	require.Equal(t, 0, s.Cmp(secret))
 
-	s = ReconstructSecret([]*DecryptedShare{ds4, ds1, ds3}, sharebox.U)
+	s = ReconstructSecret(g, []*DecryptedShare{ds4, ds1, ds3}, sharebox.U)
 	require.NotNil(t, s)
 	require.Equal(t, 0, s.Cmp(secret))
 
-	s = ReconstructSecret([]*DecryptedShare{ds4, ds2, ds1}, sharebox.U)
+	s = ReconstructSecret(g, []*DecryptedShare{ds4, ds2, ds1}, sharebox.U)
 	require.NotNil(t, s)
 	require.Equal(t, 0, s.Cmp(secret))
 
-	s = ReconstructSecret([]*DecryptedShare{ds4, ds2, ds3, ds1}, sharebox.U)
+	s = ReconstructSecret(g, []*DecryptedShare{ds4, ds2, ds3, ds1}, sharebox.U)
 	require.NotNil(t, s)
 	require.Equal(t, 0, s.Cmp(secret))
 }
 
-func genDealers(n int) ([]*Dealer, []*ecdsa.PublicKey) {
+func TestCrossGroupRejected(t *testing.T) {
+	g, other := Secp256k1(), Ristretto255()
+	dealers, pks := genDealers(g, 4)
+	sharebox, err := dealers[0].DistributeSecret(big.NewInt(42), pks[1:], testSession(g, pks[1:], 2))
+	require.NoError(t, err)
+
+	require.False(t, VerifyDistributionShares(other, sharebox.Session, sharebox))
+	otherDealers, _ := genDealers(other, 1)
+	_, err = otherDealers[0].ExtractSecretShare(sharebox)
+	require.ErrorIs(t, err, ErrGroupMismatch)
+
+	decShare, err := dealers[1].ExtractSecretShare(sharebox)
+	require.NoError(t, err)
+	require.False(t, VerifyDecryptedShare(other, sharebox.Session, decShare))
+}
+
+func TestSessionBinding(t *testing.T) {
+	g := Secp256k1()
+	dealers, pks := genDealers(g, 4)
+	session := NewSession(g, []byte("app"), 7, pks[1:], 2)
+	sharebox, err := dealers[0].DistributeSecret(big.NewInt(42), pks[1:], session)
+	require.NoError(t, err)
+	require.True(t, VerifyDistributionShares(g, session, sharebox))
+	require.True(t, VerifyDistributionShares(g, NewSession(g, []byte("app"), 7, pks[1:], 2), sharebox))
+
+	// the box is not accepted in another round
+	for _, expected := range []*Session{
+		nil,
+		NewSession(g, []byte("app"), 8, pks[1:], 2),
+		NewSession(g, []byte("other app"), 7, pks[1:], 2),
+		NewSession(g, []byte("app"), 7, pks[1:], 3),
+		NewSession(g, []byte("app"), 7, []Element{pks[2], pks[1], pks[3]}, 2),
+	} {
+		require.False(t, VerifyDistributionShares(g, expected, sharebox))
+	}
+	// nor a box which claims another round
+	replayed := *sharebox
+	replayed.Session = NewSession(g, []byte("app"), 8, pks[1:], 2)
+	require.False(t, VerifyDistributionShares(g, replayed.Session, &replayed))
+
+	// decrypted shares are bound to the session too
+	decShare, err := dealers[1].ExtractSecretShare(sharebox)
+	require.NoError(t, err)
+	require.True(t, VerifyDecryptedShare(g, session, decShare))
+	require.False(t, VerifyDecryptedShare(g, replayed.Session, decShare))
+	require.False(t, VerifyDecryptedShare(g, nil, decShare))
+
+	// the session must be made for the participants
+	_, err = dealers[0].DistributeSecret(big.NewInt(42), pks[1:3], session)
+	require.ErrorIs(t, err, ErrSessionMismatch)
+	_, err = dealers[0].DistributeSecret(big.NewInt(42), pks[1:], nil)
+	require.Error(t, err)
+}
+
+func TestCheckDistributionShares(t *testing.T) {
+	g := Secp256k1()
+	dealers, pks := genDealers(g, 6)
+	session := testSession(g, pks[1:], 3)
+	sharebox, err := dealers[0].DistributeSecret(big.NewInt(42), pks[1:], session)
+	require.NoError(t, err)
+	require.NoError(t, CheckDistributionShares(g, session, sharebox))
+	require.NoError(t, CheckAllDistributionShares(g, session, sharebox))
+
+	// the dealer cheats on the shares of positions 2 and 4
+	sharebox.Shares[1].S = g.Add(sharebox.Shares[1].S, g.Generator())
+	sharebox.Shares[3].response = new(big.Int).Add(sharebox.Shares[3].response, big.NewInt(1))
+	require.False(t, VerifyDistributionShares(g, session, sharebox))
+
+	err = CheckDistributionShares(g, session, sharebox)
+	var shareErr *ShareVerificationError
+	require.True(t, errors.As(err, &shareErr))
+	require.Equal(t, 2, shareErr.Position)
+	require.True(t, g.Equal(pks[2], shareErr.PK))
+	require.ErrorIs(t, err, ErrInvalidProof)
+
+	err = CheckAllDistributionShares(g, session, sharebox)
+	var shareErrs ShareVerificationErrors
+	require.True(t, errors.As(err, &shareErrs))
+	require.Equal(t, []int{2, 4}, shareErrs.Positions())
+	require.ErrorIs(t, err, ErrInvalidProof)
+
+	// errors of the whole box
+	require.ErrorIs(t, CheckDistributionShares(Ristretto255(), session, sharebox), ErrGroupMismatch)
+	require.ErrorIs(t, CheckDistributionShares(g, testSession(g, pks[1:], 2), sharebox), ErrSessionMismatch)
+	require.ErrorIs(t, CheckDistributionShares(g, session, nil), ErrInvalidBox)
+
+	// decrypted shares
+	decShare, err := dealers[1].ExtractSecretShare(sharebox)
+	require.NoError(t, err)
+	require.NoError(t, CheckDecryptedShare(g, session, decShare))
+	decShare.S = g.Add(decShare.S, g.Generator())
+	err = CheckDecryptedShare(g, session, decShare)
+	require.True(t, errors.As(err, &shareErr))
+	require.Equal(t, 1, shareErr.Position)
+	require.ErrorIs(t, err, ErrInvalidProof)
+	decShare.challenge = nil
+	require.ErrorIs(t, CheckDecryptedShare(g, session, decShare), ErrMissingProof)
+}
+
+func TestCheckDistributionShares_Batch(t *testing.T) {
+	g := Secp256k1()
+	dealers, pks := genDealers(g, 7)
+	session := testSession(g, pks[1:], 3)
+	sharebox, err := dealers[0].DistributeSecret(big.NewInt(42), pks[1:], session)
+	require.NoError(t, err)
+	for _, share := range sharebox.Shares {
+		require.NotNil(t, share.a1)
+		require.NotNil(t, share.a2)
+	}
+
+	// the proofs are accepted in the challenge form, whatever their commitments, and without them
+	sharebox.Shares[0].a1 = g.Generator()
+	sharebox.Shares[1].a1, sharebox.Shares[1].a2 = nil, nil
+	sharebox.Shares[2].a2 = sharebox.Shares[2].a1
+	require.NoError(t, CheckAllDistributionShares(g, session, sharebox))
+
+	// a faulty proof is found whether it's batched or not
+	sharebox.Shares[1].response = new(big.Int).Add(sharebox.Shares[1].response, big.NewInt(1))
+	sharebox.Shares[4].challenge = new(big.Int).Add(sharebox.Shares[4].challenge, big.NewInt(1))
+	sharebox.Shares[5].S = g.Add(sharebox.Shares[5].S, g.Generator())
+	sharebox.Shares[3].challenge = nil
+	err = CheckAllDistributionShares(g, session, sharebox)
+	var shareErrs ShareVerificationErrors
+	require.True(t, errors.As(err, &shareErrs))
+	require.Equal(t, []int{2, 4, 5, 6}, shareErrs.Positions())
+	require.ErrorIs(t, shareErrs[1], ErrMissingProof)
+	require.ErrorIs(t, shareErrs[2], ErrInvalidProof)
+
+	var shareErr *ShareVerificationError
+	require.True(t, errors.As(CheckDistributionShares(g, session, sharebox), &shareErr))
+	require.Equal(t, 2, shareErr.Position)
+}
+
+func TestCheckDecryptedShares(t *testing.T) {
+	g := Secp256k1()
+	sharebox, decShares := dealAndDecrypt(t, g, 3, 5, big.NewInt(42), false)
+	session := sharebox.Session
+	require.NoError(t, CheckDecryptedShares(g, session, decShares))
+	require.True(t, VerifyDecryptedShares(g, session, decShares))
+	require.True(t, VerifyDecryptedShares(g, session, nil))
+
+	forged := *decShares[1]
+	forged.S = g.Add(forged.S, g.Generator())
+	tampered := *decShares[3]
+	tampered.a1 = g.Generator()
+	input := []*DecryptedShare{decShares[0], &forged, nil, &tampered, decShares[4]}
+	err := CheckDecryptedShares(g, session, input)
+	require.False(t, VerifyDecryptedShares(g, session, input))
+	var shareErrs ShareVerificationErrors
+	require.True(t, errors.As(err, &shareErrs))
+	require.Equal(t, []int{2, 0}, shareErrs.Positions())
+	require.ErrorIs(t, shareErrs[0], ErrInvalidProof)
+	require.ErrorIs(t, shareErrs[1], ErrMissingShare)
+
+	require.ErrorIs(t, CheckDecryptedShares(g, nil, decShares), ErrSessionMismatch)
+}
+
+// testSession creates a session of the tests for the participants pks.
+func testSession(g Group, pks []Element, threshold int) *Session {
+	return NewSession(g, []byte("go-pvss test"), 1, pks, threshold)
+}
+
+func genDealers(g Group, n int) ([]*Dealer, []Element) {
 	dealers := make([]*Dealer, 0, n)
-	pks := make([]*ecdsa.PublicKey, 0, n)
+	pks := make([]Element, 0, n)
 	for i := 0; i < n; i++ {
-		private, _ := ecdsa.GenerateKey(theCurve, rand.Reader)
+		private, public, _ := GenerateKey(g, rand.Reader)
 
-		dealers = append(dealers, NewDealer(private))
-		pks = append(pks, &private.PublicKey)
+		dealers = append(dealers, NewDealer(g, private))
+		pks = append(pks, public)
 	}
 	return dealers, pks
 }
 
 func BenchmarkDealer_DistributeSecret(b *testing.B) {
 	threshold, n := 11, 20
-	dealers, pks := genDealers(n + 1)
+	g := Secp256k1()
+	dealers, pks := genDealers(g, n+1)
 	dealer := dealers[0]
-	secret, _ := rand.Int(rand.Reader, secp256k1N)
+	secret, _ := rand.Int(rand.Reader, g.Order())
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _ = dealer.DistributeSecret(secret, pks[1:], threshold)
+		_, _ = dealer.DistributeSecret(secret, pks[1:], testSession(g, pks[1:], threshold))
 	}
 }
 
 func BenchmarkVerifyDistributionShares(b *testing.B) {
 	threshold, n := 11, 20
-	dealers, pks := genDealers(n + 1)
+	g := Secp256k1()
+	dealers, pks := genDealers(g, n+1)
 	dealer := dealers[0]
-	secret, _ := rand.Int(rand.Reader, secp256k1N)
-	sharebox, err := dealer.DistributeSecret(secret, pks[1:], threshold)
+	secret, _ := rand.Int(rand.Reader, g.Order())
+	sharebox, err := dealer.DistributeSecret(secret, pks[1:], testSession(g, pks[1:], threshold))
 	require.NoError(b, err)
 	decShares := make([]*DecryptedShare, 0, n)
 	for i, d := range dealers[1:] {
@@ -110,16 +286,17 @@ func BenchmarkVerifyDistributionShares(b *testing.B) {
 	}
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		VerifyDistributionShares(sharebox)
+		VerifyDistributionShares(g, sharebox.Session, sharebox)
 	}
 }
 
 func BenchmarkReconstructSecret(b *testing.B) {
 	threshold, n := 11, 20
-	dealers, pks := genDealers(n + 1)
+	g := Secp256k1()
+	dealers, pks := genDealers(g, n+1)
 	dealer := dealers[0]
-	secret, _ := rand.Int(rand.Reader, secp256k1N)
-	sharebox, err := dealer.DistributeSecret(secret, pks[1:], threshold)
+	secret, _ := rand.Int(rand.Reader, g.Order())
+	sharebox, err := dealer.DistributeSecret(secret, pks[1:], testSession(g, pks[1:], threshold))
 	require.NoError(b, err)
 	decShares := make([]*DecryptedShare, 0, n)
 	for i, d := range dealers[1:] {
@@ -130,6 +307,6 @@ func BenchmarkReconstructSecret(b *testing.B) {
 	}
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = ReconstructSecret(decShares[:threshold], sharebox.U)
+		_ = ReconstructSecret(g, decShares[:threshold], sharebox.U)
 	}
 }
@@ -10,11 +10,13 @@ package pvss
 
 import (
 	"crypto/rand"
+	"github.com/stars-labs/go-pvss/crypto/scalar"
 	"math/big"
 )
 
+// Polynomial is a polynomial modulo n, whose secret coefficients are constant-time scalars.
 type Polynomial struct {
-	coefficients []*big.Int
+	coefficients []*scalar.Scalar
 }
 
 // InitPolynomial initialises a polynomial of the given degree,
@@ -22,9 +24,10 @@ type Polynomial struct {
 // and the n should be the order of the base point of the selected ECC curve.
 func InitPolynomial(degree int, n *big.Int) (*Polynomial, error) {
 	// there will be degree+1 coefficients
-	poly := &Polynomial{coefficients: make([]*big.Int, degree+1)}
+	m := modulusOf(n)
+	poly := &Polynomial{coefficients: make([]*scalar.Scalar, degree+1)}
 	for i := 0; i <= degree; i++ {
-		a, err := rand.Int(rand.Reader, n)
+		a, err := m.Random(rand.Reader)
 		if err != nil {
 			return nil, err
 		}
@@ -33,21 +36,49 @@ func InitPolynomial(degree int, n *big.Int) (*Polynomial, error) {
 	return poly, nil
 }
 
+// InitZeroPolynomial initialises a random polynomial of the given degree like InitPolynomial, but whose constant term is zero,
+// so it shares nothing: added to the polynomial of a secret, it changes the shares but not the secret.
+func InitZeroPolynomial(degree int, n *big.Int) (*Polynomial, error) {
+	return InitSecretPolynomial(degree, new(big.Int), n)
+}
+
+// InitSecretPolynomial initialises a random polynomial of the given degree like InitPolynomial, but whose constant term is
+// the given secret, which must be less than n.
+func InitSecretPolynomial(degree int, secret, n *big.Int) (*Polynomial, error) {
+	poly, err := InitPolynomial(degree, n)
+	if err != nil {
+		return nil, err
+	}
+	poly.coefficients[0] = modulusOf(n).FromBig(secret)
+	return poly, nil
+}
+
+// Degree returns the degree of the polynomial.
+func (poly *Polynomial) Degree() int {
+	return len(poly.coefficients) - 1
+}
+
 // GetValue evaluates `P(x) mod n` and then returns the result.
-// n should be the order of the base point of the selected ECC curve.
+// n must be the order the polynomial was initialised with.
 func (poly *Polynomial) GetValue(x, n *big.Int) *big.Int {
-	xi := new(big.Int).Set(x)
-	sum := new(big.Int)
-	sum.Add(sum, poly.coefficients[0])
-
-	aixi := new(big.Int) // for reuse
-	for i := 1; i < len(poly.coefficients); i++ {
-		aixi.SetInt64(0)
-		aixi.Mul(poly.coefficients[i], xi) // ai * xi
-		sum.Add(sum, aixi)
-		sum.Mod(sum, n)
-		xi.Mul(xi, x)
-		xi.Mod(xi, n)
+	return poly.Evaluate(modulusOf(n).FromBig(x)).Big()
+}
+
+// Evaluate evaluates P(x) by Horner's rule, in constant time. x must be modulo the order the polynomial was initialised with.
+func (poly *Polynomial) Evaluate(x *scalar.Scalar) *scalar.Scalar {
+	sum := x.Modulus().NewScalar()
+	for i := len(poly.coefficients) - 1; i >= 0; i-- {
+		sum.Mul(sum, x)
+		sum.Add(sum, poly.coefficients[i])
 	}
 	return sum
 }
+
+// Commit returns the commitments of the coefficients on the group g: C_j := a_j·base, 0 <= j <= degree.
+func (poly *Polynomial) Commit(g Group, base Element) []Element {
+	commitments := make([]Element, 0, len(poly.coefficients))
+	for _, a := range poly.coefficients {
+		commitments = append(commitments, g.ScalarMult(base, a.Big()))
+	}
+	return commitments
+}
@@ -10,6 +10,7 @@ package pvss
 
 import (
 	"crypto/rand"
+	"github.com/stars-labs/go-pvss/crypto/scalar"
 	"github.com/stars-labs/go-pvss/crypto/secp256k1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -23,19 +24,45 @@ func TestInit(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, 10+1, len(p.coefficients))
 	for _, coefficient := range p.coefficients {
-		t.Log(coefficient.Text(16))
-		require.True(t, coefficient.Cmp(curve.N) < 0)
+		t.Log(coefficient.Big().Text(16))
+		require.True(t, coefficient.Big().Cmp(curve.N) < 0)
+		require.Equal(t, 0, coefficient.Modulus().Big().Cmp(curve.N))
 	}
 }
+
+func TestInitSecretPolynomial(t *testing.T) {
+	curve := secp256k1.S256()
+	secret := big.NewInt(42)
+	p, err := InitSecretPolynomial(3, secret, curve.N)
+	require.NoError(t, err)
+	require.Equal(t, 3, p.Degree())
+	require.Equal(t, 0, p.GetValue(new(big.Int), curve.N).Cmp(secret))
+	require.Equal(t, 0, secret.Cmp(big.NewInt(42)), "the secret is copied")
+
+	// the polynomial is interpolated at 0 from any degree+1 values
+	positions := []int{2, 5, 6, 9}
+	sum := new(big.Int)
+	for _, i := range positions {
+		value := p.GetValue(big.NewInt(int64(i)), curve.N)
+		sum.Add(sum, value.Mul(value, LagrangeCoefficient(i, positions, curve.N)))
+	}
+	require.Equal(t, 0, sum.Mod(sum, curve.N).Cmp(secret))
+
+	p, err = InitZeroPolynomial(3, curve.N)
+	require.NoError(t, err)
+	require.Equal(t, 0, p.GetValue(new(big.Int), curve.N).Sign())
+}
+
 func TestPolynomial_GetValue(t *testing.T) {
 	// a_0 = 3, a_1 = 2, a_2 = 2, a_3 = 4
-	p := &Polynomial{coefficients: make([]*big.Int, 4)}
-	p.coefficients[0] = new(big.Int).SetInt64(3)
-	p.coefficients[1] = new(big.Int).SetInt64(2)
-	p.coefficients[2] = new(big.Int).SetInt64(2)
-	p.coefficients[3] = new(big.Int).SetInt64(4)
-
 	curve := secp256k1.S256()
+	m := modulusOf(curve.N)
+	p := &Polynomial{coefficients: make([]*scalar.Scalar, 4)}
+	p.coefficients[0] = m.FromBig(big.NewInt(3))
+	p.coefficients[1] = m.FromBig(big.NewInt(2))
+	p.coefficients[2] = m.FromBig(big.NewInt(2))
+	p.coefficients[3] = m.FromBig(big.NewInt(4))
+
 	x := new(big.Int)
 	// P(0) == 3
 	assert.EqualValues(t, 3, p.GetValue(x, curve.N).Int64())
@@ -48,6 +75,17 @@ func TestPolynomial_GetValue(t *testing.T) {
 	// P(3) == 135
 	x.SetInt64(3)
 	assert.EqualValues(t, 135, p.GetValue(x, curve.N).Int64())
+
+	// the constant-time evaluation of a random polynomial matches math/big
+	p, err := InitPolynomial(5, curve.N)
+	require.NoError(t, err)
+	x, _ = rand.Int(rand.Reader, curve.N)
+	want, xi := new(big.Int), big.NewInt(1)
+	for _, a := range p.coefficients {
+		want.Add(want, new(big.Int).Mul(a.Big(), xi))
+		xi.Mul(xi, x).Mod(xi, curve.N)
+	}
+	require.Equal(t, 0, want.Mod(want, curve.N).Cmp(p.GetValue(x, curve.N)))
 }
 
 func BenchmarkPolynomial_GetValue(b *testing.B) {
@@ -0,0 +1,235 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pvss
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+var (
+	ErrNotEnoughShares = errors.New("not enough decrypted shares")
+	ErrSecretMismatch  = errors.New("reconstructed secret does not match the commitment")
+)
+
+// The reasons of a ShareVerificationError of a decrypted share checked against its box.
+var (
+	ErrDuplicateShare = errors.New("duplicate share position")
+	ErrShareMismatch  = errors.New("decrypted share does not match the encrypted share")
+)
+
+// Reconstruct reconstructs the secret of the box, which is dealt on the group g in the expected session, from its decrypted shares.
+//
+// Unlike ReconstructSecret, the inputs are authenticated:
+//   - every decrypted share must be the decryption of the encrypted share of the box at its position, by the owner of the share,
+//     with valid proofs of the encryption and the decryption,
+//   - no position is given twice, and there are at least threshold shares, the number of the commitments,
+//   - the Lagrange interpolation which yields s·G from the shares S_i must yield C_0 = s·H from their commitments X_i,
+//   - U is bound to the proofs of the encrypted shares, so the secret it yields with s·G is the one of the dealer.
+//
+// The first faulty share is reported as a *ShareVerificationError.
+func Reconstruct(g Group, expected *Session, sharesBox *DistributionSharesBox, decShares []*DecryptedShare) (*big.Int, error) {
+	if err := checkReconstruction(g, expected, sharesBox); err != nil {
+		return nil, err
+	}
+	checker := newDecryptedShareChecker(g, sharesBox)
+	if _, rejected := checker.checkAll(decShares); len(rejected) != 0 {
+		return nil, rejected[0]
+	}
+	sG, err := checker.interpolate(decShares)
+	if err != nil {
+		return nil, err
+	}
+	return maskSecret(g, sG, sharesBox.U), nil
+}
+
+// ReconstructRobust reconstructs the secret like Reconstruct, but tolerates faulty decrypted shares: any number of shares can be
+// given, they are all checked, the faulty ones are dropped and reported in rejected, in the order of their positions, and the
+// secret is reconstructed from the first threshold valid ones. Of the shares of the same position, the first valid one is used and the others are
+// rejected as duplicates.
+//
+// If fewer than threshold valid shares remain, err is ErrNotEnoughShares, and rejected still tells which shares are faulty.
+func ReconstructRobust(g Group, expected *Session, sharesBox *DistributionSharesBox, decShares []*DecryptedShare) (secret *big.Int, rejected ShareVerificationErrors, err error) {
+	if err := checkReconstruction(g, expected, sharesBox); err != nil {
+		return nil, nil, err
+	}
+	checker := newDecryptedShareChecker(g, sharesBox)
+	t := sharesBox.threshold()
+	// every share is checked, also after threshold valid ones, so that all the faulty ones are reported
+	valid, rejected := checker.checkAll(decShares)
+	if len(valid) > t {
+		valid = valid[:t]
+	}
+	sort.SliceStable(rejected, func(i, j int) bool { return rejected[i].Position < rejected[j].Position })
+	if len(valid) < t {
+		return nil, rejected, fmt.Errorf("%w: %d valid of threshold %d", ErrNotEnoughShares, len(valid), t)
+	}
+	sG, err := checker.interpolate(valid)
+	if err != nil {
+		return nil, rejected, err
+	}
+	return maskSecret(g, sG, sharesBox.U), rejected, nil
+}
+
+// checkReconstruction checks the box whose secret is reconstructed, and its session.
+func checkReconstruction(g Group, expected *Session, sharesBox *DistributionSharesBox) error {
+	if err := checkBox(g, expected, sharesBox); err != nil {
+		return err
+	}
+	if err := checkSecret(sharesBox); err != nil {
+		return err
+	}
+	if sharesBox.isSCRAPE() {
+		// the proofs of the SCRAPE mode are batched, so they can't be verified share by share
+		return checkSCRAPEShares(g, expected, sharesBox, false)
+	}
+	return nil
+}
+
+// checkSecret checks that the box hides a secret by U, rather than a payload. U itself is checked with the box.
+func checkSecret(sharesBox *DistributionSharesBox) error {
+	if sharesBox.Payload != nil {
+		return fmt.Errorf("%w: the secret is the payload", ErrInvalidBox)
+	}
+	return nil
+}
+
+// threshold returns the number of the shares needed to reconstruct the secret of the box.
+func (b *DistributionSharesBox) threshold() int {
+	if b.isSCRAPE() {
+		return b.Session.Threshold
+	}
+	return len(b.Commitments)
+}
+
+// shareCommitment returns X_i = p(i)·H of the share at the position.
+func (b *DistributionSharesBox) shareCommitment(g Group, position int) Element {
+	if b.isSCRAPE() {
+		return b.ShareCommitments[position-1]
+	}
+	return CommitmentAt(g, b.Commitments, position)
+}
+
+// decryptedShareChecker checks the decrypted shares against a box, whose shape and session are already checked.
+type decryptedShareChecker struct {
+	group      Group
+	box        *DistributionSharesBox
+	transcript *Transcript
+	seen       map[int]bool
+	// proven is set if the encrypted shares of the box are proven as a whole, rather than share by share
+	proven bool
+}
+
+func newDecryptedShareChecker(g Group, sharesBox *DistributionSharesBox) *decryptedShareChecker {
+	return &decryptedShareChecker{
+		group:      g,
+		box:        sharesBox,
+		transcript: distributionTranscript(sharesBox.Session, distributionProtocol, sharesBox.U),
+		seen:       make(map[int]bool),
+		// the batched proofs of the SCRAPE mode are verified with the box
+		proven: sharesBox.isSCRAPE(),
+	}
+}
+
+// checkAll verifies the decrypted shares against the encrypted shares of the box at their positions, and returns the
+// valid ones and the faulty ones, in the order of the shares. Of the shares of the same position, only the first valid
+// one is valid. The proofs of all the shares are verified as a batch, see proofBatch.
+func (c *decryptedShareChecker) checkAll(decShares []*DecryptedShare) (valid []*DecryptedShare, rejected ShareVerificationErrors) {
+	g, box := c.group, c.box
+	errs := make([]*ShareVerificationError, len(decShares))
+	batch := newProofBatch(g)
+	decryption := box.Session.transcript(decryptionProtocol)
+	for i, ds := range decShares {
+		if errs[i] = c.checkFields(ds); errs[i] != nil {
+			continue
+		}
+		share := box.Shares[ds.Position-1]
+		if !c.proven {
+			// DLEQ(H,X_i,PK_i,Y_i)
+			Xi := CommitmentAt(g, box.Commitments, ds.Position)
+			batch.add(i, c.transcript, g.SecondGenerator(), Xi, share.PK, share.S, share.a1, share.a2, share.challenge, share.response)
+		}
+		// DLEQ(G,PK_i,S_i,Y_i)
+		batch.add(i, decryption, g.Generator(), ds.PK, ds.S, ds.Y, ds.a1, ds.a2, ds.challenge, ds.response)
+	}
+	for i := range batch.failures() {
+		errs[i] = &ShareVerificationError{Position: decShares[i].Position, PK: decShares[i].PK, Reason: ErrInvalidProof}
+	}
+	for i, ds := range decShares {
+		err := errs[i]
+		// a share of a position out of the box is never valid, so it's never seen
+		if ds != nil && c.seen[ds.Position] {
+			err = &ShareVerificationError{Position: ds.Position, PK: ds.PK, Reason: ErrDuplicateShare}
+		}
+		if err != nil {
+			rejected = append(rejected, err)
+			continue
+		}
+		c.seen[ds.Position] = true
+		valid = append(valid, ds)
+	}
+	return valid, rejected
+}
+
+// checkFields checks a decrypted share against the encrypted share of the box at its position, before their proofs
+// are verified.
+func (c *decryptedShareChecker) checkFields(ds *DecryptedShare) *ShareVerificationError {
+	g, box := c.group, c.box
+	if ds == nil {
+		return &ShareVerificationError{Reason: ErrMissingShare}
+	}
+	fail := func(reason error) *ShareVerificationError {
+		return &ShareVerificationError{Position: ds.Position, PK: ds.PK, Reason: reason}
+	}
+	if ds.Position < 1 || ds.Position > len(box.Shares) {
+		return fail(ErrInvalidPosition)
+	}
+	if !sameGroup(ds.Group, g) {
+		return fail(ErrGroupMismatch)
+	}
+	share := box.Shares[ds.Position-1]
+	if share == nil || share.PK == nil || share.S == nil {
+		return fail(ErrMissingShare)
+	}
+	if ds.PK == nil || ds.Y == nil || !g.Equal(ds.PK, share.PK) || !g.Equal(ds.Y, share.S) {
+		return fail(ErrShareMismatch)
+	}
+	if !c.proven {
+		if err := checkShareFields(g, ds.Position, share); err != nil {
+			return err
+		}
+	}
+	return checkDecryptedShareFields(g, ds)
+}
+
+// interpolate returns s·G from the checked decrypted shares, after checking that the same Lagrange coefficients
+// yield C_0 from the commitments X_i of the shares: S_i = p(i)·G and X_i = p(i)·H are bound by the proofs, so
+// ∑ λ_i·X_i == C_0 = s·H proves ∑ λ_i·S_i == s·G.
+func (c *decryptedShareChecker) interpolate(decShares []*DecryptedShare) (Element, error) {
+	g, box := c.group, c.box
+	if t := box.threshold(); len(decShares) < t {
+		return nil, fmt.Errorf("%w: %d of threshold %d", ErrNotEnoughShares, len(decShares), t)
+	}
+	bigjs := make(map[int]*big.Int, len(decShares))
+	for _, ds := range decShares {
+		bigjs[ds.Position] = big.NewInt(int64(ds.Position))
+	}
+	shares, commitments := make([]Element, len(decShares)), make([]Element, len(decShares))
+	lambdas := make([]*big.Int, len(decShares))
+	for i, ds := range decShares {
+		shares[i], commitments[i] = ds.S, box.shareCommitment(g, ds.Position)
+		lambdas[i] = lagrangeCoefficient(ds.Position, bigjs, g.Order())
+	}
+	if !g.Equal(multiScalarMult(g, commitments, lambdas), box.Commitments[0]) {
+		return nil, ErrSecretMismatch
+	}
+	return multiScalarMult(g, shares, lambdas), nil
+}
@@ -0,0 +1,181 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pvss
+
+import (
+	"crypto/rand"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+// dealAndDecrypt deals a secret in the classic or the SCRAPE mode, and decrypts all the shares.
+func dealAndDecrypt(t *testing.T, g Group, threshold, n int, secret *big.Int, scrape bool) (*DistributionSharesBox, []*DecryptedShare) {
+	dealers, pks := genDealers(g, n+1)
+	session := testSession(g, pks[1:], threshold)
+	deal := dealers[0].DistributeSecret
+	if scrape {
+		deal = dealers[0].DistributeSecretSCRAPE
+	}
+	sharebox, err := deal(secret, pks[1:], session)
+	require.NoError(t, err)
+	decShares := make([]*DecryptedShare, 0, n)
+	for i, d := range dealers[1:] {
+		decShare, err := d.ExtractSecretShare(sharebox)
+		require.NoError(t, err, "ExtractSecretShare", i)
+		decShares = append(decShares, decShare)
+	}
+	return sharebox, decShares
+}
+
+func TestReconstruct(t *testing.T) {
+	threshold, n := 3, 5
+	secret := new(big.Int).SetBytes([]byte("Hello, go-pvss under ECC"))
+	for _, g := range testGroups {
+		for _, scrape := range []bool{false, true} {
+			sharebox, decShares := dealAndDecrypt(t, g, threshold, n, secret, scrape)
+			for _, subset := range [][]*DecryptedShare{decShares[:threshold], decShares[n-threshold:], decShares} {
+				s, err := Reconstruct(g, sharebox.Session, sharebox, subset)
+				require.NoError(t, err, g.Name())
+				require.Equal(t, 0, s.Cmp(secret), g.Name())
+			}
+		}
+	}
+}
+
+func TestReconstruct_Rejects(t *testing.T) {
+	threshold, n := 3, 5
+	g := Secp256k1()
+	sharebox, decShares := dealAndDecrypt(t, g, threshold, n, big.NewInt(42), false)
+	session := sharebox.Session
+	reconstruct := func(decShares ...*DecryptedShare) error {
+		_, err := Reconstruct(g, session, sharebox, decShares)
+		return err
+	}
+	requireShareError := func(err error, position int, reason error) {
+		var shareErr *ShareVerificationError
+		require.ErrorAs(t, err, &shareErr)
+		require.Equal(t, position, shareErr.Position)
+		require.ErrorIs(t, err, reason)
+	}
+
+	require.ErrorIs(t, reconstruct(decShares[:threshold-1]...), ErrNotEnoughShares)
+	requireShareError(reconstruct(decShares[0], decShares[1], decShares[1]), 2, ErrDuplicateShare)
+	requireShareError(reconstruct(decShares[0], decShares[1], nil), 0, ErrMissingShare)
+
+	// a forged decrypted share with a valid proof of another point
+	w, _ := rand.Int(rand.Reader, g.Order())
+	private, _, _ := GenerateKey(g, rand.Reader)
+	forged := *decShares[2]
+	dleq := NewDLEQ(g, g.Generator(), nil, g.ScalarBaseMult(big.NewInt(7)), nil, w, private)
+	forged.PK, forged.S, forged.Y = dleq.H1, dleq.G2, dleq.H2
+	forged.challenge, forged.response = dleq.ChallengeAndResponse(session.transcript(decryptionProtocol))
+	require.True(t, VerifyDecryptedShare(g, session, &forged))
+	requireShareError(reconstruct(decShares[0], decShares[1], &forged), 3, ErrShareMismatch)
+
+	// a share decrypted from the right encrypted share, but with a wrong S_i
+	forged = *decShares[2]
+	forged.S = g.Add(forged.S, g.Generator())
+	requireShareError(reconstruct(decShares[0], decShares[1], &forged), 3, ErrInvalidProof)
+
+	// a position out of the box
+	forged = *decShares[2]
+	forged.Position = n + 1
+	requireShareError(reconstruct(decShares[0], decShares[1], &forged), n+1, ErrInvalidPosition)
+
+	// an encrypted share of the box is faulty
+	faulty := *sharebox
+	faulty.Shares = append([]*Share(nil), sharebox.Shares...)
+	share := *sharebox.Shares[1]
+	share.response = new(big.Int).Add(share.response, big.NewInt(1))
+	faulty.Shares[1] = &share
+	_, err := Reconstruct(g, session, &faulty, decShares[:threshold])
+	requireShareError(err, 2, ErrInvalidProof)
+
+	// the box of another session
+	_, err = Reconstruct(g, NewSession(g, []byte("another"), 1, nil, threshold), sharebox, decShares)
+	require.ErrorIs(t, err, ErrSessionMismatch)
+
+	// U replaced by a relay of the box, in both modes
+	for _, scrape := range []bool{false, true} {
+		sharebox, decShares := dealAndDecrypt(t, g, threshold, n, big.NewInt(42), scrape)
+		relayed := *sharebox
+		relayed.U = new(big.Int).Xor(sharebox.U, big.NewInt(1))
+		require.False(t, VerifyDistributionShares(g, sharebox.Session, &relayed))
+		_, err = Reconstruct(g, sharebox.Session, &relayed, decShares)
+		require.ErrorIs(t, err, ErrInvalidProof)
+		_, _, err = ReconstructRobust(g, sharebox.Session, &relayed, decShares)
+		require.Error(t, err)
+		relayed.U = new(big.Int).Lsh(big.NewInt(1), 256)
+		require.ErrorIs(t, CheckDistributionShares(g, sharebox.Session, &relayed), ErrInvalidBox)
+	}
+}
+
+func TestDistributeSecret_SecretRange(t *testing.T) {
+	g := Secp256k1()
+	dealers, pks := genDealers(g, 4)
+	session := testSession(g, pks[1:], 2)
+	largest := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	for _, deal := range []func(*big.Int, []Element, *Session) (*DistributionSharesBox, error){dealers[0].DistributeSecret, dealers[0].DistributeSecretSCRAPE} {
+		sharebox, err := deal(largest, pks[1:], session)
+		require.NoError(t, err)
+		require.True(t, VerifyDistributionShares(g, session, sharebox))
+		_, err = deal(new(big.Int).Add(largest, big.NewInt(1)), pks[1:], session)
+		require.ErrorIs(t, err, ErrSecretTooLarge)
+		_, err = deal(big.NewInt(-1), pks[1:], session)
+		require.Error(t, err)
+	}
+}
+
+func TestReconstructRobust(t *testing.T) {
+	threshold, n := 3, 6
+	secret := big.NewInt(42)
+	for _, g := range testGroups {
+		for _, scrape := range []bool{false, true} {
+			sharebox, decShares := dealAndDecrypt(t, g, threshold, n, secret, scrape)
+			session := sharebox.Session
+
+			// the faulty shares come first and are skipped, the duplicate of a valid share is rejected
+			wrongS := *decShares[4]
+			wrongS.S = g.Add(wrongS.S, g.Generator())
+			misplaced := *decShares[1]
+			misplaced.Position = n + 1
+			input := []*DecryptedShare{&wrongS, nil, &misplaced, decShares[0], decShares[0], decShares[3], decShares[5]}
+			s, rejected, err := ReconstructRobust(g, session, sharebox, input)
+			require.NoError(t, err, g.Name())
+			require.Equal(t, 0, s.Cmp(secret), g.Name())
+			require.Equal(t, []int{0, 1, 5, 7}, rejected.Positions())
+			require.ErrorIs(t, rejected[0], ErrMissingShare)
+			require.ErrorIs(t, rejected[1], ErrDuplicateShare)
+			require.ErrorIs(t, rejected[2], ErrInvalidProof)
+			require.ErrorIs(t, rejected[3], ErrInvalidPosition)
+
+			// all valid
+			s, rejected, err = ReconstructRobust(g, session, sharebox, decShares)
+			require.NoError(t, err)
+			require.Equal(t, 0, s.Cmp(secret))
+			require.Empty(t, rejected)
+
+			// the faulty shares after threshold valid ones are reported too
+			input = []*DecryptedShare{decShares[0], decShares[1], decShares[2], decShares[3], &wrongS, &misplaced, decShares[1]}
+			s, rejected, err = ReconstructRobust(g, session, sharebox, input)
+			require.NoError(t, err)
+			require.Equal(t, 0, s.Cmp(secret))
+			require.Equal(t, []int{2, 5, 7}, rejected.Positions())
+			require.ErrorIs(t, rejected[0], ErrDuplicateShare)
+			require.ErrorIs(t, rejected[1], ErrInvalidProof)
+			require.ErrorIs(t, rejected[2], ErrInvalidPosition)
+
+			// too few valid shares remain
+			_, rejected, err = ReconstructRobust(g, session, sharebox, []*DecryptedShare{decShares[2], &wrongS, decShares[3]})
+			require.ErrorIs(t, err, ErrNotEnoughShares)
+			require.Equal(t, []int{5}, rejected.Positions())
+		}
+	}
+}
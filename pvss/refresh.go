@@ -0,0 +1,224 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pvss
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var ErrInvalidRefresh = errors.New("invalid refresh")
+
+// The proactive refresh of a box, see:
+// Amir Herzberg, Stanisław Jarecki, Hugo Krawczyk and Moti Yung. Proactive Secret Sharing Or: How to Cope With Perpetual Leakage. CRYPTO 1995.
+//
+// In a round of refresh, every holder deals a refresh box of a random polynomial p' of degree t-1 with p'(0) = 0 to the same
+// participants, so its commitment C'_0 = 0·H is the identity, which proves that it doesn't change the secret. After the refresh
+// boxes of the round are publicly verified, the encrypted shares are updated in place to Y_i + ∑ Y'_i = (p(i) + ∑ p'(i))·PK_i,
+// and the commitments to C_j + ∑ C'_j, so C_0 and U are unchanged, while the shares of different rounds don't interpolate.
+//
+// Nobody knows p(i) + ∑ p'(i), so the updated shares can't be proven again: they are proven by the proofs of the boxes which
+// they are summed from, and a RefreshedBox keeps all of them.
+//
+// The refresh only protects against the leakage of the shares, not of the keys of the holders: the box, with the encrypted
+// share of every epoch, is kept along with the refresh boxes, so whoever learns sk_i later decrypts the share of the holder i
+// in every epoch. The holders must rotate their keys, by a resharing to new keys (see dkg.Resharer), rather than refresh the shares under
+// the same keys when a key may be leaked.
+
+// RefreshedBox is a box with the refresh boxes of its rounds of proactive refresh, in the order of the rounds.
+// The refresh boxes of a round are dealt in the same session, which has the ID, the participants and the threshold
+// of the box, and a greater epoch than the previous round.
+type RefreshedBox struct {
+	Box       *DistributionSharesBox
+	Refreshes []*DistributionSharesBox
+}
+
+// DistributeRefresh deals a refresh box of a zero-constant polynomial to the participants pks in the session of a round of refresh.
+func (d *Dealer) DistributeRefresh(pks []Element, session *Session) (*DistributionSharesBox, error) {
+	if session == nil {
+		return nil, errors.New("missing session")
+	}
+	threshold := session.Threshold
+	if threshold < 1 {
+		return nil, errors.New(fmt.Sprintf("invalid threshold(%d). ", threshold))
+	}
+	poly, err := InitZeroPolynomial(threshold-1, d.Group.Order())
+	if err != nil {
+		return nil, err
+	}
+	shares, err := d.prepareShares(pks, session)
+	if err != nil {
+		return nil, err
+	}
+	// s = 0, so U hides nothing
+	return d.distribute(shares, session, poly, new(big.Int))
+}
+
+// Session returns the session of the last round of refresh, or the session of the box if it's not refreshed yet.
+func (r *RefreshedBox) Session() *Session {
+	if len(r.Refreshes) == 0 {
+		return r.Box.Session
+	}
+	return r.Refreshes[len(r.Refreshes)-1].Session
+}
+
+// SharesBox returns the box with the refreshed encrypted shares and commitments, in the session of the last round, which the
+// holders decrypt with ExtractSecretShare. Its shares have no proofs, it's only valid along with the boxes of r, see CheckRefreshedBox.
+// A refresh box whose commitments or shares don't match the ones of the box is rejected.
+func (r *RefreshedBox) SharesBox(g Group) (*DistributionSharesBox, error) {
+	if r == nil || r.Box == nil {
+		return nil, fmt.Errorf("%w: nil box", ErrInvalidBox)
+	}
+	box := r.Box
+	if len(r.Refreshes) == 0 {
+		return box, nil
+	}
+	refreshed := &DistributionSharesBox{
+		Group:       box.Group,
+		Session:     r.Session(),
+		Commitments: append([]Element(nil), box.Commitments...),
+		Shares:      make([]*Share, len(box.Shares)),
+		U:           box.U,
+		Payload:     box.Payload,
+	}
+	for i, share := range box.Shares {
+		if share == nil {
+			return nil, fmt.Errorf("%w: missing share %d", ErrInvalidBox, i+1)
+		}
+		refreshed.Shares[i] = &Share{Group: share.Group, PK: share.PK, Position: share.Position, S: share.S}
+	}
+	for _, refresh := range r.Refreshes {
+		if refresh == nil || len(refresh.Commitments) != len(box.Commitments) || len(refresh.Shares) != len(box.Shares) {
+			return nil, fmt.Errorf("%w: the refresh box does not match the box", ErrInvalidRefresh)
+		}
+		for j, c := range refresh.Commitments {
+			refreshed.Commitments[j] = g.Add(refreshed.Commitments[j], c)
+		}
+		for i, share := range refresh.Shares {
+			if share == nil || share.Position != refreshed.Shares[i].Position || share.PK == nil || !g.Equal(share.PK, refreshed.Shares[i].PK) {
+				return nil, fmt.Errorf("%w: the share %d does not match the box", ErrInvalidRefresh, i+1)
+			}
+			refreshed.Shares[i].S = g.Add(refreshed.Shares[i].S, share.S)
+		}
+	}
+	return refreshed, nil
+}
+
+// CheckRefresh verifies publicly a refresh box of a round, dealt in the expected session, against the box it refreshes:
+// the session of the round follows the session of r, the refresh box is a valid distribution to the same participants,
+// and its C'_0 is the identity, so the commitment C_0 of the secret is unchanged.
+func CheckRefresh(g Group, expected *Session, r *RefreshedBox, refresh *DistributionSharesBox) error {
+	if r == nil || r.Box == nil {
+		return fmt.Errorf("%w: nil box", ErrInvalidBox)
+	}
+	return checkRefresh(g, r.Session(), expected, refresh)
+}
+
+// checkRefresh checks a refresh box of the round in the expected session, after the previous session.
+func checkRefresh(g Group, previous, expected *Session, refresh *DistributionSharesBox) error {
+	if previous == nil || expected == nil {
+		return fmt.Errorf("%w: missing session", ErrSessionMismatch)
+	}
+	if !bytes.Equal(expected.ID, previous.ID) || expected.Epoch <= previous.Epoch ||
+		!bytes.Equal(expected.ParticipantsHash, previous.ParticipantsHash) || expected.Threshold != previous.Threshold {
+		return fmt.Errorf("%w: the round does not follow epoch %d of the box", ErrInvalidRefresh, previous.Epoch)
+	}
+	if refresh == nil {
+		return fmt.Errorf("%w: nil box", ErrInvalidBox)
+	}
+	if refresh.isSCRAPE() || refresh.Payload != nil {
+		return fmt.Errorf("%w: not the box of a polynomial", ErrInvalidRefresh)
+	}
+	if err := CheckDistributionShares(g, expected, refresh); err != nil {
+		return err
+	}
+	if !isIdentity(g, refresh.Commitments[0]) {
+		return fmt.Errorf("%w: the constant term is not zero", ErrInvalidRefresh)
+	}
+	return nil
+}
+
+// Refresh verifies the refresh boxes of a round, dealt in the expected session, against r, which must have been checked
+// by CheckRefreshedBox or returned by Refresh, and returns r refreshed by them.
+func Refresh(g Group, expected *Session, r *RefreshedBox, refreshes []*DistributionSharesBox) (*RefreshedBox, error) {
+	if r == nil || r.Box == nil {
+		return nil, fmt.Errorf("%w: nil box", ErrInvalidBox)
+	}
+	if r.Box.isSCRAPE() {
+		return nil, fmt.Errorf("%w: the SCRAPE mode has no commitments of the coefficients", ErrInvalidRefresh)
+	}
+	if len(refreshes) == 0 {
+		return nil, fmt.Errorf("%w: no refresh box", ErrInvalidRefresh)
+	}
+	for _, refresh := range refreshes {
+		if err := CheckRefresh(g, expected, r, refresh); err != nil {
+			return nil, err
+		}
+	}
+	return &RefreshedBox{
+		Box:       r.Box,
+		Refreshes: append(append([]*DistributionSharesBox(nil), r.Refreshes...), refreshes...),
+	}, nil
+}
+
+// CheckRefreshedBox verifies publicly the box, which is dealt in the expected session, and all the refresh boxes of r, whose
+// last round must be in the current session, or which must not be refreshed if current is the expected session. The rounds
+// are chained by the boxes of r, so without the current session, r with its last rounds dropped would still verify.
+func CheckRefreshedBox(g Group, expected, current *Session, r *RefreshedBox) error {
+	if r == nil || r.Box == nil {
+		return fmt.Errorf("%w: nil box", ErrInvalidBox)
+	}
+	if !r.Session().Equal(current) {
+		return fmt.Errorf("%w: the last round is not in the current session", ErrSessionMismatch)
+	}
+	if err := CheckDistributionShares(g, expected, r.Box); err != nil {
+		return err
+	}
+	if len(r.Refreshes) != 0 && r.Box.isSCRAPE() {
+		return fmt.Errorf("%w: the SCRAPE mode has no commitments of the coefficients", ErrInvalidRefresh)
+	}
+	previous, session := expected, expected
+	for _, refresh := range r.Refreshes {
+		if refresh != nil && !session.Equal(refresh.Session) {
+			// the first refresh box of a round
+			previous, session = session, refresh.Session
+		}
+		if err := checkRefresh(g, previous, session, refresh); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReconstructRefreshed reconstructs the secret of a refreshed box like Reconstruct, from the decrypted shares of SharesBox.
+// The box is dealt in the expected session, its last round is in the current session, and r is verified by CheckRefreshedBox.
+func ReconstructRefreshed(g Group, expected, current *Session, r *RefreshedBox, decShares []*DecryptedShare) (*big.Int, error) {
+	if err := CheckRefreshedBox(g, expected, current, r); err != nil {
+		return nil, err
+	}
+	if err := checkSecret(r.Box); err != nil {
+		return nil, err
+	}
+	box, err := r.SharesBox(g)
+	if err != nil {
+		return nil, err
+	}
+	checker := newDecryptedShareChecker(g, box)
+	checker.proven = true
+	if _, rejected := checker.checkAll(decShares); len(rejected) != 0 {
+		return nil, rejected[0]
+	}
+	sG, err := checker.interpolate(decShares)
+	if err != nil {
+		return nil, err
+	}
+	return maskSecret(g, sG, box.U), nil
+}
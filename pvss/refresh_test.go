@@ -0,0 +1,162 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pvss
+
+import (
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+// refreshRound deals a refresh box by every holder in the round of the epoch, and refreshes r with them.
+func refreshRound(t *testing.T, g Group, holders []*Dealer, pks []Element, r *RefreshedBox, epoch uint64) *RefreshedBox {
+	session := r.Box.Session
+	round := NewSession(g, session.ID, epoch, pks, session.Threshold)
+	refreshes := make([]*DistributionSharesBox, 0, len(holders))
+	for _, holder := range holders {
+		refresh, err := holder.DistributeRefresh(pks, round)
+		require.NoError(t, err)
+		require.NoError(t, CheckRefresh(g, round, r, refresh))
+		// C'_0 is the identity, which survives the encoding
+		data, err := refresh.MarshalBinary()
+		require.NoError(t, err)
+		decoded := new(DistributionSharesBox)
+		require.NoError(t, decoded.UnmarshalBinary(data))
+		require.NoError(t, CheckRefresh(g, round, r, decoded))
+		refreshes = append(refreshes, refresh)
+	}
+	refreshed, err := Refresh(g, round, r, refreshes)
+	require.NoError(t, err)
+	return refreshed
+}
+
+func TestRefresh(t *testing.T) {
+	threshold, n := 3, 5
+	secret := new(big.Int).SetBytes([]byte("Hello, go-pvss under ECC"))
+	for _, g := range testGroups {
+		dealers, pks := genDealers(g, n+1)
+		holders, pks := dealers[1:], pks[1:]
+		session := testSession(g, pks, threshold)
+		sharebox, err := dealers[0].DistributeSecret(secret, pks, session)
+		require.NoError(t, err)
+		old := make([]*DecryptedShare, n)
+		for i, holder := range holders {
+			old[i], err = holder.ExtractSecretShare(sharebox)
+			require.NoError(t, err)
+		}
+
+		r := &RefreshedBox{Box: sharebox}
+		for epoch := session.Epoch + 1; epoch <= session.Epoch+2; epoch++ {
+			r = refreshRound(t, g, holders, pks, r, epoch)
+		}
+		require.Len(t, r.Refreshes, 2*n)
+		require.NoError(t, CheckRefreshedBox(g, session, r.Session(), r))
+
+		box, err := r.SharesBox(g)
+		require.NoError(t, err)
+		require.True(t, g.Equal(box.Commitments[0], sharebox.Commitments[0]), g.Name())
+		require.Equal(t, r.Session(), box.Session)
+		decShares := make([]*DecryptedShare, n)
+		for i, holder := range holders {
+			decShares[i], err = holder.ExtractSecretShare(box)
+			require.NoError(t, err)
+			require.NoError(t, CheckDecryptedShare(g, box.Session, decShares[i]))
+			require.False(t, g.Equal(decShares[i].S, old[i].S), "the shares are refreshed")
+		}
+		for _, subset := range [][]*DecryptedShare{decShares[:threshold], decShares[n-threshold:]} {
+			s, err := ReconstructRefreshed(g, session, r.Session(), r, subset)
+			require.NoError(t, err, g.Name())
+			require.Equal(t, 0, s.Cmp(secret), g.Name())
+			require.Equal(t, 0, ReconstructSecret(g, subset, box.U).Cmp(secret))
+		}
+
+		// the shares of different rounds don't interpolate to the secret
+		mixed := []*DecryptedShare{old[0], old[1], decShares[2]}
+		require.NotEqual(t, 0, ReconstructSecret(g, mixed, box.U).Cmp(secret))
+		_, err = ReconstructRefreshed(g, session, r.Session(), r, mixed)
+		require.ErrorIs(t, err, ErrShareMismatch)
+	}
+}
+
+func TestRefresh_Rejects(t *testing.T) {
+	threshold, n := 3, 5
+	g := Secp256k1()
+	dealers, pks := genDealers(g, n+1)
+	holders, pks := dealers[1:], pks[1:]
+	session := testSession(g, pks, threshold)
+	sharebox, err := dealers[0].DistributeSecret(big.NewInt(42), pks, session)
+	require.NoError(t, err)
+	r := &RefreshedBox{Box: sharebox}
+	round := NewSession(g, session.ID, session.Epoch+1, pks, threshold)
+
+	// a refresh box which changes the secret
+	changing, err := holders[0].DistributeSecret(big.NewInt(1), pks, round)
+	require.NoError(t, err)
+	require.ErrorIs(t, CheckRefresh(g, round, r, changing), ErrInvalidRefresh)
+	_, err = Refresh(g, round, r, []*DistributionSharesBox{changing})
+	require.ErrorIs(t, err, ErrInvalidRefresh)
+
+	// a round which doesn't follow the box
+	refresh, err := holders[0].DistributeRefresh(pks, NewSession(g, session.ID, session.Epoch, pks, threshold))
+	require.NoError(t, err)
+	require.ErrorIs(t, CheckRefresh(g, refresh.Session, r, refresh), ErrInvalidRefresh)
+	refresh, err = holders[0].DistributeRefresh(pks[:4], NewSession(g, session.ID, session.Epoch+1, pks[:4], threshold))
+	require.NoError(t, err)
+	require.ErrorIs(t, CheckRefresh(g, refresh.Session, r, refresh), ErrInvalidRefresh)
+
+	// a refresh box of another round, or with a faulty share
+	refresh, err = holders[0].DistributeRefresh(pks, round)
+	require.NoError(t, err)
+	require.ErrorIs(t, CheckRefresh(g, NewSession(g, session.ID, session.Epoch+2, pks, threshold), r, refresh), ErrSessionMismatch)
+	faulty := *refresh
+	faulty.Shares = append([]*Share(nil), refresh.Shares...)
+	share := *refresh.Shares[3]
+	share.S = g.Add(share.S, g.Generator())
+	faulty.Shares[3] = &share
+	var shareErr *ShareVerificationError
+	require.ErrorAs(t, CheckRefresh(g, round, r, &faulty), &shareErr)
+	require.Equal(t, 4, shareErr.Position)
+
+	// a tampered refreshed box
+	refreshed, err := Refresh(g, round, r, []*DistributionSharesBox{refresh})
+	require.NoError(t, err)
+	require.NoError(t, CheckRefreshedBox(g, session, round, refreshed))
+	tampered := &RefreshedBox{Box: sharebox, Refreshes: []*DistributionSharesBox{refresh, &faulty}}
+	require.ErrorIs(t, CheckRefreshedBox(g, session, round, tampered), ErrInvalidProof)
+	_, err = ReconstructRefreshed(g, session, round, tampered, nil)
+	require.ErrorIs(t, err, ErrInvalidProof)
+
+	// a refreshed box rolled back to an earlier round
+	require.ErrorIs(t, CheckRefreshedBox(g, session, round, r), ErrSessionMismatch)
+	next := NewSession(g, session.ID, round.Epoch+1, pks, threshold)
+	nextRefresh, err := holders[1].DistributeRefresh(pks, next)
+	require.NoError(t, err)
+	latest, err := Refresh(g, next, refreshed, []*DistributionSharesBox{nextRefresh})
+	require.NoError(t, err)
+	require.NoError(t, CheckRefreshedBox(g, session, next, latest))
+	require.ErrorIs(t, CheckRefreshedBox(g, session, next, refreshed), ErrSessionMismatch)
+	_, err = ReconstructRefreshed(g, session, next, refreshed, nil)
+	require.ErrorIs(t, err, ErrSessionMismatch)
+
+	// the shares box of refresh boxes which don't match the box
+	short := *refresh
+	short.Shares = refresh.Shares[:n-1]
+	_, err = (&RefreshedBox{Box: sharebox, Refreshes: []*DistributionSharesBox{&short}}).SharesBox(g)
+	require.ErrorIs(t, err, ErrInvalidRefresh)
+	swapped := *refresh
+	swapped.Shares = append([]*Share{refresh.Shares[1], refresh.Shares[0]}, refresh.Shares[2:]...)
+	_, err = (&RefreshedBox{Box: sharebox, Refreshes: []*DistributionSharesBox{&swapped}}).SharesBox(g)
+	require.ErrorIs(t, err, ErrInvalidRefresh)
+
+	// the SCRAPE mode can't be refreshed
+	scrapebox, err := dealers[0].DistributeSecretSCRAPE(big.NewInt(42), pks, session)
+	require.NoError(t, err)
+	_, err = Refresh(g, round, &RefreshedBox{Box: scrapebox}, []*DistributionSharesBox{refresh})
+	require.ErrorIs(t, err, ErrInvalidRefresh)
+}
@@ -0,0 +1,147 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pvss
+
+import (
+	"crypto/sha512"
+	"errors"
+	"github.com/gtank/ristretto255"
+	"github.com/stars-labs/go-pvss/crypto/secp256k1"
+	"math/big"
+)
+
+var ErrInvalidRistrettoElement = errors.New("invalid ristretto255 element")
+
+// ristretto255Group is the prime-order group ristretto255 built on Curve25519, see RFC 9496.
+// Elements are *ristretto255.Element, and encoded in the canonical 32 bytes format.
+type ristretto255Group struct {
+	order *big.Int
+	g     *ristretto255.Element
+	h     *ristretto255.Element
+}
+
+var theRistretto255 = newRistretto255Group()
+
+func newRistretto255Group() *ristretto255Group {
+	// ℓ = 2^252 + 27742317777372353535851937790883648493
+	order, _ := new(big.Int).SetString("7237005577332262213973186563042994240857116359379907606001950938285454250989", 10)
+	g := &ristretto255Group{
+		order: order,
+		g:     ristretto255.NewElement().Base(),
+	}
+	g.h = toRistretto(g.deriveSecondGenerator())
+	return g
+}
+
+func init() {
+	RegisterGroup(theRistretto255)
+}
+
+// Ristretto255 returns the ristretto255 group.
+func Ristretto255() Group {
+	return theRistretto255
+}
+
+func (r *ristretto255Group) Name() string {
+	return "ristretto255"
+}
+
+func (r *ristretto255Group) Order() *big.Int {
+	return r.order
+}
+
+func (r *ristretto255Group) Generator() Element {
+	return r.g
+}
+
+func (r *ristretto255Group) SecondGenerator() Element {
+	return r.h
+}
+
+func (r *ristretto255Group) Identity() Element {
+	return ristretto255.NewElement().Zero()
+}
+
+func (r *ristretto255Group) Add(a, b Element) Element {
+	return ristretto255.NewElement().Add(toRistretto(a), toRistretto(b))
+}
+
+func (r *ristretto255Group) Neg(a Element) Element {
+	return ristretto255.NewElement().Negate(toRistretto(a))
+}
+
+func (r *ristretto255Group) ScalarMult(a Element, k *big.Int) Element {
+	return ristretto255.NewElement().ScalarMult(r.scalar(k), toRistretto(a))
+}
+
+func (r *ristretto255Group) ScalarBaseMult(k *big.Int) Element {
+	return ristretto255.NewElement().ScalarBaseMult(r.scalar(k))
+}
+
+func (r *ristretto255Group) Equal(a, b Element) bool {
+	return toRistretto(a).Equal(toRistretto(b)) == 1
+}
+
+func (r *ristretto255Group) ElementLen() int {
+	return 32
+}
+
+func (r *ristretto255Group) Encode(a Element) []byte {
+	return toRistretto(a).Encode(nil)
+}
+
+func (r *ristretto255Group) Decode(data []byte) (Element, error) {
+	e := ristretto255.NewElement()
+	if len(data) != r.ElementLen() || e.Decode(data) != nil {
+		return nil, ErrInvalidRistrettoElement
+	}
+	return e, nil
+}
+
+// HashToElement implements hash_to_ristretto255 with expand_message_xmd and SHA-512, see RFC 9380 and RFC 9496.
+func (r *ristretto255Group) HashToElement(msg, dst []byte) Element {
+	uniform := secp256k1.ExpandMessageXMD(sha512.New, msg, dst, 64)
+	return ristretto255.NewElement().FromUniformBytes(uniform)
+}
+
+func (r *ristretto255Group) multiScalarMult(points []Element, scalars []*big.Int) Element {
+	ps, ss := make([]*ristretto255.Element, len(points)), make([]*ristretto255.Scalar, len(scalars))
+	for i, p := range points {
+		ps[i], ss[i] = toRistretto(p), r.scalar(scalars[i])
+	}
+	return ristretto255.NewElement().VarTimeMultiScalarMult(ss, ps)
+}
+
+// deriveSecondGenerator hashes the encoding of G to H, so no one knows log_G(H).
+func (r *ristretto255Group) deriveSecondGenerator() Element {
+	return r.HashToElement(r.g.Encode(nil), []byte("go-pvss-ristretto255-H"))
+}
+
+// scalar converts k mod ℓ to a ristretto255 scalar.
+func (r *ristretto255Group) scalar(k *big.Int) *ristretto255.Scalar {
+	le := new(big.Int).Mod(k, r.order).FillBytes(make([]byte, 32))
+	// big-endian to little-endian
+	for i, j := 0, len(le)-1; i < j; i, j = i+1, j-1 {
+		le[i], le[j] = le[j], le[i]
+	}
+	s := ristretto255.NewScalar()
+	if err := s.Decode(le); err != nil {
+		panic("pvss: reduced scalar is not canonical")
+	}
+	return s
+}
+
+// toRistretto returns a as a *ristretto255.Element, it panics if a is not a ristretto255 element.
+func toRistretto(a Element) *ristretto255.Element {
+	e, ok := a.(*ristretto255.Element)
+	if !ok || e == nil {
+		panic("pvss: element is not a ristretto255 element")
+	}
+	return e
+}
@@ -0,0 +1,186 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pvss
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"github.com/stars-labs/go-pvss/crypto/scalar"
+	"math/big"
+)
+
+// The SCRAPE mode of distribution, see:
+// Ignacio Cascudo and Bernardo David. SCRAPE: Scalable Randomness Attested by Public Entities. ACNS 2017.
+//
+// Instead of the commitments of the polynomial coefficients, the dealer commits to every share, v_i = p(i)·H, 0 <= i <= n,
+// where v_0 = C_0 is kept as the only entry of Commitments and v_1..v_n are ShareCommitments. The verifier
+//   - checks that v_0..v_n are the evaluations of a polynomial of degree < t, by the Reed–Solomon dual code:
+//     for a random codeword c⊥ of the dual code, ∑ c⊥_i·v_i must be the identity,
+//   - checks a batched DLEQ(H,v_i,PK_i,Y_i) proof, whose single challenge c is derived from all the shares,
+// so the verification takes O(n) scalar multiplications instead of O(n·t).
+
+// DistributeSecretSCRAPE shares the secret like DistributeSecret in the SCRAPE mode, whose box is verified in O(n).
+// VerifyDistributionShares and CheckDistributionShares verify the boxes of both modes.
+func (d *Dealer) DistributeSecretSCRAPE(secret *big.Int, pks []Element, session *Session) (*DistributionSharesBox, error) {
+	if session == nil {
+		return nil, errors.New("missing session")
+	}
+	threshold := session.Threshold
+	if threshold < 1 {
+		return nil, errors.New(fmt.Sprintf("invalid threshold(%d). ", threshold))
+	}
+	if err := checkSecretRange(secret); err != nil {
+		return nil, err
+	}
+	poly, err := InitPolynomial(threshold-1, d.Group.Order())
+	if err != nil {
+		return nil, err
+	}
+	shares, err := d.prepareShares(pks, session)
+	if err != nil {
+		return nil, err
+	}
+
+	g := d.Group
+	m := OrderModulus(g)
+	H := g.SecondGenerator()
+	shareCommitments := make([]Element, len(shares))
+	pis, ws := make([]*scalar.Scalar, len(shares)), make([]*scalar.Scalar, len(shares))
+	a1s, a2s := make([]Element, len(shares)), make([]Element, len(shares))
+	for i, share := range shares {
+		// v_i := p(i)·H, Y_i := p(i)·PK_i, A_1i := w_i·H, A_2i := w_i·PK_i
+		pis[i] = poly.Evaluate(m.FromBig(big.NewInt(int64(share.Position))))
+		if ws[i], err = m.Random(rand.Reader); err != nil {
+			return nil, err
+		}
+		pi, wi := pis[i].Big(), ws[i].Big()
+		shareCommitments[i] = g.ScalarMult(H, pi)
+		share.S = g.ScalarMult(share.PK, pi)
+		a1s[i] = g.ScalarMult(H, wi)
+		a2s[i] = g.ScalarMult(share.PK, wi)
+	}
+	u := maskSecret(g, g.ScalarBaseMult(poly.coefficients[0].Big()), secret)
+	c := scrapeChallenge(g, session, u, shareCommitments, shares, a1s, a2s)
+	for i, share := range shares {
+		share.challenge, share.response = c, response(ws[i], pis[i], m.FromBig(c)).Big()
+	}
+
+	return &DistributionSharesBox{
+		Group:            g,
+		Session:          session,
+		Commitments:      []Element{g.ScalarMult(H, poly.coefficients[0].Big())},
+		ShareCommitments: shareCommitments,
+		Shares:           shares,
+		U:                u,
+	}, nil
+}
+
+// scrapeChallenge derives the single challenge of the batched DLEQ(H,v_i,PK_i,Y_i) proofs with the commitments A_1i,A_2i,
+// which binds U like distributionTranscript.
+func scrapeChallenge(g Group, session *Session, u *big.Int, shareCommitments []Element, shares []*Share, a1s, a2s []Element) *big.Int {
+	t := distributionTranscript(session, scrapeProtocol, u)
+	t.AppendMessage("proof", []byte("batched DLEQ"))
+	t.AppendMessage("group", []byte(g.Name()))
+	t.AppendElement(g, "H", g.SecondGenerator())
+	for i, share := range shares {
+		t.AppendElement(g, "v", shareCommitments[i])
+		t.AppendElement(g, "PK", share.PK)
+		t.AppendElement(g, "Y", share.S)
+		t.AppendElement(g, "A1", a1s[i])
+		t.AppendElement(g, "A2", a2s[i])
+	}
+	return t.ChallengeScalar(g, "c")
+}
+
+// isSCRAPE reports whether the box is dealt in the SCRAPE mode.
+func (b *DistributionSharesBox) isSCRAPE() bool {
+	return len(b.ShareCommitments) != 0
+}
+
+// checkSCRAPEShares verifies the shares of a box in the SCRAPE mode, the session is already checked.
+func checkSCRAPEShares(g Group, session *Session, box *DistributionSharesBox, all bool) error {
+	if len(box.Commitments) != 1 || len(box.ShareCommitments) != len(box.Shares) || len(box.Shares) < session.Threshold {
+		return fmt.Errorf("%w: %d share commitments for %d shares", ErrInvalidBox, len(box.ShareCommitments), len(box.Shares))
+	}
+	for _, v := range box.ShareCommitments {
+		if v == nil {
+			return fmt.Errorf("%w: missing share commitment", ErrInvalidBox)
+		}
+	}
+
+	var errs ShareVerificationErrors
+	for i, share := range box.Shares {
+		if err := checkShareFields(g, i+1, share); err != nil {
+			if !all {
+				return err
+			}
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) != 0 {
+		return errs
+	}
+
+	// the Reed–Solomon dual code check on v_0..v_n
+	points := append([]Element{box.Commitments[0]}, box.ShareCommitments...)
+	codeword, err := randomDualCodeword(g.Order(), len(points), session.Threshold)
+	if err != nil {
+		return err
+	}
+	if !isIdentity(g, multiScalarMult(g, points, codeword)) {
+		return fmt.Errorf("%w: the shares are not on a polynomial of degree %d", ErrInvalidBox, session.Threshold-1)
+	}
+
+	// the batched DLEQ proof: A_1i = r_i·H + c·v_i, A_2i = r_i·PK_i + c·Y_i
+	H := g.SecondGenerator()
+	c := box.Shares[0].challenge
+	a1s, a2s := make([]Element, len(box.Shares)), make([]Element, len(box.Shares))
+	for i, share := range box.Shares {
+		if share.challenge.Cmp(c) != 0 {
+			return fmt.Errorf("%w: the challenges of the batched proof differ", ErrInvalidProof)
+		}
+		a1s[i] = multiScalarMult(g, []Element{H, box.ShareCommitments[i]}, []*big.Int{share.response, c})
+		a2s[i] = multiScalarMult(g, []Element{share.PK, share.S}, []*big.Int{share.response, c})
+	}
+	if scrapeChallenge(g, session, box.U, box.ShareCommitments, box.Shares, a1s, a2s).Cmp(c) != 0 {
+		return fmt.Errorf("%w: batched proof", ErrInvalidProof)
+	}
+	return nil
+}
+
+// randomDualCodeword returns a random codeword of the dual code of the Reed–Solomon code, which consists of the evaluations
+// at 0..m-1 of the polynomials of degree < t. The codeword is c⊥_i = λ_i·f(i) for a random polynomial f of degree m-1-t,
+// where λ_i = ∏(j≠i) 1/(i−j), so that ∑ c⊥_i·p(i) = 0 for every polynomial p of degree < t.
+func randomDualCodeword(n *big.Int, m, t int) ([]*big.Int, error) {
+	f, err := InitPolynomial(m-1-t, n)
+	if err != nil {
+		return nil, err
+	}
+	// ∏(j≠i) (i−j) = i!·(−1)^(m−1−i)·(m−1−i)!
+	factorials := make([]*big.Int, m)
+	factorials[0] = big.NewInt(1)
+	for i := 1; i < m; i++ {
+		factorials[i] = new(big.Int).Mul(factorials[i-1], big.NewInt(int64(i)))
+		factorials[i].Mod(factorials[i], n)
+	}
+	codeword := make([]*big.Int, m)
+	bigI := new(big.Int)
+	for i := range codeword {
+		denominator := new(big.Int).Mul(factorials[i], factorials[m-1-i])
+		if (m-1-i)%2 == 1 {
+			denominator.Neg(denominator)
+		}
+		denominator.Mod(denominator, n)
+		lambda := denominator.ModInverse(denominator, n)
+		bigI.SetInt64(int64(i))
+		codeword[i] = lambda.Mul(lambda, f.GetValue(bigI, n)).Mod(lambda, n)
+	}
+	return codeword, nil
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pvss
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+func TestSCRAPE(t *testing.T) {
+	for _, g := range testGroups {
+		t.Run(g.Name(), func(t *testing.T) {
+			for _, tc := range []struct{ threshold, n int }{{1, 1}, {3, 3}, {3, 7}} {
+				testSCRAPE(t, g, tc.threshold, tc.n)
+			}
+		})
+	}
+}
+
+func testSCRAPE(t *testing.T, g Group, threshold, n int) {
+	dealers, pks := genDealers(g, n+1)
+	secret := new(big.Int).SetBytes([]byte("Hello, go-pvss under SCRAPE"))
+	session := testSession(g, pks[1:], threshold)
+	sharebox, err := dealers[0].DistributeSecretSCRAPE(secret, pks[1:], session)
+	require.NoError(t, err, "DistributeSecretSCRAPE")
+	require.Equal(t, 1, len(sharebox.Commitments))
+	require.Equal(t, n, len(sharebox.ShareCommitments))
+	require.NoError(t, CheckDistributionShares(g, session, sharebox))
+
+	// the shares are decrypted, verified and reconstructed the same way as the classic mode
+	decShares := make([]*DecryptedShare, 0, n)
+	for i, d := range dealers[1:] {
+		decShare, err := d.ExtractSecretShare(sharebox)
+		require.NoError(t, err, "ExtractSecretShare", i)
+		require.True(t, VerifyDecryptedShare(g, session, decShare), i)
+		decShares = append(decShares, decShare)
+	}
+	require.Equal(t, 0, ReconstructSecret(g, decShares[n-threshold:], sharebox.U).Cmp(secret))
+
+	data, err := sharebox.MarshalBinary()
+	require.NoError(t, err)
+	decoded := new(DistributionSharesBox)
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	require.NoError(t, CheckDistributionShares(g, session, decoded))
+
+	data, err = json.Marshal(sharebox)
+	require.NoError(t, err)
+	decoded = new(DistributionSharesBox)
+	require.NoError(t, json.Unmarshal(data, decoded))
+	require.NoError(t, CheckDistributionShares(g, session, decoded))
+}
+
+func TestSCRAPERejects(t *testing.T) {
+	threshold, n := 3, 6
+	g := Secp256k1()
+	dealers, pks := genDealers(g, n+1)
+	session := testSession(g, pks[1:], threshold)
+	deal := func() *DistributionSharesBox {
+		sharebox, err := dealers[0].DistributeSecretSCRAPE(big.NewInt(42), pks[1:], session)
+		require.NoError(t, err)
+		return sharebox
+	}
+
+	// the share commitments are not on a polynomial of degree threshold-1
+	sharebox := deal()
+	sharebox.ShareCommitments[2] = g.Add(sharebox.ShareCommitments[2], g.SecondGenerator())
+	require.ErrorIs(t, CheckDistributionShares(g, session, sharebox), ErrInvalidBox)
+
+	// C_0 doesn't match the shares
+	sharebox = deal()
+	sharebox.Commitments[0] = g.Add(sharebox.Commitments[0], g.SecondGenerator())
+	require.ErrorIs(t, CheckDistributionShares(g, session, sharebox), ErrInvalidBox)
+
+	// an encrypted share doesn't match its commitment
+	sharebox = deal()
+	sharebox.Shares[4].S = g.Add(sharebox.Shares[4].S, g.Generator())
+	require.ErrorIs(t, CheckDistributionShares(g, session, sharebox), ErrInvalidProof)
+
+	// a response is forged
+	sharebox = deal()
+	r, err := rand.Int(rand.Reader, g.Order())
+	require.NoError(t, err)
+	sharebox.Shares[0].response = r
+	require.ErrorIs(t, CheckDistributionShares(g, session, sharebox), ErrInvalidProof)
+
+	// a share is misplaced
+	sharebox = deal()
+	sharebox.Shares[1].Position = 5
+	var shareErr *ShareVerificationError
+	require.ErrorAs(t, CheckDistributionShares(g, session, sharebox), &shareErr)
+	require.Equal(t, 2, shareErr.Position)
+	require.ErrorIs(t, shareErr, ErrInvalidPosition)
+
+	// the box is replayed into another session
+	sharebox = deal()
+	other := NewSession(g, []byte("another"), 1, pks[1:], threshold)
+	require.ErrorIs(t, CheckDistributionShares(g, other, sharebox), ErrSessionMismatch)
+}
+
+func BenchmarkVerifyDistributionSharesSCRAPE(b *testing.B) {
+	threshold, n := 11, 20
+	g := Secp256k1()
+	dealers, pks := genDealers(g, n+1)
+	secret, _ := rand.Int(rand.Reader, g.Order())
+	sharebox, err := dealers[0].DistributeSecretSCRAPE(secret, pks[1:], testSession(g, pks[1:], threshold))
+	require.NoError(b, err)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		VerifyDistributionShares(g, sharebox.Session, sharebox)
+	}
+}
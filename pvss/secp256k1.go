@@ -0,0 +1,215 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pvss
+
+import (
+	"crypto/elliptic"
+	"github.com/stars-labs/go-pvss/crypto/secp256k1"
+	"math/big"
+)
+
+// SecondGeneratorDST is the domain separation tag of hash_to_curve, by which the second generator H of the secp256k1 groups is derived.
+const SecondGeneratorDST = "GO-PVSS-V01-CS01-with-secp256k1_XMD:SHA-256_SSWU_RO_"
+
+var (
+	// Generator point Hx,Hy of secp256k1
+	//
+	// Used as generator point for the value in Pedersen Commitments.
+	// Created as NUMS (nothing-up-my-sleeve) curve point:
+	//	H = hash_to_curve("secp256k1", SecondGeneratorDST)
+	// where hash_to_curve is the suite secp256k1_XMD:SHA-256_SSWU_RO_ of RFC 9380,
+	// so no one knows the discrete logarithm of H with respect to G.
+	// VerifySecondGenerator(Secp256k1()) recomputes it.
+	Hx, _ = new(big.Int).SetString("854cd16ace34c62da03fd108af14dd22176dc2df3e5573e3f3a0663b6520dec2", 16)
+	Hy, _ = new(big.Int).SetString("9e0adf69962e1023b893977362a300bd3b7835895957efa63b0008a105d0d03d", 16)
+)
+
+// Point is an element of the secp256k1 group, in affine coordinates.
+// The point at infinity is represented as (0, 0).
+type Point struct {
+	X *big.Int
+	Y *big.Int
+}
+
+// secp256k1Group is the secp256k1 curve used by Bitcoin, Ethereum etc., it's the default group of the package.
+// Elements are *Point, and encoded in the compressed SEC1 format, except that the point at infinity is encoded as 33 zero bytes.
+type secp256k1Group struct {
+	name  string
+	curve *secp256k1.BitCurve
+	g     *Point
+	h     *Point
+}
+
+var theSecp256k1 = &secp256k1Group{
+	name:  "secp256k1",
+	curve: secp256k1.S256(),
+	g:     &Point{secp256k1.S256().Gx, secp256k1.S256().Gy},
+	h:     &Point{Hx, Hy},
+}
+
+func init() {
+	RegisterGroup(theSecp256k1)
+}
+
+// Secp256k1 returns the secp256k1 group, with Hx,Hy as the second generator.
+func Secp256k1() Group {
+	return theSecp256k1
+}
+
+// NewSecp256k1Group returns a secp256k1 group named "secp256k1/" + deployment,
+// whose second generator H = hash_to_curve(name, SecondGeneratorDST) is independent of the other deployments.
+// The group must be registered by RegisterGroup before decoding the transcripts dealt on it.
+// An empty deployment returns the default group Secp256k1().
+func NewSecp256k1Group(deployment string) Group {
+	if deployment == "" {
+		return theSecp256k1
+	}
+	c := &secp256k1Group{
+		name:  "secp256k1/" + deployment,
+		curve: theSecp256k1.curve,
+		g:     theSecp256k1.g,
+	}
+	c.h = toPoint(c.deriveSecondGenerator())
+	return c
+}
+
+func (c *secp256k1Group) Name() string {
+	return c.name
+}
+
+func (c *secp256k1Group) Order() *big.Int {
+	return c.curve.N
+}
+
+func (c *secp256k1Group) Generator() Element {
+	return c.g
+}
+
+func (c *secp256k1Group) SecondGenerator() Element {
+	return c.h
+}
+
+func (c *secp256k1Group) Identity() Element {
+	return &Point{X: new(big.Int), Y: new(big.Int)}
+}
+
+func (c *secp256k1Group) Add(a, b Element) Element {
+	p, q := toPoint(a), toPoint(b)
+	x, y := c.curve.Add(p.X, p.Y, q.X, q.Y)
+	return &Point{X: x, Y: y}
+}
+
+func (c *secp256k1Group) Neg(a Element) Element {
+	p := toPoint(a)
+	if isInfinity(p) {
+		return p
+	}
+	return &Point{X: p.X, Y: new(big.Int).Sub(c.curve.P, p.Y)}
+}
+
+func (c *secp256k1Group) ScalarMult(a Element, k *big.Int) Element {
+	p := toPoint(a)
+	k = new(big.Int).Mod(k, c.curve.N)
+	if isInfinity(p) || k.Sign() == 0 {
+		return c.Identity()
+	}
+	x, y := c.curve.ScalarMult(p.X, p.Y, k.Bytes())
+	if x == nil {
+		return c.Identity()
+	}
+	return &Point{X: x, Y: y}
+}
+
+// multiScalarMult sums up the products by the multi-scalar multiplication of the curve, in variable time.
+// Like ScalarMult, it returns the identity if a point is not on the curve, which only a point built by the caller can be.
+func (c *secp256k1Group) multiScalarMult(points []Element, scalars []*big.Int) Element {
+	xs, ys, ks := make([]*big.Int, len(points)), make([]*big.Int, len(points)), make([][]byte, len(scalars))
+	for i, a := range points {
+		p := toPoint(a)
+		xs[i], ys[i], ks[i] = p.X, p.Y, new(big.Int).Mod(scalars[i], c.curve.N).Bytes()
+	}
+	x, y := c.curve.MultiScalarMult(xs, ys, ks)
+	if x == nil {
+		return c.Identity()
+	}
+	return &Point{X: x, Y: y}
+}
+
+func (c *secp256k1Group) ScalarBaseMult(k *big.Int) Element {
+	return c.ScalarMult(c.g, k)
+}
+
+func (c *secp256k1Group) Equal(a, b Element) bool {
+	p, q := toPoint(a), toPoint(b)
+	return p.X.Cmp(q.X) == 0 && p.Y.Cmp(q.Y) == 0
+}
+
+func (c *secp256k1Group) ElementLen() int {
+	return 33
+}
+
+func (c *secp256k1Group) Encode(a Element) []byte {
+	p := toPoint(a)
+	if isInfinity(p) {
+		return make([]byte, c.ElementLen())
+	}
+	return secp256k1.CompressPubkey(p.X, p.Y)
+}
+
+func (c *secp256k1Group) Decode(data []byte) (Element, error) {
+	if len(data) != c.ElementLen() {
+		return nil, ErrInvalidPoint
+	}
+	if isZeros(data) {
+		return c.Identity(), nil
+	}
+	x, y := secp256k1.DecompressPubkey(data)
+	if x == nil {
+		return nil, ErrInvalidPoint
+	}
+	return &Point{X: x, Y: y}, nil
+}
+
+// HashToElement implements hash_to_curve of the suite secp256k1_XMD:SHA-256_SSWU_RO_, see RFC 9380.
+func (c *secp256k1Group) HashToElement(msg, dst []byte) Element {
+	x, y := secp256k1.HashToCurve(msg, dst)
+	return &Point{X: x, Y: y}
+}
+
+func (c *secp256k1Group) deriveSecondGenerator() Element {
+	return c.HashToElement([]byte(c.name), []byte(SecondGeneratorDST))
+}
+
+// toPoint returns a as a *Point, it panics if a is not an element of a short Weierstrass curve group.
+func toPoint(a Element) *Point {
+	p, ok := a.(*Point)
+	if !ok || p == nil {
+		panic("pvss: element is not a curve point")
+	}
+	return p
+}
+
+// isInfinity reports whether p is the point at infinity.
+func isInfinity(p *Point) bool {
+	return p.X.Sign() == 0 && p.Y.Sign() == 0
+}
+
+func isZeros(data []byte) bool {
+	for _, b := range data {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Curve returns the elliptic curve of the group, whose crypto/ecdsa keys can be used by the participants.
+func (c *secp256k1Group) Curve() elliptic.Curve {
+	return c.curve
+}
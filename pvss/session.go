@@ -0,0 +1,101 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pvss
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/sha3"
+)
+
+var ErrSessionMismatch = errors.New("session mismatch")
+
+// Session is the context of a round of distribution, it's supplied by the dealer, carried in the distribution shares box
+// and bound into every DLEQ proof of the round, so that a box or a decrypted share can not be replayed into another round.
+type Session struct {
+	ID               []byte // identifies the application and the run of the protocol
+	Epoch            uint64 // increases with every round of the same ID
+	ParticipantsHash []byte // hash of the participants' public keys in the order of their positions, see HashParticipants
+	Threshold        int
+}
+
+// NewSession creates the session of a round, dealing to the participants pks with the threshold on the group g.
+func NewSession(g Group, id []byte, epoch uint64, pks []Element, threshold int) *Session {
+	return &Session{
+		ID:               append([]byte(nil), id...),
+		Epoch:            epoch,
+		ParticipantsHash: HashParticipants(g, pks),
+		Threshold:        threshold,
+	}
+}
+
+// HashParticipants returns the SHA3-256 hash of the group name and the public keys of the participants, in the order of their positions.
+func HashParticipants(g Group, pks []Element) []byte {
+	t := NewTranscript("go-pvss/participants", nil)
+	t.AppendMessage("group", []byte(g.Name()))
+	var count [8]byte
+	binary.BigEndian.PutUint64(count[:], uint64(len(pks)))
+	t.AppendMessage("count", count[:])
+	for _, pk := range pks {
+		if pk == nil {
+			t.AppendMessage("pk", nil)
+			continue
+		}
+		t.AppendElement(g, "pk", pk)
+	}
+	sum := sha3.Sum256(t.buf)
+	return sum[:]
+}
+
+// Equal reports whether s and other are the same session.
+func (s *Session) Equal(other *Session) bool {
+	if s == nil || other == nil {
+		return s == other
+	}
+	return bytes.Equal(s.ID, other.ID) && s.Epoch == other.Epoch &&
+		bytes.Equal(s.ParticipantsHash, other.ParticipantsHash) && s.Threshold == other.Threshold
+}
+
+// transcript creates the transcript of the protocol in the session.
+func (s *Session) transcript(protocol string) *Transcript {
+	t := NewTranscript(protocol, s.ID)
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], s.Epoch)
+	t.AppendMessage("epoch", b[:])
+	t.AppendMessage("participants", s.ParticipantsHash)
+	binary.BigEndian.PutUint64(b[:], uint64(s.Threshold))
+	t.AppendMessage("threshold", b[:])
+	return t
+}
+
+// checkSession checks that the session of a box is the expected one, and is consistent with the box:
+// the threshold is the number of the commitments (except in the SCRAPE mode), and the participants are the owners of the shares.
+func checkSession(g Group, expected *Session, box *DistributionSharesBox) error {
+	if expected == nil {
+		return fmt.Errorf("%w: no expected session", ErrSessionMismatch)
+	}
+	if !expected.Equal(box.Session) {
+		return ErrSessionMismatch
+	}
+	if !box.isSCRAPE() && expected.Threshold != len(box.Commitments) {
+		return fmt.Errorf("%w: threshold %d with %d commitments", ErrSessionMismatch, expected.Threshold, len(box.Commitments))
+	}
+	pks := make([]Element, len(box.Shares))
+	for i, share := range box.Shares {
+		if share != nil {
+			pks[i] = share.PK
+		}
+	}
+	if !bytes.Equal(HashParticipants(g, pks), expected.ParticipantsHash) {
+		return fmt.Errorf("%w: participants do not match the session", ErrSessionMismatch)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pvss
+
+import (
+	"encoding/binary"
+	"golang.org/x/crypto/sha3"
+	"math/big"
+)
+
+// transcriptDomain separates the transcripts of the package from any other use of the hash function.
+const transcriptDomain = "go-pvss/transcript/v1"
+
+// The protocols of the package, every proof is bound to exactly one of them.
+const (
+	distributionProtocol = "go-pvss/distribution"
+	decryptionProtocol   = "go-pvss/decryption"
+	scrapeProtocol       = "go-pvss/distribution/scrape"
+	payloadProtocol      = "go-pvss/payload"
+	elgamalProtocol      = "go-pvss/elgamal"
+	elgamalKeyProtocol   = "go-pvss/elgamal/key"
+	keyProtocol          = "go-pvss/key"
+	keyShareProtocol     = "go-pvss/key/share"
+)
+
+// Transcript is the Fiat–Shamir transcript of a non-interactive proof.
+//
+// Everything the prover and the verifier agree on is appended to the transcript, each message as
+// len(label) || label || len(msg) || msg with 8 bytes big-endian lengths, so no two different sequences
+// of messages have the same encoding. A challenge is the SHA3-512 hash of all the messages so far, reduced
+// modulo the group order, and is appended to the transcript itself.
+//
+// The transcript starts with the protocol name and the session ID, so that a proof made for a protocol or
+// a session is never accepted by another one.
+type Transcript struct {
+	buf []byte
+}
+
+// NewTranscript creates a transcript for the protocol in the session sessionID, sessionID can be nil.
+func NewTranscript(protocol string, sessionID []byte) *Transcript {
+	t := &Transcript{}
+	t.AppendMessage("domain", []byte(transcriptDomain))
+	t.AppendMessage("protocol", []byte(protocol))
+	t.AppendMessage("session-id", sessionID)
+	return t
+}
+
+// Clone returns an independent copy of the transcript, which can be extended for one proof of many.
+func (t *Transcript) Clone() *Transcript {
+	return &Transcript{buf: append([]byte(nil), t.buf...)}
+}
+
+// AppendMessage appends a labeled message to the transcript.
+func (t *Transcript) AppendMessage(label string, msg []byte) {
+	var length [8]byte
+	binary.BigEndian.PutUint64(length[:], uint64(len(label)))
+	t.buf = append(t.buf, length[:]...)
+	t.buf = append(t.buf, label...)
+	binary.BigEndian.PutUint64(length[:], uint64(len(msg)))
+	t.buf = append(t.buf, length[:]...)
+	t.buf = append(t.buf, msg...)
+}
+
+// AppendElement appends the canonical encoding of an element of g to the transcript.
+func (t *Transcript) AppendElement(g Group, label string, e Element) {
+	t.AppendMessage(label, g.Encode(e))
+}
+
+// AppendScalar appends the fixed length encoding of a scalar of g, reduced modulo the group order, to the transcript.
+func (t *Transcript) AppendScalar(g Group, label string, k *big.Int) {
+	k = new(big.Int).Mod(k, g.Order())
+	t.AppendMessage(label, k.FillBytes(make([]byte, scalarLength(g))))
+}
+
+// ChallengeScalar derives a challenge scalar of g from the transcript, and appends it to the transcript.
+// The 512 bits hash makes the bias of the reduction negligible.
+func (t *Transcript) ChallengeScalar(g Group, label string) *big.Int {
+	t.AppendMessage("challenge", []byte(label))
+	digest := sha3.Sum512(t.buf)
+	c := new(big.Int).SetBytes(digest[:])
+	c.Mod(c, g.Order())
+	t.AppendScalar(g, label, c)
+	return c
+}
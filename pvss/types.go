@@ -9,40 +9,48 @@
 package pvss
 
 import (
-	"crypto/ecdsa"
 	"math/big"
 )
 
-type Point struct {
-	X *big.Int
-	Y *big.Int
-}
-
+// DistributionSharesBox is the box of a distribution. Commitments are the commitments of the polynomial coefficients C_j,
+// in the SCRAPE mode they are only C_0, and ShareCommitments are the commitments of the shares v_i = p(i)·H.
+// The secret is hidden by U, or it's the Payload of a hybrid distribution, see DistributePayload.
 type DistributionSharesBox struct {
-	Commitments []*Point
-	Shares      []*Share
-	U           *big.Int
+	Group            Group
+	Session          *Session
+	Commitments      []Element
+	ShareCommitments []Element // only in the SCRAPE mode
+	Shares           []*Share
+	U                *big.Int
+	Payload          *Payload // only in the hybrid mode
 }
 
 // Share includes the encrypted share and dleq information,
 // DLEQ(G1,H1,G2,H2) == > DLEQ(H,X,PK,S)
-// H is the second base point of ecc curve, X can be calculated both by dealer and participants( need the Commitments and Position),
-// so H,X are not included in the struct directly
+// H is the second base point of the group, X can be calculated both by dealer and participants( need the Commitments and Position),
+// so H,X are not included in the struct directly.
+// Besides the challenge and the response, the proof keeps its commitments A1,A2, so that the proofs of many shares can be
+// verified by a single BatchDLEQVerifier. They are nil in the SCRAPE mode, whose proofs are batched by the dealer, and in
+// the shares decoded from the encodings older than the commitments.
 type Share struct {
-	PK        *ecdsa.PublicKey
+	Group     Group
+	PK        Element
 	Position  int
-	S         *Point // Share
+	S         Element // Share
 	challenge *big.Int
 	response  *big.Int
+	a1, a2    Element
 }
 
 // DecryptedShare includes the decrypted share and dleq information,
-// DLEQ(G1,H1,G2,H2) ==> DLEQ(G,PK,S,Y)
+// DLEQ(G1,H1,G2,H2) ==> DLEQ(G,PK,S,Y), whose commitments A1,A2 are kept like the ones of a Share.
 type DecryptedShare struct {
-	PK        *ecdsa.PublicKey
+	Group     Group
+	PK        Element
 	Position  int
-	S         *Point
-	Y         *Point
+	S         Element
+	Y         Element
 	challenge *big.Int
 	response  *big.Int
+	a1, a2    Element
 }
@@ -0,0 +1,100 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pvss
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// VerifyOptions are the options of VerifyDistributionSharesContext.
+type VerifyOptions struct {
+	// Workers is the number of goroutines verifying the shares, runtime.GOMAXPROCS(0) if it's not positive.
+	Workers int
+}
+
+func (o *VerifyOptions) workers() int {
+	if o == nil || o.Workers <= 0 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return o.Workers
+}
+
+// VerifyDistributionSharesContext verifies the distribution shares like CheckDistributionShares, with the shares verified
+// by a bounded pool of workers. It returns as soon as a faulty share is found, which is reported as a *ShareVerificationError,
+// but not necessarily the first one in the position order. If ctx is done before all the shares are verified, it returns ctx.Err().
+// opts can be nil.
+//
+// The box of the SCRAPE mode is verified as a whole in a single goroutine, it's already cheaper than a share by share verification.
+func VerifyDistributionSharesContext(ctx context.Context, g Group, expected *Session, sharesBox *DistributionSharesBox, opts *VerifyOptions) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := checkBox(g, expected, sharesBox); err != nil {
+		return err
+	}
+	if sharesBox.isSCRAPE() {
+		return checkSCRAPEShares(g, expected, sharesBox, false)
+	}
+
+	workers := opts.workers()
+	if workers > len(sharesBox.Shares) {
+		workers = len(sharesBox.Shares)
+	}
+	// the transcript is only read by the workers, every proof is verified on a copy of it
+	transcript := distributionTranscript(expected, distributionProtocol, sharesBox.U)
+	workerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg      sync.WaitGroup
+		once    sync.Once
+		failure error
+		next    int64 = -1
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for workerCtx.Err() == nil {
+				i := int(atomic.AddInt64(&next, 1))
+				if i >= len(sharesBox.Shares) {
+					return
+				}
+				if err := checkShare(g, transcript, sharesBox.Commitments, i+1, sharesBox.Shares[i]); err != nil {
+					once.Do(func() {
+						failure = err
+						cancel()
+					})
+					return
+				}
+			}
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	// the workers still running after a failure or a cancellation finish their current share in the background
+	select {
+	case <-done:
+	case <-workerCtx.Done():
+	}
+	// once the no-op is done, the failure is either set or never will be
+	once.Do(func() {})
+	if failure != nil {
+		return failure
+	}
+	// the workers stop early on the cancellation of ctx, so the shares are not all verified
+	return ctx.Err()
+}
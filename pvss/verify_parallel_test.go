@@ -0,0 +1,87 @@
+/*
+ * Copyright (c) 2021 Stars-labs.
+ * Author: darlzan@foxmail.com
+ *
+ * Code is licensed under GPLv3.0 License. You should have received a copy of the GNU General Public License v3.0
+ * along with the go-pvss library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pvss
+
+import (
+	"context"
+	"crypto/rand"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestVerifyDistributionSharesContext(t *testing.T) {
+	threshold, n := 3, 9
+	g := Secp256k1()
+	dealers, pks := genDealers(g, n+1)
+	session := testSession(g, pks[1:], threshold)
+	sharebox, err := dealers[0].DistributeSecret(big.NewInt(42), pks[1:], session)
+	require.NoError(t, err)
+	scrapebox, err := dealers[0].DistributeSecretSCRAPE(big.NewInt(42), pks[1:], session)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for _, opts := range []*VerifyOptions{nil, {Workers: 1}, {Workers: 4}, {Workers: 64}} {
+		require.NoError(t, VerifyDistributionSharesContext(ctx, g, session, sharebox, opts))
+		require.NoError(t, VerifyDistributionSharesContext(ctx, g, session, scrapebox, opts))
+	}
+
+	// a faulty share is reported, whichever worker finds it
+	faulty := *sharebox
+	faulty.Shares = append([]*Share(nil), sharebox.Shares...)
+	forged := *sharebox.Shares[6]
+	forged.S = g.Add(forged.S, g.Generator())
+	faulty.Shares[6] = &forged
+	for _, opts := range []*VerifyOptions{{Workers: 1}, {Workers: 4}} {
+		err := VerifyDistributionSharesContext(ctx, g, session, &faulty, opts)
+		var shareErr *ShareVerificationError
+		require.ErrorAs(t, err, &shareErr)
+		require.Equal(t, 7, shareErr.Position)
+		require.ErrorIs(t, err, ErrInvalidProof)
+	}
+	require.ErrorIs(t, VerifyDistributionSharesContext(ctx, g, NewSession(g, []byte("another"), 1, pks[1:], threshold), sharebox, nil), ErrSessionMismatch)
+
+	// a done context
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	require.ErrorIs(t, VerifyDistributionSharesContext(cancelled, g, session, sharebox, nil), context.Canceled)
+	expired, cancel := context.WithDeadline(ctx, time.Now().Add(-time.Second))
+	defer cancel()
+	require.ErrorIs(t, VerifyDistributionSharesContext(expired, g, session, sharebox, nil), context.DeadlineExceeded)
+}
+
+func TestVerifyDistributionSharesContext_Cancel(t *testing.T) {
+	threshold, n := 11, 200
+	g := Secp256k1()
+	dealers, pks := genDealers(g, n+1)
+	session := testSession(g, pks[1:], threshold)
+	sharebox, err := dealers[0].DistributeSecret(big.NewInt(42), pks[1:], session)
+	require.NoError(t, err)
+
+	// the verification of a single worker is cancelled midway
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	require.ErrorIs(t, VerifyDistributionSharesContext(ctx, g, session, sharebox, &VerifyOptions{Workers: 1}), context.DeadlineExceeded)
+	require.Less(t, int64(time.Since(start)), int64(time.Second))
+}
+
+func BenchmarkVerifyDistributionSharesContext(b *testing.B) {
+	threshold, n := 11, 20
+	g := Secp256k1()
+	dealers, pks := genDealers(g, n+1)
+	secret, _ := rand.Int(rand.Reader, g.Order())
+	sharebox, err := dealers[0].DistributeSecret(secret, pks[1:], testSession(g, pks[1:], threshold))
+	require.NoError(b, err)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = VerifyDistributionSharesContext(context.Background(), g, sharebox.Session, sharebox, nil)
+	}
+}